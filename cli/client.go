@@ -17,12 +17,23 @@ limitations under the License.
 package cli
 
 import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/huanght1997/cosutil/coshelper"
+
 	"github.com/mitchellh/go-homedir"
 	"github.com/schollz/progressbar/v3"
 	log "github.com/sirupsen/logrus"
@@ -33,6 +44,8 @@ import (
 type Client struct {
 	Client *cos.Client
 	Config *ClientConfig
+	// httpClient signs requests the SDK does not support, it is shared with the SDK.
+	httpClient *http.Client
 }
 
 type ClientConfig struct {
@@ -41,6 +54,7 @@ type ClientConfig struct {
 	Token        string
 	Bucket       string
 	Endpoint     string
+	Region       string
 	MaxThread    int
 	PartSize     int
 	RetryTimes   int
@@ -85,13 +99,15 @@ func NewClient(config *ClientConfig) *Client {
 	if config.Token != "" {
 		authTransport.SessionToken = config.Token
 	}
-	client := cos.NewClient(b, &http.Client{
+	httpClient := &http.Client{
 		Transport: &authTransport,
 		Timeout:   time.Duration(config.Timeout) * time.Second,
-	})
+	}
+	client := cos.NewClient(b, httpClient)
 	return &Client{
-		Client: client,
-		Config: config,
+		Client:     client,
+		Config:     config,
+		httpClient: httpClient,
 	}
 }
 
@@ -155,10 +171,11 @@ func LoadConf(configPath string) *ClientConfig {
 
 		// Handle endpoint.
 		if Region != "" {
-			config.Endpoint = "cos." + compatible(Region) + ".myqcloud.com"
+			config.Region = compatible(Region)
+			config.Endpoint = "cos." + config.Region + ".myqcloud.com"
 		} else if section.HasKey("region") {
-			region := compatible(section.Key("region").String())
-			config.Endpoint = "cos." + region + ".myqcloud.com"
+			config.Region = compatible(section.Key("region").String())
+			config.Endpoint = "cos." + config.Region + ".myqcloud.com"
 		}
 		// if endpoint specified, the key region is ignored.
 		if section.HasKey("endpoint") {
@@ -231,3 +248,127 @@ func compatible(region string) string {
 	}
 	return region
 }
+
+// Attributes of an object in the result of listing, used by filter.
+func objectAttr(size int64, lastModified string, storageClass string) coshelper.FileAttr {
+	modTime, _ := time.Parse(time.RFC3339, lastModified)
+	return coshelper.FileAttr{
+		Size:         size,
+		ModTime:      modTime,
+		StorageClass: storageClass,
+	}
+}
+
+// The path of a single local file given to the filter, relative to the working directory,
+// or the absolute path without the leading "/" if the file is not in it.
+func localFilterPath(localPath string) string {
+	absPath, err := filepath.Abs(localPath)
+	if err != nil {
+		return filepath.ToSlash(localPath)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, absPath); err == nil && !isParentRel(rel) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return strings.TrimLeft(filepath.ToSlash(absPath), "/")
+}
+
+// The path of key given to the filter when walking the objects with prefix. It is relative to
+// the "directory" of prefix, so that a/b/ matches a/b/c.txt as c.txt, and the raw prefix a/b
+// matches a/b.txt as b.txt and a/b/c.txt as b/c.txt, in every command.
+func relativeKey(key string, prefix string) string {
+	return key[strings.LastIndex(prefix, "/")+1:]
+}
+
+// Attributes of an object in the response header of HEAD or GET, used by filter.
+func headerAttr(header http.Header) coshelper.FileAttr {
+	size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		size = -1
+	}
+	modTime, _ := http.ParseTime(header.Get("Last-Modified"))
+	storageClass := header.Get("x-cos-storage-class")
+	if storageClass == "" {
+		storageClass = "STANDARD"
+	}
+	return coshelper.FileAttr{
+		Size:         size,
+		ModTime:      modTime,
+		StorageClass: storageClass,
+	}
+}
+
+// Attributes of a local file, used by filter.
+func localAttr(f os.FileInfo) coshelper.FileAttr {
+	return coshelper.FileAttr{
+		Size:    f.Size(),
+		ModTime: f.ModTime(),
+	}
+}
+
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+// Send a request to the bucket for the APIs the SDK does not fully support.
+// body is marshalled to XML with Content-MD5, and the XML response is unmarshalled to result if not nil.
+// COS errors are returned as *cos.ErrorResponse like the SDK does.
+func (client *Client) sendBucketRequest(method string, uri string, body interface{}, result interface{}) error {
+	return client.sendRequest(method, uri, nil, body, result)
+}
+
+// Send a request like sendBucketRequest with the headers, uri can be the escaped path of an object.
+func (client *Client) sendRequest(method string, uri string, header http.Header, body interface{}, result interface{}) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	var reader io.Reader
+	contentMD5 := ""
+	if body != nil {
+		data, err := xml.Marshal(body)
+		if err != nil {
+			return err
+		}
+		log.Debugf("Request body: %s", string(data))
+		reader = bytes.NewReader(data)
+		sum := md5.Sum(data)
+		contentMD5 = base64.StdEncoding.EncodeToString(sum[:])
+	}
+	req, err := http.NewRequest(method, client.Client.BaseURL.BucketURL.ResolveReference(u).String(), reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/xml")
+		req.Header.Set("Content-MD5", contentMD5)
+	}
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode >= 300 {
+		errorResponse := &cos.ErrorResponse{Response: resp}
+		data, _ := ioutil.ReadAll(resp.Body)
+		_ = xml.Unmarshal(data, errorResponse)
+		return errorResponse
+	}
+	if result != nil {
+		if err := xml.NewDecoder(resp.Body).Decode(result); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,641 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/klauspost/compress/zstd"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// Archive formats.
+const (
+	ArchiveTar   = "tar"
+	ArchiveTarGz = "tar.gz"
+	ArchiveZstd  = "zstd"
+)
+
+// ArchiveIndexSuffix is appended to the key of an archive to get the key of its index.
+const ArchiveIndexSuffix = ".index.json"
+
+// ArchiveIndex records where the files are in an uncompressed tar archive,
+// so that a single file can be fetched with a ranged GET.
+type ArchiveIndex struct {
+	Format  string               `json:"format"`
+	Members []ArchiveIndexMember `json:"members"`
+}
+
+type ArchiveIndexMember struct {
+	Name    string `json:"name"`
+	Offset  int64  `json:"offset"`
+	Size    int64  `json:"size"`
+	Mode    int64  `json:"mode"`
+	ModTime int64  `json:"mtime"`
+}
+
+// Count the bytes written, used to find the offsets of files in the archive.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// UploadArchive packs localPath into a tar archive, compressed by format, and
+// streams it to cosPath as a multipart object without staging it on disk.
+// If index is set, an ArchiveIndex is uploaded to cosPath+ArchiveIndexSuffix,
+// it is only available for ArchiveTar.
+func (client *Client) UploadArchive(localPath string, cosPath string, format string, index bool, headers *http.Header, options *UploadOption) int {
+	if index && format != ArchiveTar {
+		log.Warn("Index is only available for uncompressed tar archive")
+		return -1
+	}
+	cosPath = strings.TrimLeft(cosPath, "/")
+	if options.Filter == nil {
+		options.Filter = coshelper.NewFilter(nil, nil)
+	}
+	archiveHeaders := http.Header{}
+	if headers != nil {
+		archiveHeaders = headers.Clone()
+	}
+	if options.StorageClass != "" {
+		archiveHeaders.Set("x-cos-storage-class", options.StorageClass)
+	}
+	if len(options.Tags) != 0 {
+		archiveHeaders.Set("x-cos-tagging", coshelper.EncodeTags(options.Tags))
+	}
+	options.SSE.SetWriteHeaders(archiveHeaders)
+	if archiveHeaders.Get("Content-Type") == "" {
+		switch format {
+		case ArchiveTar:
+			archiveHeaders.Set("Content-Type", "application/x-tar")
+		case ArchiveTarGz:
+			archiveHeaders.Set("Content-Type", "application/gzip")
+		case ArchiveZstd:
+			archiveHeaders.Set("Content-Type", "application/zstd")
+		}
+	}
+
+	pr, pw := io.Pipe()
+	var members []ArchiveIndexMember
+	var fileNum int
+	writeDone := make(chan error, 1)
+	go func() {
+		var err error
+		members, fileNum, err = writeArchive(pw, localPath, format, options.Filter)
+		_ = pw.CloseWithError(err)
+		writeDone <- err
+	}()
+
+	log.Infof("Upload %s   =>   cos://%s/%s (%s archive)",
+		localPath, client.Config.Bucket, cosPath, format)
+	ret := client.uploadStream(pr, cosPath, &archiveHeaders)
+	// Stop packing if uploading failed.
+	_ = pr.Close()
+	if err := <-writeDone; err != nil {
+		log.Warn(err.Error())
+		ret = -1
+	}
+	if ret != 0 {
+		log.Warnf(`Upload archive "%s" FAILED.`, localPath)
+		return ret
+	}
+	log.Infof("%d files archived", fileNum)
+	if index {
+		data, _ := json.Marshal(ArchiveIndex{
+			Format:  format,
+			Members: members,
+		})
+		indexPath := cosPath + ArchiveIndexSuffix
+		indexHeaders := http.Header{}
+		options.SSE.SetWriteHeaders(indexHeaders)
+		_, err := client.Client.Object.Put(context.Background(), indexPath, bytes.NewReader(data), &cos.ObjectPutOptions{
+			ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+				ContentType:   "application/json",
+				XOptionHeader: &indexHeaders,
+			},
+		})
+		if err != nil {
+			log.Warn(err.Error())
+			log.Warnf("Upload index cos://%s/%s FAILED.", client.Config.Bucket, indexPath)
+			return -1
+		}
+		log.Infof("Index uploaded to cos://%s/%s", client.Config.Bucket, indexPath)
+	}
+	return 0
+}
+
+// Write the tar archive of root to w. Return the index of regular files and the number of files.
+func writeArchive(w io.Writer, root string, format string, filter *coshelper.Filter) ([]ArchiveIndexMember, int, error) {
+	bw := bufio.NewWriterSize(w, 1024*1024)
+	var compressor io.WriteCloser
+	switch format {
+	case ArchiveTar:
+	case ArchiveTarGz:
+		compressor = gzip.NewWriter(bw)
+	case ArchiveZstd:
+		zw, err := zstd.NewWriter(bw)
+		if err != nil {
+			return nil, 0, err
+		}
+		compressor = zw
+	default:
+		return nil, 0, fmt.Errorf("unknown archive format '%s'", format)
+	}
+	cw := &countingWriter{w: bw}
+	if compressor != nil {
+		cw.w = compressor
+	}
+	tw := tar.NewWriter(cw)
+	members := make([]ArchiveIndexMember, 0)
+	fileNum := 0
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			if rel != "." && filter.Excluded(rel, true) {
+				log.Debugf("Skip %s", p)
+				return filepath.SkipDir
+			}
+			// rules in the ignore file apply to current directory and its subdirectories
+			ignoreFilePath := filepath.Join(p, coshelper.IgnoreFileName)
+			if coshelper.IsFile(ignoreFilePath) {
+				base := rel
+				if base == "." {
+					base = ""
+				}
+				if err := filter.LoadFile(ignoreFilePath, base); err != nil {
+					log.Warnf("Cannot read '%s': %s", ignoreFilePath, err.Error())
+				}
+			}
+			if rel == "." {
+				return nil
+			}
+		} else if info.Name() == coshelper.IgnoreFileName || filter.ExcludedFile(rel, localAttr(info)) {
+			log.Debugf("Skip %s", p)
+			return nil
+		}
+		link := ""
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		case !info.IsDir() && !info.Mode().IsRegular():
+			log.Warnf("Skip special file %s", p)
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = rel
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		members = append(members, ArchiveIndexMember{
+			Name:    rel,
+			Offset:  cw.n,
+			Size:    info.Size(),
+			Mode:    header.Mode,
+			ModTime: info.ModTime().Unix(),
+		})
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		_, err = io.CopyN(tw, f, info.Size())
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+		fileNum++
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, 0, err
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return nil, 0, err
+		}
+	}
+	return members, fileNum, bw.Flush()
+}
+
+// The max number of parts of a multipart upload.
+const maxStreamParts = 10000
+
+// Upload the data read from r to cosPath as a multipart object. The size of data is unknown,
+// so parts are read one by one, and at most MaxThread parts are uploaded at the same time.
+func (client *Client) uploadStream(r io.Reader, cosPath string, headers *http.Header) int {
+	result, _, err := client.Client.Object.InitiateMultipartUpload(context.Background(), cosPath, &cos.InitiateMultipartUploadOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			XOptionHeader: headers,
+		},
+	})
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	streamUploadID := result.UploadID
+	partHeaders := customerKeyHeaders(headers)
+	chunkSize := 1024 * 1024 * int64(client.Config.PartSize)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	parts := make([]cos.Object, 0)
+	failed := false
+	uploading := make(chan struct{}, client.Config.MaxThread)
+	var total int64
+	for partNumber := 1; ; partNumber++ {
+		// The last part is full, fail if there is still more data.
+		if partNumber > maxStreamParts {
+			if n, _ := io.ReadFull(r, make([]byte, 1)); n > 0 {
+				log.Warnf("The data is too large, at most %d parts of %d bytes are allowed", maxStreamParts, chunkSize)
+				mutex.Lock()
+				failed = true
+				mutex.Unlock()
+			}
+			break
+		}
+		// At most 10000 parts, make parts larger as the archive grows.
+		if partNumber%1000 == 0 && chunkSize < singleUploadMaxSize {
+			chunkSize *= 2
+		}
+		data := make([]byte, chunkSize)
+		n, err := io.ReadFull(r, data)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			log.Warn(err.Error())
+			mutex.Lock()
+			failed = true
+			mutex.Unlock()
+			break
+		}
+		// An empty stream still needs one part.
+		if n == 0 && partNumber > 1 {
+			break
+		}
+		total += int64(n)
+		uploading <- struct{}{}
+		mutex.Lock()
+		stop := failed
+		mutex.Unlock()
+		if stop {
+			<-uploading
+			break
+		}
+		wg.Add(1)
+		go func(partNumber int, data []byte) {
+			defer func() {
+				<-uploading
+				wg.Done()
+			}()
+			etag, ok := client.uploadStreamPart(cosPath, streamUploadID, partNumber, data, partHeaders)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if !ok {
+				failed = true
+				return
+			}
+			parts = append(parts, cos.Object{
+				ETag:       etag,
+				PartNumber: partNumber,
+			})
+		}(partNumber, data[:n])
+		if n < len(data) {
+			break
+		}
+	}
+	wg.Wait()
+	if failed {
+		_, _ = client.Client.Object.AbortMultipartUpload(context.Background(), cosPath, streamUploadID)
+		return -1
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+	_, _, err = client.Client.Object.CompleteMultipartUpload(context.Background(), cosPath, streamUploadID, &cos.CompleteMultipartUploadOptions{
+		Parts: parts,
+	})
+	if err != nil {
+		log.Warn(err.Error())
+		_, _ = client.Client.Object.AbortMultipartUpload(context.Background(), cosPath, streamUploadID)
+		return -1
+	}
+	log.Infof("%d bytes uploaded in %d parts", total, len(parts))
+	return 0
+}
+
+// The SSE-C headers in headers, which the parts must have too. Return nil if there is none.
+func customerKeyHeaders(headers *http.Header) *http.Header {
+	if headers == nil {
+		return nil
+	}
+	partHeaders := http.Header{}
+	for key, values := range *headers {
+		if strings.HasPrefix(strings.ToLower(key), "x-cos-server-side-encryption-customer-") {
+			partHeaders[key] = values
+		}
+	}
+	if len(partHeaders) == 0 {
+		return nil
+	}
+	return &partHeaders
+}
+
+// Upload a part of stream, return the ETag of it.
+func (client *Client) uploadStreamPart(cosPath string, uploadID string, partNumber int, data []byte, headers *http.Header) (string, bool) {
+	for j := 0; j <= client.Config.RetryTimes; j++ {
+		resp, err := client.Client.Object.UploadPart(context.Background(), cosPath, uploadID, partNumber, bytes.NewReader(data), &cos.ObjectUploadPartOptions{
+			XOptionHeader: headers,
+		})
+		if err == nil && resp.StatusCode == 200 {
+			return resp.Header.Get("ETag"), true
+		}
+		if err != nil {
+			log.Warnf("Upload part failed, key: %s, partNumber: %d, round: %d, exception: %s",
+				cosPath, partNumber, j+1, err.Error())
+		}
+		if j < client.Config.RetryTimes {
+			time.Sleep((1 << j) * time.Second)
+		}
+	}
+	return "", false
+}
+
+// ExtractArchive downloads the archive at cosPath and unpacks it into localPath as a stream.
+// The format is detected from the content.
+func (client *Client) ExtractArchive(cosPath string, localPath string, options *DownloadOption) int {
+	cosPath = strings.TrimLeft(cosPath, "/")
+	resp, err := client.Client.Object.Get(context.Background(), cosPath, &cos.ObjectGetOptions{
+		XOptionHeader: options.Headers,
+	})
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	log.Infof("Extract cos://%s/%s   =>   %s",
+		client.Config.Bucket, cosPath, localPath)
+	br := bufio.NewReaderSize(resp.Body, 1024*1024)
+	var r io.Reader = br
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+		defer func() {
+			_ = gr.Close()
+		}()
+		r = gr
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+		defer zr.Close()
+		r = zr
+	}
+	successNum, skipNum, failNum := 0, 0, 0
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+		name := strings.Trim(header.Name, "/")
+		target := filepath.Join(localPath, filepath.FromSlash(name))
+		// Never write outside localPath, neither by the name nor through a symbolic link extracted before.
+		if filepath.Clean(name) == "." {
+			continue
+		}
+		if err := checkLocalPath(localPath, target); err != nil {
+			log.Warnf("Skip %s: %s", header.Name, err.Error())
+			skipNum++
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if options.Filter.Excluded(name, true) {
+				continue
+			}
+			if err := os.MkdirAll(target, 0755); err != nil {
+				log.Warn(err.Error())
+				failNum++
+			}
+			continue
+		case tar.TypeReg, tar.TypeRegA, tar.TypeSymlink:
+		default:
+			log.Warnf("Skip %s, unsupported type", header.Name)
+			skipNum++
+			continue
+		}
+		if options.Filter.ExcludedFile(name, coshelper.FileAttr{
+			Size:    header.Size,
+			ModTime: header.ModTime,
+		}) {
+			log.Debugf("Skip %s", header.Name)
+			skipNum++
+			continue
+		}
+		if _, err := os.Lstat(target); err == nil {
+			if !options.Force {
+				log.Warnf("The file %s already exists, please use -f to overwrite the file", target)
+				failNum++
+				continue
+			}
+			if err := os.Remove(target); err != nil {
+				log.Warn(err.Error())
+				failNum++
+				continue
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			log.Warn(err.Error())
+			failNum++
+			continue
+		}
+		if header.Typeflag == tar.TypeSymlink {
+			if err := checkLinkTarget(localPath, target, header.Linkname); err != nil {
+				log.Warnf("Skip %s: %s", header.Name, err.Error())
+				skipNum++
+				continue
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				log.Warn(err.Error())
+				failNum++
+				continue
+			}
+			successNum++
+			continue
+		}
+		if err := extractFile(tr, target, header); err != nil {
+			log.Warn(err.Error())
+			// The stream is broken if the file can not be read.
+			if err == io.ErrUnexpectedEOF {
+				return -1
+			}
+			failNum++
+			continue
+		}
+		successNum++
+	}
+	log.Infof("%d files extracted, %d files skipped, %d files failed",
+		successNum, skipNum, failNum)
+	if failNum != 0 {
+		return -1
+	}
+	return 0
+}
+
+func extractFile(r io.Reader, target string, header *tar.Header) error {
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+	if err != nil {
+		// Drain the content, so that the next file can be read.
+		_, _ = io.Copy(ioutil.Discard, r)
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Chtimes(target, header.ModTime, header.ModTime)
+}
+
+// DownloadArchiveMember fetches a single file in the archive at cosPath with a ranged GET,
+// using the index uploaded with the archive.
+func (client *Client) DownloadArchiveMember(cosPath string, member string, localPath string, options *DownloadOption) int {
+	cosPath = strings.TrimLeft(cosPath, "/")
+	member = strings.Trim(member, "/")
+	indexPath := cosPath + ArchiveIndexSuffix
+	resp, err := client.Client.Object.Get(context.Background(), indexPath, &cos.ObjectGetOptions{
+		XOptionHeader: options.Headers,
+	})
+	if err != nil {
+		log.Warn(err.Error())
+		log.Warnf("Cannot get index cos://%s/%s of the archive", client.Config.Bucket, indexPath)
+		return -1
+	}
+	var index ArchiveIndex
+	err = json.NewDecoder(resp.Body).Decode(&index)
+	_ = resp.Body.Close()
+	if err != nil {
+		log.Warnf("Invalid index cos://%s/%s: %s", client.Config.Bucket, indexPath, err.Error())
+		return -1
+	}
+	var found *ArchiveIndexMember
+	for i := range index.Members {
+		if index.Members[i].Name == member {
+			found = &index.Members[i]
+			break
+		}
+	}
+	if found == nil {
+		log.Warnf("%s is not found in the archive", member)
+		return -1
+	}
+	if strings.HasSuffix(localPath, "/") || coshelper.IsDir(localPath) {
+		localPath = filepath.Join(localPath, path.Base(member))
+	}
+	if coshelper.FileExists(localPath) && !options.Force {
+		log.Warnf("The file %s already exists, please use -f to overwrite the file", localPath)
+		return -1
+	}
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	log.Infof("Download cos://%s/%s:%s   =>   %s",
+		client.Config.Bucket, cosPath, member, localPath)
+	f, err := os.OpenFile(localPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(found.Mode).Perm())
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	if found.Size > 0 {
+		resp, err = client.Client.Object.Get(context.Background(), cosPath, &cos.ObjectGetOptions{
+			Range:         fmt.Sprintf("bytes=%d-%d", found.Offset, found.Offset+found.Size-1),
+			XOptionHeader: options.Headers,
+		})
+		if err == nil {
+			_, err = io.Copy(f, resp.Body)
+			_ = resp.Body.Close()
+		}
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	modTime := time.Unix(found.ModTime, 0)
+	_ = os.Chtimes(localPath, modTime, modTime)
+	return 0
+}
@@ -0,0 +1,570 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/mitchellh/go-homedir"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// Conflict policies of bisync.
+const (
+	ConflictBoth   = "both"
+	ConflictNewer  = "newer"
+	ConflictLocal  = "local"
+	ConflictRemote = "remote"
+)
+
+// Suffixes appended to the two copies of a conflicting file when the policy is ConflictBoth.
+const (
+	conflictLocalSuffix  = ".conflict-local"
+	conflictRemoteSuffix = ".conflict-remote"
+)
+
+type BisyncOption struct {
+	Conflict string
+	Filter   *coshelper.Filter
+	DryRun   bool
+	Yes      bool
+	// MaxDelete aborts the run if more than this percentage of the files known by the previous run
+	// would be deleted, like when the local directory is empty or not mounted. 100 for no limit.
+	MaxDelete int
+}
+
+// localEntry is what bisync remembers about a local file.
+type localEntry struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mtime"`
+}
+
+// remoteEntry is what bisync remembers about an object.
+type remoteEntry struct {
+	Size         int64  `json:"size"`
+	ETag         string `json:"etag"`
+	LastModified string `json:"last_modified"`
+}
+
+// bisyncState is the snapshot saved at the end of every bisync run.
+// Keys of both maps are paths relative to the synchronized roots, separated by "/".
+type bisyncState struct {
+	Local  map[string]localEntry  `json:"local"`
+	Remote map[string]remoteEntry `json:"remote"`
+}
+
+type bisyncAction int
+
+const (
+	actionUpload bisyncAction = iota
+	actionDownload
+	actionDeleteLocal
+	actionDeleteRemote
+	actionKeepBoth
+)
+
+func (a bisyncAction) String() string {
+	switch a {
+	case actionUpload:
+		return "upload"
+	case actionDownload:
+		return "download"
+	case actionDeleteLocal:
+		return "delete local"
+	case actionDeleteRemote:
+		return "delete remote"
+	case actionKeepBoth:
+		return "keep both"
+	}
+	return "unknown"
+}
+
+type bisyncTask struct {
+	path   string
+	action bisyncAction
+}
+
+// Bisync synchronizes localPath and cosPath in both directions.
+// Changes are detected against the snapshot saved by the previous run, so
+// files created, modified or deleted on either side are propagated to the other.
+// If a file changed on both sides, options.Conflict decides which one wins.
+// Return 0 if all things done, -1 if some operations failed, -3 if canceled by user.
+func (client *Client) Bisync(localPath string, cosPath string, options *BisyncOption) int {
+	if !strings.HasSuffix(localPath, "/") {
+		localPath += "/"
+	}
+	cosPath = strings.TrimLeft(cosPath, "/")
+	if cosPath != "" && !strings.HasSuffix(cosPath, "/") {
+		cosPath += "/"
+	}
+	statePath, err := client.getBisyncStatePath(localPath, cosPath)
+	if err != nil {
+		log.Warnf("Cannot locate the bisync state file: %s", err.Error())
+		return -1
+	}
+	prevState := loadBisyncState(statePath)
+	localFiles, err := scanLocalFiles(localPath)
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	remoteFiles, ret := client.scanRemoteFiles(cosPath)
+	if ret != 0 {
+		return ret
+	}
+
+	tasks := make([]bisyncTask, 0)
+	deleteNum := 0
+	for _, p := range unionPaths(localFiles, remoteFiles, prevState) {
+		if options.Filter.Excluded(p, false) {
+			log.Debugf("Skip %s", p)
+			continue
+		}
+		sameContent := func() bool {
+			return client.sameBisyncContent(localPath+filepath.FromSlash(p), cosPath+p, remoteFiles[p])
+		}
+		action, ok := planBisync(p, localFiles, remoteFiles, prevState, options.Conflict, sameContent)
+		if !ok {
+			continue
+		}
+		if action == actionDeleteLocal || action == actionDeleteRemote {
+			deleteNum++
+		}
+		tasks = append(tasks, bisyncTask{path: p, action: action})
+	}
+	if len(tasks) == 0 {
+		log.Info("Everything is up to date")
+		if !options.DryRun {
+			if err := saveBisyncState(statePath, &bisyncState{Local: localFiles, Remote: remoteFiles}); err != nil {
+				log.Warnf("Cannot save bisync state to '%s': %s", statePath, err.Error())
+				return -1
+			}
+		}
+		return 0
+	}
+	if options.DryRun {
+		for _, task := range tasks {
+			log.Infof("(dry run) %s: %s", task.action, task.path)
+		}
+	}
+	if knownNum := len(unionPaths(prevState.Local, prevState.Remote, &bisyncState{})); knownNum > 0 &&
+		deleteNum*100 > options.MaxDelete*knownNum {
+		log.Warnf("%d of %d files would be deleted, more than %d%%, is '%s' empty or not mounted? Use --max-delete to allow it",
+			deleteNum, knownNum, options.MaxDelete, localPath)
+		return -1
+	}
+	if options.DryRun {
+		return 0
+	}
+	if deleteNum > 0 && !options.Yes {
+		question := fmt.Sprintf("WARN: %d files will be deleted in '%s' or cos://%s/%s, please make sure",
+			deleteNum, localPath, client.Config.Bucket, cosPath)
+		if !coshelper.Confirm(question, "no") {
+			return -3
+		}
+	}
+
+	newState := &bisyncState{Local: localFiles, Remote: remoteFiles}
+	successNum, failNum := 0, 0
+	for _, task := range tasks {
+		if client.doBisyncTask(localPath, cosPath, task, newState) {
+			successNum++
+		} else {
+			failNum++
+			// Forget what was seen this time, so that the change will be found again next time.
+			revertBisyncEntry(newState, prevState, task.path)
+		}
+	}
+	if err := saveBisyncState(statePath, newState); err != nil {
+		log.Warnf("Cannot save bisync state to '%s': %s", statePath, err.Error())
+		failNum++
+	}
+	log.Infof("%d operations successful, %d operations failed", successNum, failNum)
+	if failNum != 0 {
+		return -1
+	}
+	return 0
+}
+
+// Decide what to do with path p. If nothing needs to be done, ok is false.
+// sameContent tells whether the local file and the object have the same content, it is only called
+// for files new on both sides.
+func planBisync(p string, localFiles map[string]localEntry, remoteFiles map[string]remoteEntry,
+	prevState *bisyncState, conflict string, sameContent func() bool) (action bisyncAction, ok bool) {
+	local, localExists := localFiles[p]
+	remote, remoteExists := remoteFiles[p]
+	prevLocal, prevLocalExists := prevState.Local[p]
+	prevRemote, prevRemoteExists := prevState.Remote[p]
+	localChanged := localExists != prevLocalExists ||
+		(localExists && (local.Size != prevLocal.Size || local.ModTime != prevLocal.ModTime))
+	remoteChanged := remoteExists != prevRemoteExists ||
+		(remoteExists && (remote.Size != prevRemote.Size || remote.ETag != prevRemote.ETag))
+
+	switch {
+	case !localChanged && !remoteChanged:
+		return 0, false
+	case localChanged && !remoteChanged:
+		if localExists {
+			return actionUpload, true
+		}
+		if remoteExists {
+			return actionDeleteRemote, true
+		}
+		return 0, false
+	case !localChanged && remoteChanged:
+		if remoteExists {
+			return actionDownload, true
+		}
+		if localExists {
+			return actionDeleteLocal, true
+		}
+		return 0, false
+	}
+
+	// Changed on both sides.
+	if !localExists && !remoteExists {
+		return 0, false
+	}
+	if localExists && remoteExists && !prevLocalExists && !prevRemoteExists && local.Size == remote.Size && sameContent() {
+		// The same file put on both sides, e.g. the first run. Otherwise it is a conflict.
+		return 0, false
+	}
+	// A deleted file never beats a modified one.
+	if !localExists {
+		return actionDownload, true
+	}
+	if !remoteExists {
+		return actionUpload, true
+	}
+	switch conflict {
+	case ConflictLocal:
+		return actionUpload, true
+	case ConflictRemote:
+		return actionDownload, true
+	case ConflictNewer:
+		remoteTime, err := time.Parse(time.RFC3339, remote.LastModified)
+		if err != nil || time.Unix(0, local.ModTime).After(remoteTime) {
+			return actionUpload, true
+		}
+		return actionDownload, true
+	default:
+		return actionKeepBoth, true
+	}
+}
+
+// Execute a task and update newState with the result. Return true if the task is done.
+func (client *Client) doBisyncTask(localPath string, cosPath string, task bisyncTask, newState *bisyncState) bool {
+	fileLocalPath := localPath + filepath.FromSlash(task.path)
+	fileCosPath := cosPath + task.path
+	uploadOption := &UploadOption{
+		SkipMd5: false,
+		Sync:    true,
+		Force:   true,
+		Yes:     true,
+	}
+	downloadOption := &DownloadOption{
+		Force: true,
+		Yes:   true,
+		Num:   10,
+	}
+	switch task.action {
+	case actionUpload:
+		ret := client.UploadFile(fileLocalPath, fileCosPath, &http.Header{}, uploadOption)
+		if ret != 0 && ret != -2 {
+			return false
+		}
+		return client.refreshRemoteEntry(fileCosPath, task.path, newState)
+	case actionDownload:
+		if client.DownloadFile(fileCosPath, fileLocalPath, &http.Header{}, downloadOption) != 0 {
+			return false
+		}
+		return refreshLocalEntry(fileLocalPath, task.path, newState)
+	case actionDeleteLocal:
+		log.Infof("Delete %s", fileLocalPath)
+		if err := os.Remove(fileLocalPath); err != nil && !os.IsNotExist(err) {
+			log.Warn(err.Error())
+			return false
+		}
+		delete(newState.Local, task.path)
+		delete(newState.Remote, task.path)
+		return true
+	case actionDeleteRemote:
+		if succ, _ := client.DeleteObjects([]string{fileCosPath}); succ != 1 {
+			return false
+		}
+		delete(newState.Local, task.path)
+		delete(newState.Remote, task.path)
+		return true
+	case actionKeepBoth:
+		log.Warnf("Conflict: %s changed on both sides, keep both copies", task.path)
+		localCopy := fileLocalPath + conflictLocalSuffix
+		remoteCopy := fileLocalPath + conflictRemoteSuffix
+		// The originals are only touched after both copies exist on both sides, so that nothing is lost
+		// if it fails in the middle, and the next run finds the conflict again.
+		undo := func() {
+			_ = os.Remove(remoteCopy)
+			_, _ = client.DeleteObjects([]string{fileCosPath + conflictLocalSuffix, fileCosPath + conflictRemoteSuffix})
+		}
+		if client.DownloadFile(fileCosPath, remoteCopy, &http.Header{}, downloadOption) != 0 {
+			_ = os.Remove(remoteCopy)
+			return false
+		}
+		ret := client.UploadFile(fileLocalPath, fileCosPath+conflictLocalSuffix, &http.Header{}, uploadOption)
+		if ret != 0 && ret != -2 {
+			undo()
+			return false
+		}
+		ret = client.UploadFile(remoteCopy, fileCosPath+conflictRemoteSuffix, &http.Header{}, uploadOption)
+		if ret != 0 && ret != -2 {
+			undo()
+			return false
+		}
+		if err := os.Rename(fileLocalPath, localCopy); err != nil {
+			log.Warn(err.Error())
+			undo()
+			return false
+		}
+		for _, suffix := range []string{conflictLocalSuffix, conflictRemoteSuffix} {
+			if !refreshLocalEntry(fileLocalPath+suffix, task.path+suffix, newState) ||
+				!client.refreshRemoteEntry(fileCosPath+suffix, task.path+suffix, newState) {
+				return false
+			}
+		}
+		// Only the two suffixed copies are kept on both sides. If the remote original is not deleted,
+		// the next run finds it deleted locally and deletes it.
+		if succ, _ := client.DeleteObjects([]string{fileCosPath}); succ != 1 {
+			return false
+		}
+		delete(newState.Local, task.path)
+		delete(newState.Remote, task.path)
+		return true
+	}
+	return false
+}
+
+// Whether the local file has the same content as the object, compared by MD5. The ETag is not MD5 of
+// the content for objects uploaded in parts or compressed, then x-cos-meta-md5 set by upload is used.
+// False if unknown.
+func (client *Client) sameBisyncContent(localPath string, cosPath string, remote remoteEntry) bool {
+	localMd5 := coshelper.GetFileMd5(localPath)
+	if localMd5 == "" {
+		return false
+	}
+	if strings.EqualFold(strings.Trim(remote.ETag, `"`), localMd5) {
+		return true
+	}
+	resp, err := client.Client.Object.Head(context.Background(), cosPath, nil)
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	return strings.EqualFold(resp.Header.Get("x-cos-meta-md5"), localMd5)
+}
+
+func (client *Client) refreshRemoteEntry(cosPath string, p string, state *bisyncState) bool {
+	resp, err := client.Client.Object.Head(context.Background(), cosPath, nil)
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	lastModified := ""
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		lastModified = t.UTC().Format(time.RFC3339)
+	}
+	state.Remote[p] = remoteEntry{
+		Size:         resp.ContentLength,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: lastModified,
+	}
+	return true
+}
+
+func refreshLocalEntry(localPath string, p string, state *bisyncState) bool {
+	f, err := os.Stat(localPath)
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	state.Local[p] = localEntry{
+		Size:    f.Size(),
+		ModTime: f.ModTime().UnixNano(),
+	}
+	return true
+}
+
+func revertBisyncEntry(newState *bisyncState, prevState *bisyncState, p string) {
+	if entry, ok := prevState.Local[p]; ok {
+		newState.Local[p] = entry
+	} else {
+		delete(newState.Local, p)
+	}
+	if entry, ok := prevState.Remote[p]; ok {
+		newState.Remote[p] = entry
+	} else {
+		delete(newState.Remote, p)
+	}
+}
+
+func scanLocalFiles(localPath string) (map[string]localEntry, error) {
+	files := make(map[string]localEntry)
+	if !coshelper.IsDir(localPath) {
+		return files, nil
+	}
+	err := filepath.Walk(localPath, func(filePath string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !f.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(localPath, filePath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = localEntry{
+			Size:    f.Size(),
+			ModTime: f.ModTime().UnixNano(),
+		}
+		return nil
+	})
+	return files, err
+}
+
+func (client *Client) scanRemoteFiles(cosPath string) (map[string]remoteEntry, int) {
+	files := make(map[string]remoteEntry)
+	nextMarker := ""
+	isTruncated := true
+	for isTruncated {
+		for i := 0; i <= client.Config.RetryTimes; i++ {
+			result, _, err := client.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+				Prefix:  cosPath,
+				Marker:  nextMarker,
+				MaxKeys: 1000,
+			})
+			if err != nil {
+				log.Warn(err.Error())
+			} else {
+				isTruncated = result.IsTruncated
+				nextMarker = result.NextMarker
+				for _, file := range result.Contents {
+					// if key has suffix /, it is an empty folder, ignore it.
+					if strings.HasSuffix(file.Key, "/") {
+						continue
+					}
+					files[file.Key[len(cosPath):]] = remoteEntry{
+						Size:         file.Size,
+						ETag:         file.ETag,
+						LastModified: file.LastModified,
+					}
+				}
+				break
+			}
+			if i == client.Config.RetryTimes {
+				log.Warn("List object failed")
+				return nil, -1
+			}
+			time.Sleep((1 << i) * time.Second)
+		}
+	}
+	return files, 0
+}
+
+func unionPaths(localFiles map[string]localEntry, remoteFiles map[string]remoteEntry, prevState *bisyncState) []string {
+	seen := make(map[string]struct{})
+	for p := range localFiles {
+		seen[p] = struct{}{}
+	}
+	for p := range remoteFiles {
+		seen[p] = struct{}{}
+	}
+	for p := range prevState.Local {
+		seen[p] = struct{}{}
+	}
+	for p := range prevState.Remote {
+		seen[p] = struct{}{}
+	}
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// The state file is saved in ~/.tmp, named by the digest of bucket and both paths.
+func (client *Client) getBisyncStatePath(localPath string, cosPath string) (string, error) {
+	localAbsPath, err := filepath.Abs(localPath)
+	if err != nil {
+		return "", err
+	}
+	ori := fmt.Sprintf("%s!!!%s!!!%s", localAbsPath, client.Config.Bucket, cosPath)
+	md5sum := fmt.Sprintf("%x", md5.Sum([]byte(ori)))
+	return homedir.Expand("~/.tmp/bisync-" + md5sum + ".json")
+}
+
+func loadBisyncState(statePath string) *bisyncState {
+	state := &bisyncState{
+		Local:  make(map[string]localEntry),
+		Remote: make(map[string]remoteEntry),
+	}
+	content, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		log.Info("No previous bisync state found, this is the first run")
+		return state
+	}
+	if err := json.Unmarshal(content, state); err != nil {
+		log.Warnf("Bisync state file '%s' is broken, ignore it", statePath)
+		return &bisyncState{
+			Local:  make(map[string]localEntry),
+			Remote: make(map[string]remoteEntry),
+		}
+	}
+	if state.Local == nil {
+		state.Local = make(map[string]localEntry)
+	}
+	if state.Remote == nil {
+		state.Remote = make(map[string]remoteEntry)
+	}
+	return state
+}
+
+func saveBisyncState(statePath string, state *bisyncState) error {
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(statePath)
+	if !coshelper.IsDir(dir) {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(statePath, content, 0666)
+}
@@ -0,0 +1,544 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+	"gopkg.in/yaml.v2"
+)
+
+// BucketConfiguration is every supported configuration of a bucket, exported and applied as a whole.
+// Versioning and ACL are left as they are if absent, other configurations are deleted if absent
+// and pruning is requested.
+type BucketConfiguration struct {
+	Versioning  string                    `json:"versioning,omitempty" yaml:"versioning,omitempty"`
+	ACL         *BucketACL                `json:"acl,omitempty" yaml:"acl,omitempty"`
+	Tags        map[string]string         `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Lifecycle   *LifecycleConfiguration   `json:"lifecycle,omitempty" yaml:"lifecycle,omitempty"`
+	CORS        *CORSConfiguration        `json:"cors,omitempty" yaml:"cors,omitempty"`
+	Policy      *PolicyDocument           `json:"policy,omitempty" yaml:"policy,omitempty"`
+	Website     *WebsiteConfiguration     `json:"website,omitempty" yaml:"website,omitempty"`
+	Replication *ReplicationConfiguration `json:"replication,omitempty" yaml:"replication,omitempty"`
+	Inventories []InventoryConfiguration  `json:"inventories,omitempty" yaml:"inventories,omitempty"`
+	Logging     *BucketLogging            `json:"logging,omitempty" yaml:"logging,omitempty"`
+	Encryption  *BucketEncryption         `json:"encryption,omitempty" yaml:"encryption,omitempty"`
+}
+
+// BucketACL lists the grantees of each permission, in the format of PutBucketACL:
+// a root account as 100000000001, a sub account as 100000000001/100000000011, or anyone.
+type BucketACL struct {
+	Read        []string `json:"read,omitempty" yaml:"read,omitempty"`
+	Write       []string `json:"write,omitempty" yaml:"write,omitempty"`
+	FullControl []string `json:"fullControl,omitempty" yaml:"fullControl,omitempty"`
+}
+
+type BucketApplyOption struct {
+	DryRun bool
+	Yes    bool
+	// Prune deletes the configurations absent in the desired ones, instead of leaving them as they are.
+	Prune bool
+}
+
+// A configuration of the bucket compared and applied by ApplyBucket.
+type bucketSection struct {
+	name             string
+	current, desired interface{}
+	// Whether the section is left as it is if absent, instead of being deleted.
+	keepIfAbsent bool
+	put          func() bool
+	delete       func() bool
+}
+
+// Validate checks every configuration, so that nothing is applied if any of them is wrong.
+func (config *BucketConfiguration) Validate() error {
+	switch strings.ToLower(config.Versioning) {
+	case "":
+	case "enabled":
+		config.Versioning = "Enabled"
+	case "suspended":
+		config.Versioning = "Suspended"
+	default:
+		return fmt.Errorf("versioning must be Enabled or Suspended")
+	}
+	if config.ACL != nil {
+		for _, grantees := range [][]string{config.ACL.Read, config.ACL.Write, config.ACL.FullControl} {
+			for _, grantee := range grantees {
+				if grantee == "" || strings.Count(grantee, "/") > 1 || strings.Contains(grantee, ",") {
+					return fmt.Errorf("acl: invalid grantee '%s'", grantee)
+				}
+			}
+		}
+		config.ACL.sort()
+	}
+	if len(config.Tags) > maxBucketTags {
+		return fmt.Errorf("tags: %d tags, at most %d tags are allowed for a bucket", len(config.Tags), maxBucketTags)
+	}
+	for key, value := range config.Tags {
+		if key == "" || len(key) > 128 {
+			return fmt.Errorf("tags: tag key must have 1 to 128 characters: '%s'", key)
+		}
+		if len(value) > 256 {
+			return fmt.Errorf("tags: tag value of '%s' is longer than 256 characters", key)
+		}
+	}
+	if config.Lifecycle != nil {
+		if err := config.Lifecycle.Validate(); err != nil {
+			return fmt.Errorf("lifecycle: %s", err.Error())
+		}
+	}
+	if config.CORS != nil {
+		if err := config.CORS.Validate(); err != nil {
+			return fmt.Errorf("cors: %s", err.Error())
+		}
+	}
+	if config.Policy != nil {
+		if err := config.Policy.Validate(); err != nil {
+			return fmt.Errorf("policy: %s", err.Error())
+		}
+	}
+	if config.Website != nil {
+		if err := config.Website.Validate(); err != nil {
+			return fmt.Errorf("website: %s", err.Error())
+		}
+	}
+	if config.Replication != nil {
+		if err := config.Replication.Validate(); err != nil {
+			return fmt.Errorf("replication: %s", err.Error())
+		}
+	}
+	ids := make(map[string]struct{})
+	for i := range config.Inventories {
+		inventory := &config.Inventories[i]
+		if err := inventory.Validate(); err != nil {
+			return fmt.Errorf("inventory %d: %s", i+1, err.Error())
+		}
+		if _, ok := ids[inventory.ID]; ok {
+			return fmt.Errorf("inventory '%s': id is duplicated", inventory.ID)
+		}
+		ids[inventory.ID] = struct{}{}
+	}
+	if config.Logging != nil {
+		if err := config.Logging.Validate(); err != nil {
+			return fmt.Errorf("logging: %s", err.Error())
+		}
+	}
+	if config.Encryption != nil {
+		if err := config.Encryption.Validate(); err != nil {
+			return fmt.Errorf("encryption: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+func (acl *BucketACL) sort() {
+	sort.Strings(acl.Read)
+	sort.Strings(acl.Write)
+	sort.Strings(acl.FullControl)
+}
+
+// ExportBucket prints every configuration of the bucket in YAML, or in JSON.
+func (client *Client) ExportBucket(jsonOutput bool) bool {
+	config, err := client.getBucketConfiguration()
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	var data []byte
+	if jsonOutput {
+		data, err = json.MarshalIndent(config, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(config)
+	}
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	fmt.Print(string(data))
+	return true
+}
+
+func (client *Client) getBucketConfiguration() (*BucketConfiguration, error) {
+	config := &BucketConfiguration{}
+	versioning, _, err := client.Client.Bucket.GetVersioning(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	config.Versioning = versioning.Status
+	acl, _, err := client.Client.Bucket.GetACL(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	config.ACL = &BucketACL{}
+	for _, grant := range acl.AccessControlList {
+		grantee := aclGranteeName(grant.Grantee)
+		switch grant.Permission {
+		case "READ":
+			config.ACL.Read = append(config.ACL.Read, grantee)
+		case "WRITE":
+			config.ACL.Write = append(config.ACL.Write, grantee)
+		case "FULL_CONTROL":
+			config.ACL.FullControl = append(config.ACL.FullControl, grantee)
+		default:
+			log.Warnf("Permission %s of %s is not supported, it is not exported", grant.Permission, grantee)
+		}
+	}
+	config.ACL.sort()
+	tags, err := client.bucketTags()
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) != 0 {
+		config.Tags = make(map[string]string)
+		for _, tag := range tags {
+			config.Tags[tag.Key] = tag.Value
+		}
+	}
+	lifecycle, configured, err := client.getBucketLifecycle()
+	if err != nil {
+		return nil, err
+	}
+	if configured {
+		config.Lifecycle = lifecycle
+	}
+	cors, configured, err := client.getBucketCORS()
+	if err != nil {
+		return nil, err
+	}
+	if configured {
+		config.CORS = cors
+	}
+	policy, configured, err := client.getBucketPolicy()
+	if err != nil {
+		return nil, err
+	}
+	if configured {
+		config.Policy = policy
+	}
+	website, configured, err := client.getBucketWebsite()
+	if err != nil {
+		return nil, err
+	}
+	if configured {
+		config.Website = website
+	}
+	replication, configured, err := client.getBucketReplication()
+	if err != nil {
+		return nil, err
+	}
+	if configured {
+		config.Replication = replication
+	}
+	if config.Inventories, err = client.getBucketInventories(); err != nil {
+		return nil, err
+	}
+	logging, configured, err := client.getBucketLogging()
+	if err != nil {
+		return nil, err
+	}
+	if configured {
+		config.Logging = logging
+	}
+	encryption, configured, err := client.getBucketEncryption()
+	if err != nil {
+		return nil, err
+	}
+	if configured {
+		config.Encryption = encryption
+	}
+	return config, nil
+}
+
+// The grantee in the format of PutBucketACL, like 100000000001/100000000011.
+func aclGranteeName(grantee *cos.ACLGrantee) string {
+	if grantee == nil {
+		return "anyone"
+	}
+	if strings.HasSuffix(grantee.URI, "AllUsers") || strings.Contains(grantee.ID, "anyone") {
+		return "anyone"
+	}
+	// qcs::cam::uin/100000000001:uin/100000000011
+	id := strings.TrimPrefix(grantee.ID, "qcs::cam::")
+	ids := strings.Split(id, ":")
+	if len(ids) == 2 {
+		root, sub := strings.TrimPrefix(ids[0], "uin/"), strings.TrimPrefix(ids[1], "uin/")
+		if root == sub {
+			return root
+		}
+		return root + "/" + sub
+	}
+	return grantee.ID
+}
+
+// ApplyBucket compares the configurations of the bucket with desired, prints the plan,
+// and applies the changed configurations only.
+// If applied successfully, return 0; if failed, return -1; if canceled, return -3
+func (client *Client) ApplyBucket(desired *BucketConfiguration, options *BucketApplyOption) int {
+	if err := desired.Validate(); err != nil {
+		log.Warnf("Invalid bucket configuration: %s", err.Error())
+		return -1
+	}
+	current, err := client.getBucketConfiguration()
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	// Versioning is suspended if it is never enabled.
+	if current.Versioning == "" {
+		current.Versioning = "Suspended"
+	}
+	sections := client.bucketSections(current, desired)
+
+	changes := make([]*bucketSection, 0)
+	keptNames := make([]string, 0)
+	fmt.Printf("Plan for %s:\n", client.Config.Bucket)
+	for i := range sections {
+		section := &sections[i]
+		if isNilSection(section.desired) && section.keepIfAbsent {
+			continue
+		}
+		currentText, desiredText := sectionText(section.current), sectionText(section.desired)
+		if currentText == desiredText {
+			continue
+		}
+		if desiredText == "" && !options.Prune {
+			keptNames = append(keptNames, section.name)
+			continue
+		}
+		changes = append(changes, section)
+		switch {
+		case desiredText == "":
+			fmt.Printf("- %s will be deleted\n", section.name)
+		case currentText == "":
+			fmt.Printf("+ %s will be created\n", section.name)
+		default:
+			fmt.Printf("~ %s will be updated\n", section.name)
+		}
+		for _, line := range coshelper.DiffLines(currentText, desiredText) {
+			fmt.Println("    " + line)
+		}
+	}
+	if len(keptNames) != 0 {
+		fmt.Printf("%s absent in the file will be kept, use --prune to delete them.\n", strings.Join(keptNames, ", "))
+	}
+	if len(changes) == 0 {
+		fmt.Println("No changes.")
+		return 0
+	}
+	fmt.Printf("%d configurations to change.\n", len(changes))
+	if options.DryRun {
+		return 0
+	}
+	if !options.Yes && !coshelper.Confirm("Apply the changes? ", "no") {
+		return -3
+	}
+	failNum := 0
+	for _, section := range changes {
+		var ok bool
+		if isNilSection(section.desired) {
+			ok = section.delete()
+		} else {
+			ok = section.put()
+		}
+		if !ok {
+			failNum++
+		}
+	}
+	log.Infof("%d configurations changed, %d configurations failed", len(changes)-failNum, failNum)
+	if failNum != 0 {
+		return -1
+	}
+	return 0
+}
+
+func (client *Client) bucketSections(current, desired *BucketConfiguration) []bucketSection {
+	var currentVersioning, desiredVersioning interface{}
+	if current.Versioning != "" {
+		currentVersioning = current.Versioning
+	}
+	if desired.Versioning != "" {
+		desiredVersioning = desired.Versioning
+	}
+	var currentTags, desiredTags interface{}
+	if len(current.Tags) != 0 {
+		currentTags = current.Tags
+	}
+	if len(desired.Tags) != 0 {
+		desiredTags = desired.Tags
+	}
+	sections := []bucketSection{
+		{
+			name:         "versioning",
+			current:      currentVersioning,
+			desired:      desiredVersioning,
+			keepIfAbsent: true,
+			put: func() bool {
+				return client.PutBucketVersioning(desired.Versioning == "Enabled")
+			},
+		},
+		{
+			name:         "acl",
+			current:      current.ACL,
+			desired:      desired.ACL,
+			keepIfAbsent: true,
+			put: func() bool {
+				return client.PutBucketACL(strings.Join(desired.ACL.Read, ","), strings.Join(desired.ACL.Write, ","),
+					strings.Join(desired.ACL.FullControl, ","), "")
+			},
+		},
+		{
+			name:    "tags",
+			current: currentTags,
+			desired: desiredTags,
+			put: func() bool {
+				tags := make([]coshelper.Tag, 0, len(desired.Tags))
+				for key, value := range desired.Tags {
+					tags = append(tags, coshelper.Tag{Key: key, Value: value})
+				}
+				sort.Slice(tags, func(i, j int) bool {
+					return tags[i].Key < tags[j].Key
+				})
+				return client.PutBucketTagging(tags)
+			},
+			delete: client.DeleteBucketTagging,
+		},
+		{
+			name:    "lifecycle",
+			current: current.Lifecycle,
+			desired: desired.Lifecycle,
+			put: func() bool {
+				return client.PutBucketLifecycle(desired.Lifecycle)
+			},
+			delete: client.DeleteBucketLifecycle,
+		},
+		{
+			name:    "cors",
+			current: current.CORS,
+			desired: desired.CORS,
+			put: func() bool {
+				return client.PutBucketCORS(desired.CORS)
+			},
+			delete: client.DeleteBucketCORS,
+		},
+		{
+			name:    "policy",
+			current: current.Policy,
+			desired: desired.Policy,
+			put: func() bool {
+				return client.PutBucketPolicy(desired.Policy)
+			},
+			delete: client.DeleteBucketPolicy,
+		},
+		{
+			name:    "website",
+			current: current.Website,
+			desired: desired.Website,
+			put: func() bool {
+				return client.PutBucketWebsite(desired.Website)
+			},
+			delete: client.DeleteBucketWebsite,
+		},
+		{
+			name:    "replication",
+			current: current.Replication,
+			desired: desired.Replication,
+			put: func() bool {
+				return client.PutBucketReplication(desired.Replication)
+			},
+			delete: client.DeleteBucketReplication,
+		},
+		{
+			name:    "logging",
+			current: current.Logging,
+			desired: desired.Logging,
+			put: func() bool {
+				return client.PutBucketLogging(desired.Logging)
+			},
+			delete: client.DeleteBucketLogging,
+		},
+		{
+			name:    "encryption",
+			current: current.Encryption,
+			desired: desired.Encryption,
+			put: func() bool {
+				return client.PutBucketEncryption(desired.Encryption)
+			},
+			delete: client.DeleteBucketEncryption,
+		},
+	}
+	// Every inventory is a section, since inventories are set and deleted one by one.
+	currentInventories := make(map[string]*InventoryConfiguration)
+	desiredInventories := make(map[string]*InventoryConfiguration)
+	ids := make([]string, 0)
+	for i := range current.Inventories {
+		currentInventories[current.Inventories[i].ID] = &current.Inventories[i]
+		ids = append(ids, current.Inventories[i].ID)
+	}
+	for i := range desired.Inventories {
+		desiredInventories[desired.Inventories[i].ID] = &desired.Inventories[i]
+		if _, ok := currentInventories[desired.Inventories[i].ID]; !ok {
+			ids = append(ids, desired.Inventories[i].ID)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		id, inventory := id, desiredInventories[id]
+		sections = append(sections, bucketSection{
+			name:    "inventory " + id,
+			current: currentInventories[id],
+			desired: inventory,
+			put: func() bool {
+				return client.PutBucketInventory(inventory)
+			},
+			delete: func() bool {
+				return client.DeleteBucketInventory(id)
+			},
+		})
+	}
+	return sections
+}
+
+// Whether the section is absent, including nil pointers in interface{}.
+func isNilSection(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	value := reflect.ValueOf(v)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
+
+// The section in YAML for comparing, empty if it is absent.
+func sectionText(v interface{}) string {
+	if isNilSection(v) {
+		return ""
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
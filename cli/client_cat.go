@@ -0,0 +1,287 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// ByteRange is a range of bytes like HTTP: First-Last, First- to the end, or -Suffix for the last Suffix bytes.
+type ByteRange struct {
+	First int64
+	// Last is -1 to the end.
+	Last   int64
+	Suffix int64
+}
+
+var byteRangePattern = regexp.MustCompile(`^(\d*)-(\d*)$`)
+
+// ParseByteRange parses the range like 0-1023, 1024- or -1024.
+func ParseByteRange(str string) (*ByteRange, error) {
+	matches := byteRangePattern.FindStringSubmatch(str)
+	if matches == nil || (matches[1] == "" && matches[2] == "") {
+		return nil, fmt.Errorf("range should be like 0-1023, 1024- or -1024")
+	}
+	if matches[1] == "" {
+		suffix, _ := strconv.ParseInt(matches[2], 10, 64)
+		if suffix == 0 {
+			return nil, fmt.Errorf("the last 0 bytes is empty")
+		}
+		return &ByteRange{Suffix: suffix}, nil
+	}
+	r := &ByteRange{Last: -1}
+	r.First, _ = strconv.ParseInt(matches[1], 10, 64)
+	if matches[2] != "" {
+		r.Last, _ = strconv.ParseInt(matches[2], 10, 64)
+		if r.Last < r.First {
+			return nil, fmt.Errorf("the last byte %d is before the first byte %d", r.Last, r.First)
+		}
+	}
+	return r, nil
+}
+
+func (r *ByteRange) String() string {
+	if r.Suffix > 0 {
+		return fmt.Sprintf("bytes=-%d", r.Suffix)
+	}
+	if r.Last < 0 {
+		return fmt.Sprintf("bytes=%d-", r.First)
+	}
+	return fmt.Sprintf("bytes=%d-%d", r.First, r.Last)
+}
+
+// The offset and length of the range in a content of size, length is -1 to the end.
+func (r *ByteRange) bounds(size int64) (offset int64, length int64) {
+	if r.Suffix > 0 {
+		offset = size - r.Suffix
+		if offset < 0 {
+			offset = 0
+		}
+		return offset, -1
+	}
+	if r.Last < 0 {
+		return r.First, -1
+	}
+	return r.First, r.Last - r.First + 1
+}
+
+type CatOption struct {
+	// Range of bytes to print, the whole object if nil.
+	Range     *ByteRange
+	VersionID string
+	// Lines only prints the first lines if positive, or the last lines with Tail.
+	Lines int
+	Tail  bool
+	// Headers are sent with the HEAD and GET, like the key of SSE-C.
+	Headers *http.Header
+}
+
+// Cat writes the object to w. Objects with Content-Encoding gzip or zstd, like those uploaded with
+// --compress, are decompressed, and the ranges and lines are of the decompressed content, which is
+// read from the beginning.
+func (client *Client) Cat(cosPath string, w io.Writer, options *CatOption) int {
+	var id []string
+	if options.VersionID != "" {
+		id = append(id, options.VersionID)
+	}
+	resp, err := client.Client.Object.Head(context.Background(), cosPath, &cos.ObjectHeadOptions{
+		XOptionHeader: options.Headers,
+	}, id...)
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	// An empty object has no lines, and any range of it is not satisfiable.
+	if resp.ContentLength == 0 {
+		return 0
+	}
+	encoding := contentEncoding(resp.Header)
+	compressed := encoding != ""
+	if !compressed && options.Lines > 0 && options.Tail {
+		return client.catTailLines(cosPath, w, resp.ContentLength, id, options)
+	}
+	getOptions := &cos.ObjectGetOptions{
+		XOptionHeader: options.Headers,
+	}
+	if options.Range != nil && !compressed {
+		getOptions.Range = options.Range.String()
+	}
+	if compressed {
+		// Accept the encoding explicitly, or the transport decompresses gzip by itself.
+		headers := http.Header{}
+		if options.Headers != nil {
+			headers = options.Headers.Clone()
+		}
+		headers.Set("Accept-Encoding", encoding)
+		getOptions.XOptionHeader = &headers
+	}
+	resp, err = client.Client.Object.Get(context.Background(), cosPath, getOptions, id...)
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := newDecodeReader(resp.Body, encoding)
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	var r io.Reader = body
+	if compressed && options.Range != nil && options.Range.Suffix > 0 && !isCompressed(resp.Header) {
+		// The size after decompressing is only recorded by --compress, keep the last bytes while reading.
+		data, err := readLastBytes(r, options.Range.Suffix)
+		if err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+		r = bytes.NewReader(data)
+	} else if compressed && options.Range != nil {
+		offset, length := options.Range.bounds(uncompressedSize(resp.Header))
+		if _, err := io.CopyN(ioutil.Discard, r, offset); err != nil && err != io.EOF {
+			log.Warn(err.Error())
+			return -1
+		}
+		if length >= 0 {
+			r = io.LimitReader(r, length)
+		}
+	}
+	switch {
+	case options.Lines > 0 && options.Tail:
+		err = copyLastLines(w, r, options.Lines)
+	case options.Lines > 0:
+		err = copyFirstLines(w, r, options.Lines)
+	default:
+		_, err = io.Copy(w, r)
+	}
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	return 0
+}
+
+// Print the last lines by reading larger and larger ranges from the end, so that large objects are not read.
+func (client *Client) catTailLines(cosPath string, w io.Writer, size int64, id []string, options *CatOption) int {
+	var chunk int64 = 64 * 1024
+	for {
+		if chunk > size {
+			chunk = size
+		}
+		getOptions := &cos.ObjectGetOptions{
+			XOptionHeader: options.Headers,
+		}
+		if chunk > 0 {
+			getOptions.Range = fmt.Sprintf("bytes=-%d", chunk)
+		}
+		resp, err := client.Client.Object.Get(context.Background(), cosPath, getOptions, id...)
+		if err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+		// A newline at the end does not start a line.
+		if chunk == size || bytes.Count(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) >= options.Lines {
+			if err := copyLastLines(w, bytes.NewReader(data), options.Lines); err != nil {
+				log.Warn(err.Error())
+				return -1
+			}
+			return 0
+		}
+		chunk *= 4
+	}
+}
+
+func copyFirstLines(w io.Writer, r io.Reader, lines int) error {
+	reader := bufio.NewReader(r)
+	for i := 0; i < lines; i++ {
+		line, err := reader.ReadBytes('\n')
+		if _, werr := w.Write(line); werr != nil {
+			return werr
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Only the last lines are kept while reading.
+func copyLastLines(w io.Writer, r io.Reader, lines int) error {
+	reader := bufio.NewReader(r)
+	last := make([][]byte, 0, lines)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if len(last) == lines {
+				last = last[1:]
+			}
+			last = append(last, line)
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	for _, line := range last {
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Only the last n bytes are kept while reading.
+func readLastBytes(r io.Reader, n int64) ([]byte, error) {
+	var buf bytes.Buffer
+	chunk := make([]byte, 32*1024)
+	for {
+		k, err := r.Read(chunk)
+		buf.Write(chunk[:k])
+		if int64(buf.Len()) > n {
+			buf.Next(buf.Len() - int(n))
+		}
+		if err == io.EOF {
+			return buf.Bytes(), nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
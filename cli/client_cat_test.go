@@ -0,0 +1,191 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+func TestParseByteRange(t *testing.T) {
+	tests := []struct {
+		str    string
+		want   ByteRange
+		header string
+	}{
+		{"0-1023", ByteRange{First: 0, Last: 1023}, "bytes=0-1023"},
+		{"5-5", ByteRange{First: 5, Last: 5}, "bytes=5-5"},
+		{"1024-", ByteRange{First: 1024, Last: -1}, "bytes=1024-"},
+		{"-1024", ByteRange{Suffix: 1024}, "bytes=-1024"},
+	}
+	for _, test := range tests {
+		r, err := ParseByteRange(test.str)
+		if err != nil {
+			t.Errorf("ParseByteRange(%q) returns error: %v", test.str, err)
+			continue
+		}
+		if *r != test.want {
+			t.Errorf("ParseByteRange(%q) = %+v, want %+v", test.str, *r, test.want)
+		}
+		if r.String() != test.header {
+			t.Errorf("ParseByteRange(%q).String() = %q, want %q", test.str, r.String(), test.header)
+		}
+	}
+	for _, str := range []string{"", "-", "-0", "10-5", "a-b", "1-2-3", "bytes=0-1", " 0-1"} {
+		if r, err := ParseByteRange(str); err == nil {
+			t.Errorf("ParseByteRange(%q) = %+v, want error", str, *r)
+		}
+	}
+}
+
+func TestByteRangeBounds(t *testing.T) {
+	tests := []struct {
+		r              ByteRange
+		size           int64
+		offset, length int64
+	}{
+		{ByteRange{First: 10, Last: 19}, 100, 10, 10},
+		{ByteRange{First: 10, Last: -1}, 100, 10, -1},
+		{ByteRange{Suffix: 10}, 100, 90, -1},
+		{ByteRange{Suffix: 200}, 100, 0, -1},
+	}
+	for _, test := range tests {
+		offset, length := test.r.bounds(test.size)
+		if offset != test.offset || length != test.length {
+			t.Errorf("%+v.bounds(%d) = %d, %d, want %d, %d", test.r, test.size, offset, length, test.offset, test.length)
+		}
+	}
+}
+
+func TestCopyLines(t *testing.T) {
+	text := "a\nb\nc\n"
+	tests := []struct {
+		lines       int
+		first, last string
+	}{
+		{1, "a\n", "c\n"},
+		{2, "a\nb\n", "b\nc\n"},
+		{5, text, text},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := copyFirstLines(&buf, strings.NewReader(text), test.lines); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.first {
+			t.Errorf("first %d lines = %q, want %q", test.lines, buf.String(), test.first)
+		}
+		buf.Reset()
+		if err := copyLastLines(&buf, strings.NewReader(text), test.lines); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.last {
+			t.Errorf("last %d lines = %q, want %q", test.lines, buf.String(), test.last)
+		}
+	}
+	// The last line may have no newline.
+	var buf bytes.Buffer
+	if err := copyLastLines(&buf, strings.NewReader("a\nb"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "b" {
+		t.Errorf("last line = %q, want %q", buf.String(), "b")
+	}
+}
+
+func TestCatEmptyObject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			// Ranges of an empty object are not satisfiable.
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	client := &Client{
+		Client: cos.NewClient(&cos.BaseURL{BucketURL: u}, &http.Client{}),
+		Config: &ClientConfig{},
+	}
+	r, _ := ParseByteRange("-10")
+	for _, options := range []*CatOption{
+		{Range: r},
+		{Lines: 10},
+		{Lines: 10, Tail: true},
+	} {
+		var buf bytes.Buffer
+		if ret := client.Cat("empty.txt", &buf, options); ret != 0 || buf.Len() != 0 {
+			t.Errorf("Cat(%+v) = %d with %q", *options, ret, buf.String())
+		}
+	}
+}
+
+func TestCatContentEncoding(t *testing.T) {
+	for _, algorithm := range []string{CompressGzip, CompressZstd} {
+		var buf bytes.Buffer
+		w, err := newCompressWriter(&buf, algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte("a\nb\nc\n"))
+		_ = w.Close()
+		compressed := buf.Bytes()
+		// Objects with Content-Encoding set by other tools have no uncompressed size.
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && r.Header.Get("Range") != "" {
+				t.Errorf("%s: range %s of the compressed content is requested", algorithm, r.Header.Get("Range"))
+			}
+			w.Header().Set("Content-Encoding", algorithm)
+			w.Header().Set("Content-Length", strconv.Itoa(len(compressed)))
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(compressed)
+			}
+		}))
+		u, _ := url.Parse(server.URL)
+		client := &Client{
+			Client: cos.NewClient(&cos.BaseURL{BucketURL: u}, &http.Client{}),
+			Config: &ClientConfig{},
+		}
+		suffix, _ := ParseByteRange("-4")
+		middle, _ := ParseByteRange("2-3")
+		tests := []struct {
+			options *CatOption
+			want    string
+		}{
+			{&CatOption{}, "a\nb\nc\n"},
+			{&CatOption{Range: suffix}, "b\nc\n"},
+			{&CatOption{Range: middle}, "b\n"},
+			{&CatOption{Lines: 1}, "a\n"},
+			{&CatOption{Lines: 2, Tail: true}, "b\nc\n"},
+		}
+		for _, test := range tests {
+			var out bytes.Buffer
+			if ret := client.Cat("a.txt", &out, test.options); ret != 0 || out.String() != test.want {
+				t.Errorf("%s: Cat(%+v) = %d with %q, want %q", algorithm, *test.options, ret, out.String(), test.want)
+			}
+		}
+		server.Close()
+	}
+}
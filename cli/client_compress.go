@@ -0,0 +1,219 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/danwakefield/fnmatch"
+	"github.com/klauspost/compress/zstd"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// Compression algorithms, which are also the values of Content-Encoding.
+const (
+	CompressGzip = "gzip"
+	CompressZstd = "zstd"
+)
+
+// UncompressedSizeHeader keeps the size of the original file of a compressed object.
+const UncompressedSizeHeader = "x-cos-meta-uncompressed-size"
+
+// Whether the file should be compressed when uploading.
+func (options *UploadOption) shouldCompress(localPath string) bool {
+	if options.Compress == "" {
+		return false
+	}
+	if len(options.CompressInclude) == 0 {
+		return true
+	}
+	name := filepath.Base(localPath)
+	for _, pattern := range options.CompressInclude {
+		if fnmatch.Match(pattern, name, 0) {
+			return true
+		}
+	}
+	return false
+}
+
+func newCompressWriter(w io.Writer, algorithm string) (io.WriteCloser, error) {
+	switch algorithm {
+	case CompressGzip:
+		return gzip.NewWriter(w), nil
+	case CompressZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm '%s'", algorithm)
+	}
+}
+
+// Wrap r to decompress the object compressed by upload --compress. Other objects, including those
+// with a Content-Encoding set by other tools, are returned as they are stored.
+func newDecompressReader(r io.Reader, header http.Header) (io.ReadCloser, error) {
+	if !isCompressed(header) {
+		return ioutil.NopCloser(r), nil
+	}
+	return newDecodeReader(r, contentEncoding(header))
+}
+
+// Wrap r to decode the content of encoding, which is gzip, zstd or "" for no encoding.
+func newDecodeReader(r io.Reader, encoding string) (io.ReadCloser, error) {
+	switch encoding {
+	case CompressGzip:
+		return gzip.NewReader(r)
+	case CompressZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return ioutil.NopCloser(r), nil
+	}
+}
+
+// The Content-Encoding of the object if it is gzip or zstd, otherwise "".
+func contentEncoding(header http.Header) string {
+	switch encoding := strings.ToLower(header.Get("Content-Encoding")); encoding {
+	case CompressGzip, CompressZstd:
+		return encoding
+	}
+	return ""
+}
+
+// Whether the object is compressed by upload --compress, so that it can not be downloaded in parts.
+// The size of the original file is only recorded by cosutil, Content-Encoding alone is not enough.
+func isCompressed(header http.Header) bool {
+	return header.Get(UncompressedSizeHeader) != "" && contentEncoding(header) != ""
+}
+
+// Size of the object after decompressing.
+func uncompressedSize(header http.Header) int64 {
+	if size, err := strconv.ParseInt(header.Get(UncompressedSizeHeader), 10, 64); err == nil {
+		return size
+	}
+	size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+// Upload a file compressed by options.Compress. Content-Encoding is set, and the MD5 and size
+// of the original file are recorded in metadata.
+// Small files are compressed in memory and uploaded by PUT, large files are compressed and
+// uploaded in parts at the same time.
+// If upload successfully, return 0; if skipped, return -2; if failed, return -1
+func (client *Client) compressedUpload(localPath string, cosPath string, headers *http.Header, options *UploadOption) int {
+	localMd5 := ""
+	fileSize, err := coshelper.GetFileSize(localPath)
+	if err != nil {
+		return 2
+	}
+	if !options.SkipMd5 {
+		if fileSize > 20*1024*1024 {
+			log.Infof(`The MD5 of file "%s" is being calculated, please wait. If you do not need to calculate MD5, you can use --skipmd5 to skip`,
+				localPath)
+		}
+		localMd5 = coshelper.GetFileMd5(localPath)
+		log.Debugf(`The MD5 of file "%s" is "%s"`, localPath, localMd5)
+	}
+	if !client.localToRemoteSyncCheck(localPath, cosPath, localMd5, fileSize, options) {
+		return -2
+	}
+	compressHeaders := *options.objectHeaders(localPath, cosPath, headers)
+	compressHeaders.Set("x-cos-meta-md5", localMd5)
+	compressHeaders.Set("Content-Encoding", options.Compress)
+	compressHeaders.Set(UncompressedSizeHeader, strconv.FormatInt(fileSize, 10))
+	log.Infof("Upload %s   =>   cos://%s/%s (%s)",
+		localPath, client.Config.Bucket, cosPath, options.Compress)
+
+	if fileSize > int64(client.Config.PartSize)*1024*1024 {
+		pr, pw := io.Pipe()
+		go func() {
+			_ = pw.CloseWithError(compressFile(pw, localPath, options.Compress))
+		}()
+		ret := client.uploadStream(pr, cosPath, &compressHeaders)
+		_ = pr.Close()
+		if ret != 0 {
+			log.Warnf(`Upload file "%s" FAILED.`, localPath)
+		}
+		return ret
+	}
+
+	var buf bytes.Buffer
+	if err := compressFile(&buf, localPath, options.Compress); err != nil {
+		log.Warn(err.Error())
+		log.Warnf(`Upload file "%s" FAILED.`, localPath)
+		return -1
+	}
+	log.Debugf("%s is compressed from %d bytes to %d bytes", localPath, fileSize, buf.Len())
+	for j := 0; j <= client.Config.RetryTimes; j++ {
+		if j > 0 {
+			log.Infof("Retry to upload %s   =>   cos://%s/%s",
+				localPath, client.Config.Bucket, cosPath)
+		}
+		_, err := client.Client.Object.Put(context.Background(), cosPath, bytes.NewReader(buf.Bytes()), &cos.ObjectPutOptions{
+			ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+				XOptionHeader: &compressHeaders,
+			},
+		})
+		if err == nil {
+			return 0
+		}
+		log.Warn(err.Error())
+		if j < client.Config.RetryTimes {
+			time.Sleep((1 << j) * time.Second)
+		}
+	}
+	log.Warnf(`Upload file "%s" FAILED.`, localPath)
+	return -1
+}
+
+// Write the compressed content of the file to w.
+func compressFile(w io.Writer, localPath string, algorithm string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	cw, err := newCompressWriter(w, algorithm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(cw, f); err != nil {
+		_ = cw.Close()
+		return err
+	}
+	return cw.Close()
+}
@@ -0,0 +1,71 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestDecompressReader(t *testing.T) {
+	for _, algorithm := range []string{CompressGzip, CompressZstd} {
+		var buf bytes.Buffer
+		w, err := newCompressWriter(&buf, algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte("hello")); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		compressed := buf.Bytes()
+
+		header := http.Header{}
+		header.Set("Content-Encoding", algorithm)
+		header.Set(UncompressedSizeHeader, "5")
+		if !isCompressed(header) {
+			t.Errorf("%s: object uploaded with --compress is not compressed", algorithm)
+		}
+		r, err := newDecompressReader(bytes.NewReader(compressed), header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(r)
+		_ = r.Close()
+		if err != nil || string(data) != "hello" {
+			t.Errorf("%s: decompressed %q, %v", algorithm, data, err)
+		}
+
+		// Content-Encoding set by other tools is kept, the object is stored as it is.
+		header.Del(UncompressedSizeHeader)
+		if isCompressed(header) {
+			t.Errorf("%s: object without %s is compressed", algorithm, UncompressedSizeHeader)
+		}
+		r, err = newDecompressReader(bytes.NewReader(compressed), header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ = ioutil.ReadAll(r)
+		if !bytes.Equal(data, compressed) {
+			t.Errorf("%s: object without %s is decompressed", algorithm, UncompressedSizeHeader)
+		}
+	}
+}
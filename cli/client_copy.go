@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
@@ -27,7 +28,6 @@ import (
 
 	"github.com/huanght1997/cosutil/coshelper"
 
-	"github.com/danwakefield/fnmatch"
 	log "github.com/sirupsen/logrus"
 	"github.com/tencentyun/cos-go-sdk-v5"
 )
@@ -35,13 +35,21 @@ import (
 type CopyOption struct {
 	Sync      bool
 	Force     bool
-	Yes	bool
+	Yes       bool
 	Directive string
 	SkipMd5   bool
-	Ignore    []string
-	Include   []string
+	Filter    *coshelper.Filter
 	Delete    bool
 	Move      bool
+	// StorageClass of the target objects, empty for the default of the bucket.
+	StorageClass string
+	// Tags replacing the tags of the source objects if not empty.
+	Tags []coshelper.Tag
+	// Inventory lists the source objects instead of Bucket.Get if not nil.
+	Inventory *Inventory
+	// SSE is the encryption of the target objects, SourceSSE has the SSE-C key of the source objects.
+	SSE       *coshelper.SSE
+	SourceSSE *coshelper.SSE
 }
 
 // sourcePath: bucket-appid.cos.ap-guangzhou.myqcloud.com/path/
@@ -80,7 +88,7 @@ func (client *Client) CopyFolder(sourcePath string, cosPath string, headers *htt
 	for isTruncated {
 		var i int
 		for i = 0; i <= client.Config.RetryTimes; i++ {
-			result, _, err := sourceClient.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+			result, err := sourceClient.getObjects(options.Inventory, &cos.BucketGetOptions{
 				Prefix:    sourcePath,
 				Delimiter: "",
 				Marker:    nextMarker,
@@ -101,10 +109,16 @@ func (client *Client) CopyFolder(sourcePath string, cosPath string, headers *htt
 					} else {
 						fileCosPath = cosPath + filePath[len(sourcePath):]
 					}
+					if options.Filter.ExcludedFile(relativeKey(fileCosPath, cosPath), objectAttr(file.Size, file.LastModified, file.StorageClass)) {
+						log.Debugf("Skip cos://%s => cos://%s/%s",
+							fileSourcePath, client.Config.Bucket, fileCosPath)
+						skipNum++
+						continue
+					}
 					task++
 					go func(sourcePath, cosPath string) {
 						copying <- struct{}{}
-						copyResults <- client.CopyFile(sourcePath, cosPath, headers, options)
+						copyResults <- client.copyFile(sourcePath, cosPath, headers, options)
 						<-copying
 					}(fileSourcePath, fileCosPath)
 				}
@@ -145,7 +159,7 @@ func (client *Client) CopyFolder(sourcePath string, cosPath string, headers *htt
 			}
 		}
 		log.Info("Synchronizing delete, please wait.")
-		ret, delSucc, delFail := client.remoteToRemoteSyncDelete(sourceClient, rawSourcePath, rawCosPath)
+		ret, delSucc, delFail := client.remoteToRemoteSyncDelete(sourceClient, rawSourcePath, rawCosPath, options.Filter)
 		if ret != 0 {
 			log.Warn("Sync delete fail")
 		} else {
@@ -162,6 +176,36 @@ func (client *Client) CopyFolder(sourcePath string, cosPath string, headers *htt
 // sourcePath: bucket-appid.cos.ap-guangzhou.myqcloud.com/path/to/file
 // cosPath: test/file
 func (client *Client) CopyFile(sourcePath string, cosPath string, headers *http.Header, options *CopyOption) int {
+	if options.Filter.Excluded(sourcePath[strings.Index(sourcePath, "/")+1:], false) {
+		log.Debugf("Skip cos://%s => cos://%s/%s",
+			sourcePath, client.Config.Bucket, cosPath)
+		return -2
+	}
+	if options.Filter != nil {
+		// Attributes of the source object are needed for size, time and storage class predicates.
+		sourceClient, err := client.sourcePathToClient(sourcePath)
+		if err != nil {
+			return -1
+		}
+		sourceSchema := strings.Split(sourcePath, "/")[0] + "/"
+		resp, err := sourceClient.Client.Object.Head(context.Background(), sourcePath[len(sourceSchema):], &cos.ObjectHeadOptions{
+			XOptionHeader: options.SourceSSE.ReadHeaders(),
+		})
+		if err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+		if options.Filter.ExcludedFile(sourcePath[len(sourceSchema):], headerAttr(resp.Header)) {
+			log.Debugf("Skip cos://%s => cos://%s/%s",
+				sourcePath, client.Config.Bucket, cosPath)
+			return -2
+		}
+	}
+	return client.copyFile(sourcePath, cosPath, headers, options)
+}
+
+// Copy a single file without checking filter.
+func (client *Client) copyFile(sourcePath string, cosPath string, headers *http.Header, options *CopyOption) int {
 	sourceClient, err := client.sourcePathToClient(sourcePath)
 	if err != nil {
 		return -1
@@ -175,34 +219,78 @@ func (client *Client) CopyFile(sourcePath string, cosPath string, headers *http.
 			client.Config.Bucket, cosPath)
 	}
 	// Check whether a single Copy interface could be use.
-	justCopy := false
-	// if less than 5GB, just use it.
+	// if less than 5GB, just use it, whatever the storage class is.
 	// if the source and the target COS bucket are in the same region, just use it.
-	resp, err := sourceClient.Client.Object.Head(context.Background(), sourcePath[strings.Index(sourcePath, "/")+1:], nil)
+	resp, err := sourceClient.Client.Object.Head(context.Background(), sourcePath[strings.Index(sourcePath, "/")+1:], &cos.ObjectHeadOptions{
+		XOptionHeader: options.SourceSSE.ReadHeaders(),
+	})
 	if err != nil {
 		log.Warn(err.Error())
 		return -1
 	}
-	fileSize, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
-	if fileSize < singleUploadMaxSize {
-		justCopy = true
-	}
+	fileSize, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	justCopy := fileSize < singleUploadMaxSize
 	if sourceClient.Config.Endpoint == client.Config.Endpoint &&
 		resp.Header.Get("x-cos-storage-class") == "" {
 		// we now only support copy to another bucket with STANDARD storage class.
 		justCopy = true
 	}
 	if justCopy {
-		_, _, err = client.Client.Object.Copy(context.Background(), cosPath, sourcePath, nil)
+		// The headers replace the metadata of the source only with the Replaced directive.
+		copyHeaders := http.Header{}
+		if options.Directive == "Replaced" && headers != nil {
+			copyHeaders = headers.Clone()
+		}
+		if len(options.Tags) != 0 {
+			copyHeaders.Set("x-cos-tagging", coshelper.EncodeTags(options.Tags))
+			copyHeaders.Set("x-cos-tagging-directive", "Replaced")
+		}
+		options.SSE.SetWriteHeaders(copyHeaders)
+		options.SourceSSE.SetCopySourceHeaders(copyHeaders)
+		_, _, err = client.Client.Object.Copy(context.Background(), cosPath, sourcePath, &cos.ObjectCopyOptions{
+			ObjectCopyHeaderOptions: &cos.ObjectCopyHeaderOptions{
+				XCosMetadataDirective: options.Directive,
+				XCosStorageClass:      options.StorageClass,
+				XOptionHeader:         &copyHeaders,
+			},
+		})
 		if err != nil {
 			log.Warn(err.Error())
 			return -1
 		}
 	} else {
+		// Parts do not carry the metadata, take it from the source unless it is replaced.
+		initHeaders := http.Header{}
+		if options.Directive == "Replaced" {
+			if headers != nil {
+				initHeaders = headers.Clone()
+			}
+		} else {
+			if headers != nil && len(*headers) != 0 {
+				log.Warnf("The headers of cos://%s/%s are ignored, use -d Replaced to replace the metadata",
+					client.Config.Bucket, cosPath)
+			}
+			initHeaders = objectMetaHeaders(resp.Header)
+		}
+		if options.StorageClass != "" {
+			initHeaders.Set("x-cos-storage-class", options.StorageClass)
+		}
+		// Parts do not carry the tags either, copy them from the source unless they are given.
+		tags := options.Tags
+		if len(tags) == 0 && resp.Header.Get("x-cos-tagging-count") != "" {
+			if tags, err = sourceClient.objectTags(sourcePath[strings.Index(sourcePath, "/")+1:]); err != nil {
+				log.Warn(err.Error())
+				return -1
+			}
+		}
+		if len(tags) != 0 {
+			initHeaders.Set("x-cos-tagging", coshelper.EncodeTags(tags))
+		}
+		options.SSE.SetWriteHeaders(initHeaders)
 		// Create Multipart upload first.
 		result, _, err := client.Client.Object.InitiateMultipartUpload(context.Background(), cosPath, &cos.InitiateMultipartUploadOptions{
 			ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
-				XOptionHeader: headers,
+				XOptionHeader: &initHeaders,
 			},
 		})
 		if err != nil {
@@ -231,9 +319,7 @@ func (client *Client) CopyFile(sourcePath string, cosPath string, headers *http.
 			go func(idx int, start, end int64) {
 				copying <- struct{}{}
 				for j := 0; j <= client.Config.RetryTimes; j++ {
-					result, _, err := client.Client.Object.CopyPart(context.Background(), cosPath, uploadID, idx, sourcePath, &cos.ObjectCopyPartOptions{
-						XCosCopySourceRange: fmt.Sprintf("bytes=%d-%d", start, end),
-					})
+					etag, err := client.copyPart(cosPath, uploadID, idx, sourcePath, start, end, options)
 					if err != nil {
 						log.Warnf("An error occurred when copying the %d part (total %d), retry time: %d, error message: '%s'",
 							idx, partsNum, j, err.Error())
@@ -244,7 +330,7 @@ func (client *Client) CopyFile(sourcePath string, cosPath string, headers *http.
 						}
 						time.Sleep((1 << j) * time.Second)
 					} else {
-						copyResult <- fmt.Sprintf("%d#%s", idx, result.ETag)
+						copyResult <- fmt.Sprintf("%d#%s", idx, etag)
 						break
 					}
 				}
@@ -292,8 +378,85 @@ func (client *Client) CopyFile(sourcePath string, cosPath string, headers *http.
 	return 0
 }
 
-// Delete objects source client does not have but target client has.
-func (client *Client) remoteToRemoteSyncDelete(sourceClient *Client, sourcePath string, cosPath string) (ret, successNum, failNum int) {
+// Copy the object to itself, to change its metadata or storage class. header is the response of HEAD.
+// Copying resets the encryption and the ACL, so SSE-COS and SSE-KMS are applied to the copy again,
+// and the ACL is put back after copying.
+func (client *Client) copyInPlace(cosPath string, header http.Header, headers *http.Header, options *CopyOption) int {
+	switch header.Get("x-cos-server-side-encryption") {
+	case "AES256":
+		options.SSE = &coshelper.SSE{Type: "cos"}
+	case "cos/kms":
+		options.SSE = &coshelper.SSE{
+			Type:     "kms",
+			KMSKeyID: header.Get("x-cos-server-side-encryption-cos-kms-key-id"),
+		}
+	}
+	acl, _, err := client.Client.Object.GetACL(context.Background(), cosPath)
+	if err != nil {
+		log.Warnf("Failed to read the ACL of cos://%s/%s: %s", client.Config.Bucket, cosPath, err.Error())
+		return -1
+	}
+	sourcePath := client.Config.Bucket + "." + client.Config.Endpoint + "/" + cosPath
+	if ret := client.copyFile(sourcePath, cosPath, headers, options); ret != 0 {
+		return ret
+	}
+	if isDefaultObjectACL(acl) {
+		return 0
+	}
+	if _, err := client.Client.Object.PutACL(context.Background(), cosPath, &cos.ObjectPutACLOptions{
+		Body: acl,
+	}); err != nil {
+		log.Warnf("cos://%s/%s is copied, but its ACL is not restored: %s",
+			client.Config.Bucket, cosPath, err.Error())
+		return -1
+	}
+	return 0
+}
+
+// Whether the ACL only grants the owner full control, which objects inherit from the bucket.
+// Putting it again would stop the object from inheriting the ACL of the bucket.
+func isDefaultObjectACL(acl *cos.ObjectGetACLResult) bool {
+	for _, grant := range acl.AccessControlList {
+		if grant.Grantee == nil || acl.Owner == nil || grant.Grantee.ID != acl.Owner.ID ||
+			grant.Permission != "FULL_CONTROL" {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy a part of the source object, returning the ETag of the part.
+// The SDK can not send the SSE-C headers of parts, the request is sent by ourselves if SSE-C is used.
+func (client *Client) copyPart(cosPath string, uploadID string, partNumber int, sourcePath string, start, end int64, options *CopyOption) (string, error) {
+	sourceRange := fmt.Sprintf("bytes=%d-%d", start, end)
+	partHeaders := http.Header{}
+	options.SSE.SetReadHeaders(partHeaders)
+	options.SourceSSE.SetCopySourceHeaders(partHeaders)
+	if len(partHeaders) == 0 {
+		result, _, err := client.Client.Object.CopyPart(context.Background(), cosPath, uploadID, partNumber, sourcePath, &cos.ObjectCopyPartOptions{
+			XCosCopySourceRange: sourceRange,
+		})
+		if err != nil {
+			return "", err
+		}
+		return result.ETag, nil
+	}
+	partHeaders.Set("x-cos-copy-source", sourcePath)
+	partHeaders.Set("x-cos-copy-source-range", sourceRange)
+	uri := fmt.Sprintf("/%s?partNumber=%d&uploadId=%s", url.PathEscape(cosPath), partNumber, url.QueryEscape(uploadID))
+	var result cos.CopyPartResult
+	if err := client.sendRequest(http.MethodPut, uri, partHeaders, nil, &result); err != nil {
+		return "", err
+	}
+	// Errors of copying may be in the body of 200 OK.
+	if result.ETag == "" {
+		return "", fmt.Errorf("copy part %d of cos://%s/%s failed", partNumber, client.Config.Bucket, cosPath)
+	}
+	return result.ETag, nil
+}
+
+// Delete objects source client does not have but target client has. Objects excluded by filter are kept.
+func (client *Client) remoteToRemoteSyncDelete(sourceClient *Client, sourcePath string, cosPath string, filter *coshelper.Filter) (ret, successNum, failNum int) {
 	successNum = 0
 	failNum = 0
 	nextMarker := ""
@@ -316,6 +479,9 @@ func (client *Client) remoteToRemoteSyncDelete(sourceClient *Client, sourcePath
 				nextMarker = result.NextMarker
 				for _, file := range result.Contents {
 					fileCosPath := file.Key
+					if filter.ExcludedFile(relativeKey(fileCosPath, cosPath), objectAttr(file.Size, file.LastModified, file.StorageClass)) {
+						continue
+					}
 					fileSourcePath := sourcePath + fileCosPath[len(cosPath):]
 					// if there is no file in source client, add it to deleteList
 					resp, _ := sourceClient.Client.Object.Head(context.Background(), fileSourcePath, nil)
@@ -338,6 +504,21 @@ func (client *Client) remoteToRemoteSyncDelete(sourceClient *Client, sourcePath
 	return 0, successNum, failNum
 }
 
+// Headers of the metadata in the response of HEAD, which are kept when copying.
+func objectMetaHeaders(header http.Header) http.Header {
+	meta := http.Header{}
+	for key, values := range header {
+		lowerKey := strings.ToLower(key)
+		switch {
+		case strings.HasPrefix(lowerKey, "x-cos-meta-"),
+			lowerKey == "content-type", lowerKey == "content-encoding", lowerKey == "content-language",
+			lowerKey == "content-disposition", lowerKey == "cache-control", lowerKey == "expires":
+			meta[key] = values
+		}
+	}
+	return meta
+}
+
 // sourcePath to Client
 func (client *Client) sourcePathToClient(sourcePath string) (*Client, error) {
 	sourceTmpPath := strings.Split(sourcePath, "/")
@@ -358,44 +539,29 @@ func (client *Client) sourcePathToClient(sourcePath string) (*Client, error) {
 	return NewClient(&sourceConfig), nil
 }
 
+// Check whether the copy should be processed. Filter rules are checked by callers.
 func (client *Client) remoteToRemoteSyncCheck(sourcePath, cosPath string, options *CopyOption) bool {
 	sourceKey := sourcePath[strings.Index(sourcePath, "/")+1:]
-	// check this path is in ignore or include list
-	isInclude, isIgnore := false, false
-	for _, rule := range options.Include {
-		if fnmatch.Match(rule, sourceKey, 0) {
-			isInclude = true
-			break
-		}
-	}
-	for _, rule := range options.Ignore {
-		if fnmatch.Match(rule, sourceKey, 0) {
-			isIgnore = true
-			break
-		}
-	}
 	sourceClient, err := client.sourcePathToClient(sourcePath)
 	if err != nil {
 		log.Warn(err.Error())
 		return true
 	}
-	if !isInclude || isIgnore {
-		log.Debugf("Skip cos://%s/%s => cos://%s/%s",
-			sourceClient.Config.Bucket, sourceKey,
-			client.Config.Bucket, cosPath)
-		return false
-	}
 	if !options.Force && options.Sync {
 		srcMd5, dstMd5 := "src", "dst"
 		var srcSize, dstSize int64 = -1, -2
-		sourceResp, err := sourceClient.Client.Object.Head(context.Background(), sourceKey, nil)
+		sourceResp, err := sourceClient.Client.Object.Head(context.Background(), sourceKey, &cos.ObjectHeadOptions{
+			XOptionHeader: options.SourceSSE.ReadHeaders(),
+		})
 		if err != nil {
 			return true
 		} else if sourceResp.StatusCode == 200 {
 			srcMd5 = sourceResp.Header.Get("x-cos-meta-md5")
 			srcSize = sourceResp.ContentLength
 		}
-		targetResp, err := client.Client.Object.Head(context.Background(), cosPath, nil)
+		targetResp, err := client.Client.Object.Head(context.Background(), cosPath, &cos.ObjectHeadOptions{
+			XOptionHeader: options.SSE.ReadHeaders(),
+		})
 		if err != nil {
 			return true
 		} else if targetResp.StatusCode == 200 {
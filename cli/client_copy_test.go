@@ -0,0 +1,51 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+func TestIsDefaultObjectACL(t *testing.T) {
+	owner := &cos.Owner{ID: "qcs::cam::uin/1:uin/1"}
+	ownerGrant := cos.ACLGrant{Grantee: &cos.ACLGrantee{ID: owner.ID}, Permission: "FULL_CONTROL"}
+	tests := []struct {
+		name string
+		acl  cos.ObjectGetACLResult
+		want bool
+	}{
+		{"empty", cos.ObjectGetACLResult{Owner: owner}, true},
+		{"owner", cos.ObjectGetACLResult{Owner: owner, AccessControlList: []cos.ACLGrant{ownerGrant}}, true},
+		{"public read", cos.ObjectGetACLResult{Owner: owner, AccessControlList: []cos.ACLGrant{
+			ownerGrant,
+			{Grantee: &cos.ACLGrantee{URI: "http://cam.qcloud.com/groups/global/AllUsers"}, Permission: "READ"},
+		}}, false},
+		{"owner read", cos.ObjectGetACLResult{Owner: owner, AccessControlList: []cos.ACLGrant{
+			{Grantee: &cos.ACLGrantee{ID: owner.ID}, Permission: "READ"},
+		}}, false},
+		{"other account", cos.ObjectGetACLResult{Owner: owner, AccessControlList: []cos.ACLGrant{
+			{Grantee: &cos.ACLGrantee{ID: "qcs::cam::uin/2:uin/2"}, Permission: "FULL_CONTROL"},
+		}}, false},
+	}
+	for _, test := range tests {
+		if got := isDefaultObjectACL(&test.acl); got != test.want {
+			t.Errorf("%s: isDefaultObjectACL = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
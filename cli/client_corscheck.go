@@ -0,0 +1,125 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type CORSCheckOption struct {
+	Origin  string
+	Method  string
+	Headers []string
+}
+
+// CORSCheck fetches the CORS rules of the bucket and evaluates them locally, explaining whether
+// a browser request to cosPath from options.Origin would be allowed. CORS rules apply to the
+// whole bucket, so cosPath is only shown in the messages.
+// If allowed, return 0; if denied, return 1; if failed, return -1
+func (client *Client) CORSCheck(cosPath string, options *CORSCheckOption) int {
+	config, configured, err := client.getBucketCORS()
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	request := fmt.Sprintf("%s cos://%s/%s from %s", options.Method, client.Config.Bucket, cosPath, options.Origin)
+	if !configured {
+		log.Infof("DENIED: %s, no CORS rule is configured", request)
+		return 1
+	}
+	for i, rule := range config.Rules {
+		name := fmt.Sprintf("Rule %d", i+1)
+		if rule.ID != "" {
+			name = fmt.Sprintf("Rule '%s'", rule.ID)
+		}
+		if reason := rule.mismatch(options); reason != "" {
+			log.Infof("%s does not match: %s", name, reason)
+			continue
+		}
+		log.Infof("%s matches", name)
+		log.Infof("ALLOWED: %s", request)
+		fmt.Println("Access-Control-Allow-Origin: " + options.Origin)
+		fmt.Println("Access-Control-Allow-Methods: " + strings.Join(rule.AllowedMethods, ","))
+		if len(options.Headers) != 0 {
+			fmt.Println("Access-Control-Allow-Headers: " + strings.Join(options.Headers, ","))
+		}
+		if len(rule.ExposeHeaders) != 0 {
+			fmt.Println("Access-Control-Expose-Headers: " + strings.Join(rule.ExposeHeaders, ","))
+		}
+		if rule.MaxAgeSeconds != 0 {
+			fmt.Println("Access-Control-Max-Age: " + strconv.Itoa(rule.MaxAgeSeconds))
+		}
+		return 0
+	}
+	log.Infof("DENIED: %s, no CORS rule matches", request)
+	return 1
+}
+
+// Return why the rule does not match the request, or "" if it matches.
+func (rule *CORSRule) mismatch(options *CORSCheckOption) string {
+	originMatched := false
+	for _, origin := range rule.AllowedOrigins {
+		if corsWildcardMatch(origin, options.Origin, false) {
+			originMatched = true
+			break
+		}
+	}
+	if !originMatched {
+		return fmt.Sprintf("origin %s is not in %s", options.Origin, strings.Join(rule.AllowedOrigins, ", "))
+	}
+	methodMatched := false
+	for _, method := range rule.AllowedMethods {
+		if strings.EqualFold(method, options.Method) {
+			methodMatched = true
+			break
+		}
+	}
+	if !methodMatched {
+		return fmt.Sprintf("method %s is not in %s", options.Method, strings.Join(rule.AllowedMethods, ", "))
+	}
+	for _, header := range options.Headers {
+		headerMatched := false
+		for _, allowed := range rule.AllowedHeaders {
+			if corsWildcardMatch(allowed, header, true) {
+				headerMatched = true
+				break
+			}
+		}
+		if !headerMatched {
+			return fmt.Sprintf("header %s is not allowed", header)
+		}
+	}
+	return ""
+}
+
+// Match s against pattern with at most one '*' which matches any string.
+func corsWildcardMatch(pattern string, s string, ignoreCase bool) bool {
+	if ignoreCase {
+		pattern = strings.ToLower(pattern)
+		s = strings.ToLower(s)
+	}
+	star := strings.Index(pattern, "*")
+	if star < 0 {
+		return pattern == s
+	}
+	prefix, suffix := pattern[:star], pattern[star+1:]
+	return len(s) >= len(prefix)+len(suffix) && strings.HasPrefix(s, prefix) && strings.HasSuffix(s, suffix)
+}
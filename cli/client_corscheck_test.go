@@ -0,0 +1,86 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCORSWildcardMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		ignoreCase bool
+		match      bool
+	}{
+		{"*", "https://example.com", false, true},
+		{"https://example.com", "https://example.com", false, true},
+		{"https://example.com", "https://example.com:8080", false, false},
+		{"https://*.example.com", "https://www.example.com", false, true},
+		{"https://*.example.com", "https://example.com", false, false},
+		{"https://*.example.com", "http://www.example.com", false, false},
+		{"https://*.example.com", "https://WWW.EXAMPLE.COM", false, false},
+		{"x-cos-*", "X-Cos-Meta-A", true, true},
+		{"x-cos-*", "Content-Type", true, false},
+		// The prefix and suffix must not overlap.
+		{"ab*ba", "aba", false, false},
+	}
+	for _, test := range tests {
+		if got := corsWildcardMatch(test.pattern, test.s, test.ignoreCase); got != test.match {
+			t.Errorf("corsWildcardMatch(%q, %q, %v) = %v, want %v",
+				test.pattern, test.s, test.ignoreCase, got, test.match)
+		}
+	}
+}
+
+func TestCORSRuleMismatch(t *testing.T) {
+	rule := CORSRule{
+		AllowedOrigins: []string{"https://example.com", "https://*.example.org"},
+		AllowedMethods: []string{"GET", "PUT"},
+		AllowedHeaders: []string{"Content-Type", "x-cos-meta-*"},
+	}
+	tests := []struct {
+		name   string
+		option CORSCheckOption
+		reason string
+	}{
+		{"match", CORSCheckOption{Origin: "https://example.com", Method: "GET"}, ""},
+		{"wildcard origin", CORSCheckOption{Origin: "https://a.example.org", Method: "put"}, ""},
+		{"headers", CORSCheckOption{
+			Origin:  "https://example.com",
+			Method:  "PUT",
+			Headers: []string{"content-type", "X-Cos-Meta-Author"},
+		}, ""},
+		{"origin", CORSCheckOption{Origin: "https://example.net", Method: "GET"}, "origin"},
+		{"method", CORSCheckOption{Origin: "https://example.com", Method: "DELETE"}, "method"},
+		{"header", CORSCheckOption{
+			Origin:  "https://example.com",
+			Method:  "GET",
+			Headers: []string{"Content-Type", "Authorization"},
+		}, "header Authorization"},
+	}
+	for _, test := range tests {
+		option := test.option
+		reason := rule.mismatch(&option)
+		switch {
+		case test.reason == "" && reason != "":
+			t.Errorf("%s: does not match: %s", test.name, reason)
+		case test.reason != "" && !strings.Contains(reason, test.reason):
+			t.Errorf("%s: reason %q, want %q", test.name, reason, test.reason)
+		}
+	}
+}
@@ -33,6 +33,9 @@ type DeleteOption struct {
 	Yes       bool
 	Versions  bool
 	VersionID string
+	Filter    *coshelper.Filter
+	// Inventory lists the objects instead of Bucket.Get if not nil.
+	Inventory *Inventory
 }
 
 func (client *Client) DeleteFolder(cosPath string, options *DeleteOption) int {
@@ -47,6 +50,7 @@ func (client *Client) DeleteFolder(cosPath string, options *DeleteOption) int {
 	}
 	haveDeletedNum := 0
 	totalDeleteFileNum := 0
+	listedNum := 0
 	nextMarker := ""
 	keyMarker := ""
 	versionIDMarker := ""
@@ -67,7 +71,7 @@ func (client *Client) DeleteFolder(cosPath string, options *DeleteOption) int {
 					MaxKeys:         1000,
 				})
 			} else {
-				result, _, err = client.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+				result, err = client.getObjects(options.Inventory, &cos.BucketGetOptions{
 					Prefix:  cosPath,
 					Marker:  nextMarker,
 					MaxKeys: 1000,
@@ -88,8 +92,12 @@ func (client *Client) DeleteFolder(cosPath string, options *DeleteOption) int {
 			isTruncated = rt.IsTruncated
 			keyMarker = rt.NextKeyMarker
 			versionIDMarker = rt.NextVersionIdMarker
+			listedNum += len(rt.DeleteMarker) + len(rt.Version)
 			// if delete marker found, this version is specified for deleting.
 			for _, file := range rt.DeleteMarker {
+				if options.Filter.ExcludedFile(relativeKey(file.Key, cosPath), objectAttr(-1, file.LastModified, "")) {
+					continue
+				}
 				deleteList = append(deleteList, cos.Object{
 					Key:       file.Key,
 					VersionId: file.VersionId,
@@ -97,6 +105,9 @@ func (client *Client) DeleteFolder(cosPath string, options *DeleteOption) int {
 			}
 			// History file
 			for _, file := range rt.Version {
+				if options.Filter.ExcludedFile(relativeKey(file.Key, cosPath), objectAttr(int64(file.Size), file.LastModified, file.StorageClass)) {
+					continue
+				}
 				deleteList = append(deleteList, cos.Object{
 					Key:       file.Key,
 					VersionId: file.VersionId,
@@ -106,12 +117,17 @@ func (client *Client) DeleteFolder(cosPath string, options *DeleteOption) int {
 			rt := result.(*cos.BucketGetResult)
 			isTruncated = rt.IsTruncated
 			nextMarker = rt.NextMarker
+			listedNum += len(rt.Contents)
 			for _, file := range rt.Contents {
+				if options.Filter.ExcludedFile(relativeKey(file.Key, cosPath), objectAttr(file.Size, file.LastModified, file.StorageClass)) {
+					log.Debugf("Skip %s", file.Key)
+					continue
+				}
 				deleteList = append(deleteList, cos.Object{
 					Key: file.Key,
 				})
 			}
-			totalDeleteFileNum += len(rt.Contents)
+			totalDeleteFileNum += len(deleteList)
 		}
 		if len(deleteList) > 0 {
 			delResult, resp, err := client.Client.Object.DeleteMulti(context.Background(), &cos.ObjectDeleteMultiOptions{
@@ -140,10 +156,14 @@ func (client *Client) DeleteFolder(cosPath string, options *DeleteOption) int {
 			}
 		}
 	}
-	if totalDeleteFileNum == 0 {
+	if listedNum == 0 {
 		log.Infof("The directory does not exist")
 		return -1
 	}
+	if totalDeleteFileNum == 0 {
+		log.Infof("No file matches the filter")
+		return 0
+	}
 	if !versions {
 		log.Infof("%d files successful, %d files failed", haveDeletedNum, totalDeleteFileNum-haveDeletedNum)
 	}
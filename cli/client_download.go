@@ -18,20 +18,22 @@ package cli
 
 import (
 	"context"
+	"encoding/xml"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/huanght1997/cosutil/coshelper"
 
-	"github.com/danwakefield/fnmatch"
 	"github.com/mitchellh/go-homedir"
 	"github.com/schollz/progressbar/v3"
 	log "github.com/sirupsen/logrus"
@@ -40,18 +42,33 @@ import (
 
 type DownloadOption struct {
 	Force   bool
-	Yes	bool
+	Yes     bool
 	Sync    bool
 	Num     int
-	Ignore  []string
-	Include []string
+	Filter  *coshelper.Filter
 	SkipMd5 bool
 	Delete  bool
+	// Headers are sent with every HEAD and GET, like the key of SSE-C.
+	Headers *http.Header
+	// VersionID is the version to download instead of the latest one.
+	VersionID string
+	// AsOf downloads the version of each object which was the latest at the time, if not zero.
+	AsOf time.Time
+	// root is the local directory files are downloaded into, nothing is written outside it.
+	root string
 }
 
 type multiDownloadFile struct {
 	cosPath, localPath string
 	size               int64
+	versionID          string
+}
+
+// An object to download in a folder, the latest one if versionID is empty.
+type downloadObject struct {
+	key, lastModified, storageClass string
+	size                            int64
+	versionID                       string
 }
 
 const (
@@ -72,49 +89,84 @@ func (client *Client) DownloadFolder(cosPath string, localPath string, options *
 		localPath += "/"
 	}
 	cosPath = strings.TrimLeft(cosPath, "/")
+	rooted := *options
+	rooted.root = filepath.Clean(localPath)
+	options = &rooted
 	nextMarker := ""
 	isTruncated := true
 	successNum, failNum, skipNum := 0, 0, 0
+	// keys existing at options.AsOf, local files not in them are deleted by --sync --delete
+	var asOfKeys map[string]struct{}
 
 	for isTruncated {
 		downloading := make(chan int, client.Config.MaxThread)
 		downloadResult := make(chan int, client.Config.MaxThread)
 		multiDownloadFileList := make([]multiDownloadFile, 0)
-		result, _, err := client.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
-			Prefix:  cosPath,
-			Marker:  nextMarker,
-			MaxKeys: 1000,
-		})
-		if err != nil {
-			log.Warn(err.Error())
-			log.Warn("List object failed")
-			return -1
+		var files []downloadObject
+		if options.AsOf.IsZero() {
+			result, _, err := client.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+				Prefix:  cosPath,
+				Marker:  nextMarker,
+				MaxKeys: 1000,
+			})
+			if err != nil {
+				log.Warn(err.Error())
+				log.Warn("List object failed")
+				return -1
+			}
+			isTruncated = result.IsTruncated
+			nextMarker = result.NextMarker
+			for _, file := range result.Contents {
+				files = append(files, downloadObject{
+					key:          file.Key,
+					lastModified: file.LastModified,
+					storageClass: file.StorageClass,
+					size:         file.Size,
+				})
+			}
+		} else {
+			var err error
+			if files, err = client.listVersionsAsOf(cosPath, options.AsOf); err != nil {
+				log.Warn(err.Error())
+				log.Warn("List object versions failed")
+				return -1
+			}
+			isTruncated = false
+			asOfKeys = make(map[string]struct{}, len(files))
+			for _, file := range files {
+				asOfKeys[file.key] = struct{}{}
+			}
 		}
-		isTruncated = result.IsTruncated
-		nextMarker = result.NextMarker
 		tasks := 0
-		for _, file := range result.Contents {
-			fileCosPath := file.Key
-			fileSize := file.Size
+		for _, file := range files {
+			fileCosPath := file.key
+			fileSize := file.size
 			fileLocalPath := localPath + fileCosPath[len(cosPath):]
 			// if fileCosPath has suffix /, it is an empty folder, ignore it.
 			if strings.HasSuffix(fileCosPath, "/") {
 				continue
 			}
+			if options.Filter.ExcludedFile(relativeKey(fileCosPath, cosPath), objectAttr(file.size, file.lastModified, file.storageClass)) {
+				log.Debugf("Skip cos://%s/%s => %s",
+					client.Config.Bucket, fileCosPath, fileLocalPath)
+				skipNum++
+				continue
+			}
 			if fileSize <= multiDownloadThreshold {
 				// small file, just download it now.
 				tasks++
-				go func(cosPath, localPath string) {
+				go func(cosPath, localPath, versionID string) {
 					downloading <- 1
-					downloadResult <- client.singleDownload(cosPath, localPath, options)
+					downloadResult <- client.singleDownload(cosPath, localPath, options.withVersion(versionID))
 					<-downloading
-				}(fileCosPath, fileLocalPath)
+				}(fileCosPath, fileLocalPath, file.versionID)
 			} else {
 				// large file, download later.
 				multiDownloadFileList = append(multiDownloadFileList, multiDownloadFile{
 					cosPath:   fileCosPath,
 					localPath: fileLocalPath,
-					size:      int64(fileSize),
+					size:      fileSize,
+					versionID: file.versionID,
 				})
 			}
 		}
@@ -132,7 +184,7 @@ func (client *Client) DownloadFolder(cosPath string, localPath string, options *
 		}
 		// Download large file one by one.
 		for _, f := range multiDownloadFileList {
-			ret := client.multipartDownload(f.cosPath, f.localPath, f.size, options)
+			ret := client.multipartDownload(f.cosPath, f.localPath, f.size, options.withVersion(f.versionID))
 			switch ret {
 			case 0:
 				successNum++
@@ -153,7 +205,7 @@ func (client *Client) DownloadFolder(cosPath string, localPath string, options *
 			}
 		}
 		log.Info("Synchronizing delete, please wait.")
-		ret, delSucc, delFail := client.remoteToLocalSyncDelete(localPath, cosPath)
+		ret, delSucc, delFail := client.remoteToLocalSyncDelete(localPath, cosPath, asOfKeys, options)
 		if ret != 0 {
 			log.Warn("sync delete fail")
 		} else {
@@ -167,8 +219,15 @@ func (client *Client) DownloadFolder(cosPath string, localPath string, options *
 	return 0
 }
 
-func (client *Client) DownloadFile(cosPath string, localPath string, _ *http.Header, options *DownloadOption) int {
-	resp, err := client.Client.Object.Head(context.Background(), cosPath, nil)
+func (client *Client) DownloadFile(cosPath string, localPath string, headers *http.Header, options *DownloadOption) int {
+	if headers != nil && len(*headers) > 0 {
+		withHeaders := *options
+		withHeaders.Headers = headers
+		options = &withHeaders
+	}
+	resp, err := client.Client.Object.Head(context.Background(), cosPath, &cos.ObjectHeadOptions{
+		XOptionHeader: options.Headers,
+	}, options.versionIDs()...)
 	if err != nil {
 		log.Warn(err.Error())
 		return -1
@@ -177,11 +236,21 @@ func (client *Client) DownloadFile(cosPath string, localPath string, _ *http.Hea
 		log.Warnf("Object HEAD Response Code: %d", resp.StatusCode)
 		return -1
 	}
+	if options.Filter.ExcludedFile(strings.TrimLeft(cosPath, "/"), headerAttr(resp.Header)) {
+		log.Debugf("Skip cos://%s/%s => %s",
+			client.Config.Bucket, cosPath, localPath)
+		return -2
+	}
 	absLocalPath, err := homedir.Expand(localPath)
 	if err != nil {
 		log.Warn(err.Error())
 		return -1
 	}
+	if options.root == "" {
+		rooted := *options
+		rooted.root = filepath.Dir(filepath.Clean(absLocalPath))
+		options = &rooted
+	}
 	fileSize, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
 	if fileSize <= multiDownloadThreshold || options.Num == 1 {
 		return client.singleDownload(cosPath, absLocalPath, options)
@@ -194,13 +263,19 @@ func (client *Client) singleDownload(cosPath string, localPath string, options *
 	for strings.HasPrefix(cosPath, "/") {
 		cosPath = cosPath[1:]
 	}
+	if err := checkLocalPath(options.root, localPath); err != nil {
+		log.Warnf("Refuse to download cos://%s/%s: %s", client.Config.Bucket, cosPath, err.Error())
+		return -1
+	}
 	ret := client.remoteToLocalSyncCheck(cosPath, localPath, options)
 	if ret != 0 {
 		return ret
 	}
-	log.Infof("Download cos://%s/%s   =>   %s",
-		client.Config.Bucket, cosPath, localPath)
-	resp, err := client.Client.Object.Get(context.Background(), cosPath, nil)
+	log.Infof("Download %s   =>   %s",
+		client.downloadSource(cosPath, options), localPath)
+	resp, err := client.Client.Object.Get(context.Background(), cosPath, &cos.ObjectGetOptions{
+		XOptionHeader: options.Headers,
+	}, options.versionIDs()...)
 	if err != nil {
 		log.Warn(err.Error())
 		return -1
@@ -213,6 +288,25 @@ func (client *Client) singleDownload(cosPath string, localPath string, options *
 			log.Warnf("Cannot create directory '%s'", dirPath)
 		}
 	}
+	if escapedTarget := resp.Header.Get(SymlinkTargetHeader); escapedTarget != "" {
+		_ = resp.Body.Close()
+		return createSymlink(escapedTarget, localPath, options)
+	}
+	// objects uploaded with --compress are decompressed
+	body, err := newDecompressReader(resp.Body, resp.Header)
+	if err != nil {
+		log.Warn(err.Error())
+		_ = resp.Body.Close()
+		return -1
+	}
+	defer func() {
+		_ = body.Close()
+		_ = resp.Body.Close()
+	}()
+	if err := removeSymlink(localPath); err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
 	f, err := os.Create(localPath)
 	if err != nil {
 		return -1
@@ -225,7 +319,7 @@ func (client *Client) singleDownload(cosPath string, localPath string, options *
 	// make a buffer to keep chunks (1M)
 	buf := make([]byte, 1024*1024)
 	for {
-		n, err := resp.Body.Read(buf)
+		n, err := body.Read(buf)
 		// if there is an error and not EOF, something wrong.
 		if err != nil && err != io.EOF {
 			return -1
@@ -243,14 +337,60 @@ func (client *Client) singleDownload(cosPath string, localPath string, options *
 	return 0
 }
 
+// Recreate the symbolic link stored by upload.
+func createSymlink(escapedTarget string, localPath string, options *DownloadOption) int {
+	target, err := url.PathUnescape(escapedTarget)
+	if err != nil {
+		log.Warnf("Invalid symbolic link target '%s'", escapedTarget)
+		return -1
+	}
+	if err := checkLinkTarget(options.root, localPath, target); err != nil {
+		log.Warnf("Refuse to create symbolic link %s: %s", localPath, err.Error())
+		return -1
+	}
+	if coshelper.IsSymlink(localPath) {
+		if oldTarget, err := os.Readlink(localPath); err == nil && oldTarget == target {
+			log.Debugf("Skip %s -> %s", localPath, target)
+			return -2
+		}
+	}
+	if _, err := os.Lstat(localPath); err == nil {
+		if !options.Force && !options.Sync {
+			log.Warnf("The file %s already exists, please use -f to overwrite the file",
+				localPath)
+			return -1
+		}
+		if err := os.Remove(localPath); err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+	}
+	if err := os.Symlink(target, localPath); err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	return 0
+}
+
 func (client *Client) multipartDownload(cosPath string, localPath string, fileSize int64, options *DownloadOption) int {
 	cosPath = strings.TrimLeft(cosPath, "/")
+	// compressed objects can not be decompressed in parts
+	resp, err := client.Client.Object.Head(context.Background(), cosPath, &cos.ObjectHeadOptions{
+		XOptionHeader: options.Headers,
+	}, options.versionIDs()...)
+	if err == nil && isCompressed(resp.Header) {
+		return client.singleDownload(cosPath, localPath, options)
+	}
+	if err := checkLocalPath(options.root, localPath); err != nil {
+		log.Warnf("Refuse to download cos://%s/%s: %s", client.Config.Bucket, cosPath, err.Error())
+		return -1
+	}
 	ret := client.remoteToLocalSyncCheck(cosPath, localPath, options)
 	if ret != 0 {
 		return ret
 	}
-	log.Infof("Download cos://%s/%s   =>   %s",
-		client.Config.Bucket, cosPath, localPath)
+	log.Infof("Download %s   =>   %s",
+		client.downloadSource(cosPath, options), localPath)
 	var offset int64 = 0
 	partsNum := options.Num
 	chuckSize := fileSize / int64(partsNum)
@@ -279,6 +419,10 @@ func (client *Client) multipartDownload(cosPath string, localPath string, fileSi
 	}
 	// Create an empty file
 	// the file must have been created when use f.Seek()
+	if err := removeSymlink(localPath); err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
 	f, err := os.Create(localPath)
 	if err != nil {
 		log.Warn(err.Error())
@@ -311,13 +455,13 @@ func (client *Client) multipartDownload(cosPath string, localPath string, fileSi
 		if i+1 == partsNum {
 			go func(offset, length int64, index int) {
 				downloading <- struct{}{}
-				downloadResult <- client.getPartsData(localPath, cosPath, offset, length)
+				downloadResult <- client.getPartsData(localPath, cosPath, offset, length, options)
 				<-downloading
 			}(offset, fileSize-offset, i+1)
 		} else {
 			go func(offset, length int64, index int) {
 				downloading <- struct{}{}
-				downloadResult <- client.getPartsData(localPath, cosPath, offset, length)
+				downloadResult <- client.getPartsData(localPath, cosPath, offset, length, options)
 				<-downloading
 			}(offset, chuckSize, i+1)
 			offset += chuckSize
@@ -347,12 +491,20 @@ func (client *Client) multipartDownload(cosPath string, localPath string, fileSi
 	return 0
 }
 
-func (client *Client) getPartsData(localPath string, cosPath string, offset int64, length int64) int {
+func (client *Client) getPartsData(localPath string, cosPath string, offset int64, length int64, options *DownloadOption) int {
 	for j := 0; j <= client.Config.RetryTimes; j++ {
 		resp, err := client.Client.Object.Get(context.Background(), cosPath, &cos.ObjectGetOptions{
 			Range: fmt.Sprintf("bytes=%d-%d",
 				offset, offset+length-1),
-		})
+			XOptionHeader: options.Headers,
+		}, options.versionIDs()...)
+		if err != nil {
+			log.Warn(err.Error())
+			if j < client.Config.RetryTimes {
+				time.Sleep((1 << j) * time.Second)
+			}
+			continue
+		}
 		f, err := os.OpenFile(localPath, os.O_RDWR, 0644)
 		if err != nil {
 			log.Warn(err.Error())
@@ -414,8 +566,11 @@ func (client *Client) getPartsData(localPath string, cosPath string, offset int6
 	return -1
 }
 
-// Delete objects in local but not in COS
-func (client *Client) remoteToLocalSyncDelete(localPath string, cosPath string) (ret, successNum, failNum int) {
+// Delete objects in local but not in COS. Files excluded by filter are kept.
+// If keys is not nil, files whose keys are not in it are deleted instead of checking COS.
+func (client *Client) remoteToLocalSyncDelete(localPath string, cosPath string, keys map[string]struct{}, options *DownloadOption) (ret, successNum, failNum int) {
+	filter := options.Filter
+	rootCosPath := cosPath
 	q := []PathPair{
 		{
 			LocalPath: localPath,
@@ -442,13 +597,30 @@ func (client *Client) remoteToLocalSyncDelete(localPath string, cosPath string)
 		}
 		for _, file := range files {
 			filePath := path.Join(localPath, file.Name())
+			relPath := strings.TrimPrefix(cosPath+file.Name(), rootCosPath)
+			if (file.IsDir() && filter.Excluded(relPath, true)) ||
+				(!file.IsDir() && filter.ExcludedFile(relPath, localAttr(file))) {
+				continue
+			}
 			if file.IsDir() {
 				q = append(q, PathPair{
 					LocalPath: filePath,
 					CosPath:   cosPath + file.Name(),
 				})
+			} else if keys != nil {
+				if _, ok := keys[cosPath+file.Name()]; !ok {
+					if err := os.Remove(filePath); err != nil {
+						log.Infof("Delete %s fail", filePath)
+						failNum++
+					} else {
+						log.Infof("Delete %s", filePath)
+						successNum++
+					}
+				}
 			} else {
-				resp, err := client.Client.Object.Head(context.Background(), cosPath+file.Name(), nil)
+				resp, err := client.Client.Object.Head(context.Background(), cosPath+file.Name(), &cos.ObjectHeadOptions{
+					XOptionHeader: options.Headers,
+				})
 				if resp != nil && resp.StatusCode == 404 {
 					err = os.Remove(filePath)
 					if err != nil {
@@ -468,37 +640,22 @@ func (client *Client) remoteToLocalSyncDelete(localPath string, cosPath string)
 	return 0, successNum, failNum
 }
 
+// Check whether the download should be processed. Filter rules are checked by callers.
+// Return 0 if it should be processed, -2 if skipped, -1 if the local file exists or something wrong.
 func (client *Client) remoteToLocalSyncCheck(cosPath string, localPath string, options *DownloadOption) int {
-	// check this path is in ignore or include list
-	isInclude, isIgnore := false, false
-	for _, rule := range options.Include {
-		if fnmatch.Match(rule, cosPath, 0) {
-			isInclude = true
-			break
-		}
-	}
-	for _, rule := range options.Ignore {
-		if fnmatch.Match(rule, cosPath, 0) {
-			isIgnore = true
-			break
-		}
-	}
-	if !isInclude || isIgnore {
-		log.Debugf("Skip cos://%s/%s => %s",
-			client.Config.Bucket, cosPath, localPath)
-		return -2
-	}
 	if !options.Force {
 		if coshelper.IsFile(localPath) {
 			if options.Sync {
-				resp, err := client.Client.Object.Head(context.Background(), cosPath, nil)
+				resp, err := client.Client.Object.Head(context.Background(), cosPath, &cos.ObjectHeadOptions{
+					XOptionHeader: options.Headers,
+				}, options.versionIDs()...)
 				if err != nil {
 					log.Warn(err.Error())
 					return -1
 				}
 				md5 := resp.Header.Get("x-cos-meta-md5")
 				localMd5 := coshelper.GetFileMd5(localPath)
-				size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+				size := uncompressedSize(resp.Header)
 				localSize, _ := coshelper.GetFileSize(localPath)
 				if (options.SkipMd5 || md5 == localMd5) && size == localSize {
 					log.Debugf("Skip cos://%s/%s => %s",
@@ -514,3 +671,204 @@ func (client *Client) remoteToLocalSyncCheck(cosPath string, localPath string, o
 	}
 	return 0
 }
+
+// Check that localPath is in root and none of the directories between them is a symbolic link,
+// so that nothing is written outside root, like through a link created by a former download.
+// Nothing is checked if root is empty.
+func checkLocalPath(root string, localPath string) error {
+	if root == "" {
+		return nil
+	}
+	rel, err := filepath.Rel(root, filepath.Clean(localPath))
+	if err != nil || rel == "." || isParentRel(rel) {
+		return fmt.Errorf("%s is outside %s", localPath, root)
+	}
+	dir := root
+	for _, name := range strings.Split(filepath.Dir(rel), string(filepath.Separator)) {
+		if name == "." {
+			continue
+		}
+		dir = filepath.Join(dir, name)
+		if coshelper.IsSymlink(dir) {
+			return fmt.Errorf("%s is a symbolic link", dir)
+		}
+	}
+	return nil
+}
+
+// Check that the symbolic link at localPath pointing to target stays in root.
+// Absolute targets are refused. Nothing is checked if root is empty.
+func checkLinkTarget(root string, localPath string, target string) error {
+	if root == "" {
+		return nil
+	}
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("the target %s is absolute", target)
+	}
+	resolved := filepath.Join(filepath.Dir(localPath), filepath.FromSlash(target))
+	if rel, err := filepath.Rel(root, resolved); err != nil || isParentRel(rel) {
+		return fmt.Errorf("the target %s is outside %s", target, root)
+	}
+	return nil
+}
+
+// Remove the symbolic link at localPath if any, so that the file is written in place of the link
+// instead of its target.
+func removeSymlink(localPath string) error {
+	if coshelper.IsSymlink(localPath) {
+		return os.Remove(localPath)
+	}
+	return nil
+}
+
+// Whether the relative path rel returned by filepath.Rel goes up out of the base.
+func isParentRel(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// The version id argument of HEAD and GET, empty for the latest version.
+func (options *DownloadOption) versionIDs() []string {
+	if options.VersionID == "" {
+		return nil
+	}
+	return []string{options.VersionID}
+}
+
+// Copy options to download the version, options itself is returned if versionID is empty.
+func (options *DownloadOption) withVersion(versionID string) *DownloadOption {
+	if versionID == "" {
+		return options
+	}
+	withVersion := *options
+	withVersion.VersionID = versionID
+	return &withVersion
+}
+
+func (client *Client) downloadSource(cosPath string, options *DownloadOption) string {
+	if options.VersionID == "" {
+		return fmt.Sprintf("cos://%s/%s", client.Config.Bucket, cosPath)
+	}
+	return fmt.Sprintf("cos://%s/%s?versionId=%s", client.Config.Bucket, cosPath, options.VersionID)
+}
+
+// An object version or a delete marker in the listing of versions.
+type versionEntry struct {
+	Key          string `xml:"Key"`
+	VersionID    string `xml:"VersionId"`
+	LastModified string `xml:"LastModified"`
+	StorageClass string `xml:"StorageClass"`
+	Size         int64  `xml:"Size"`
+	deleteMarker bool
+}
+
+// The result of listing versions. The SDK puts versions and delete markers in separate lists,
+// losing their order, which decides between the versions of a key modified in the same second.
+type versionListResult struct {
+	IsTruncated         bool
+	NextKeyMarker       string
+	NextVersionIDMarker string
+	Entries             []versionEntry
+}
+
+func (result *versionListResult) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			var err error
+			switch t.Name.Local {
+			case "IsTruncated":
+				err = d.DecodeElement(&result.IsTruncated, &t)
+			case "NextKeyMarker":
+				err = d.DecodeElement(&result.NextKeyMarker, &t)
+			case "NextVersionIdMarker":
+				err = d.DecodeElement(&result.NextVersionIDMarker, &t)
+			case "Version", "DeleteMarker":
+				entry := versionEntry{deleteMarker: t.Name.Local == "DeleteMarker"}
+				err = d.DecodeElement(&entry, &t)
+				result.Entries = append(result.Entries, entry)
+			default:
+				err = d.Skip()
+			}
+			if err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// List the version of each object with prefix cosPath which was the latest at asOf.
+// Objects created after asOf, or deleted at asOf, are not listed.
+// Versions of a key are listed from the newest, so the first one at or before asOf is taken.
+func (client *Client) listVersionsAsOf(cosPath string, asOf time.Time) ([]downloadObject, error) {
+	type candidate struct {
+		object       downloadObject
+		modTime      time.Time
+		deleteMarker bool
+	}
+	latest := make(map[string]candidate)
+	keyMarker, versionIDMarker := "", ""
+	isTruncated := true
+	for isTruncated {
+		query := url.Values{}
+		query.Set("prefix", cosPath)
+		query.Set("key-marker", keyMarker)
+		query.Set("version-id-marker", versionIDMarker)
+		query.Set("max-keys", "1000")
+		var result versionListResult
+		for i := 0; i <= client.Config.RetryTimes; i++ {
+			result = versionListResult{}
+			err := client.sendBucketRequest(http.MethodGet, "/?versions&"+query.Encode(), nil, &result)
+			if err == nil {
+				break
+			}
+			if i >= client.Config.RetryTimes {
+				return nil, err
+			}
+			log.Warn(err.Error())
+			time.Sleep((1 << i) * time.Second)
+		}
+		isTruncated = result.IsTruncated
+		keyMarker = result.NextKeyMarker
+		versionIDMarker = result.NextVersionIDMarker
+		for _, entry := range result.Entries {
+			modTime, err := time.Parse(time.RFC3339, entry.LastModified)
+			if err != nil {
+				return nil, fmt.Errorf("invalid last modified time '%s' of %s", entry.LastModified, entry.Key)
+			}
+			if modTime.After(asOf) {
+				continue
+			}
+			// An older version, or one modified in the same second but listed after the taken one.
+			if old, ok := latest[entry.Key]; ok && !modTime.After(old.modTime) {
+				continue
+			}
+			c := candidate{
+				object:       downloadObject{key: entry.Key, versionID: entry.VersionID},
+				modTime:      modTime,
+				deleteMarker: entry.deleteMarker,
+			}
+			if !entry.deleteMarker {
+				c.object.lastModified = entry.LastModified
+				c.object.storageClass = entry.StorageClass
+				c.object.size = entry.Size
+			}
+			latest[entry.Key] = c
+		}
+	}
+	objects := make([]downloadObject, 0, len(latest))
+	for _, c := range latest {
+		if !c.deleteMarker {
+			objects = append(objects, c.object)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].key < objects[j].key
+	})
+	return objects, nil
+}
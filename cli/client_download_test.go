@@ -0,0 +1,94 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+func TestListVersionsAsOf(t *testing.T) {
+	pages := map[string]string{
+		"": `<ListVersionsResult>
+	<IsTruncated>true</IsTruncated>
+	<NextKeyMarker>b.txt</NextKeyMarker>
+	<NextVersionIdMarker>null</NextVersionIdMarker>
+	<DeleteMarker><Key>a.txt</Key><VersionId>a3</VersionId><IsLatest>true</IsLatest><LastModified>2020-01-03T00:00:00.000Z</LastModified></DeleteMarker>
+	<Version><Key>a.txt</Key><VersionId>a2</VersionId><LastModified>2020-01-02T00:00:00.000Z</LastModified><Size>2</Size><StorageClass>STANDARD</StorageClass></Version>
+	<Version><Key>a.txt</Key><VersionId>a1</VersionId><LastModified>2020-01-01T00:00:00.000Z</LastModified><Size>1</Size><StorageClass>STANDARD</StorageClass></Version>
+	<DeleteMarker><Key>b.txt</Key><VersionId>b3</VersionId><LastModified>2020-01-02T00:00:00.000Z</LastModified></DeleteMarker>
+	<Version><Key>b.txt</Key><VersionId>null</VersionId><LastModified>2020-01-02T00:00:00.000Z</LastModified><Size>2</Size></Version>
+</ListVersionsResult>`,
+		"b.txt": `<ListVersionsResult>
+	<IsTruncated>false</IsTruncated>
+	<Version><Key>b.txt</Key><VersionId>b1</VersionId><LastModified>2020-01-01T00:00:00.000Z</LastModified><Size>1</Size></Version>
+	<Version><Key>c.txt</Key><VersionId>c2</VersionId><LastModified>2020-01-02T00:00:00.000Z</LastModified><Size>2</Size><StorageClass>ARCHIVE</StorageClass></Version>
+	<DeleteMarker><Key>c.txt</Key><VersionId>c1</VersionId><LastModified>2020-01-02T00:00:00.000Z</LastModified></DeleteMarker>
+	<Version><Key>d.txt</Key><VersionId>d1</VersionId><LastModified>2020-01-04T00:00:00.000Z</LastModified><Size>1</Size></Version>
+</ListVersionsResult>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if _, ok := query["versions"]; !ok || query.Get("prefix") != "" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		page, ok := pages[query.Get("key-marker")]
+		// The marker of a version put before versioning is enabled is null, which is passed as it is.
+		if !ok || (query.Get("key-marker") != "") != (query.Get("version-id-marker") == "null") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	client := &Client{
+		Client:     cos.NewClient(&cos.BaseURL{BucketURL: u}, &http.Client{}),
+		Config:     &ClientConfig{},
+		httpClient: &http.Client{},
+	}
+
+	tests := []struct {
+		asOf     time.Time
+		versions map[string]string
+	}{
+		// b.txt is put and deleted in the same second, c.txt is deleted and put again.
+		{time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC), map[string]string{"c.txt": "c2", "d.txt": "d1"}},
+		{time.Date(2020, 1, 2, 12, 0, 0, 0, time.UTC), map[string]string{"a.txt": "a2", "c.txt": "c2"}},
+		{time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC), map[string]string{"a.txt": "a1", "b.txt": "b1"}},
+		{time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), map[string]string{}},
+	}
+	for _, test := range tests {
+		objects, err := client.listVersionsAsOf("", test.asOf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions := make(map[string]string)
+		for _, object := range objects {
+			versions[object.key] = object.versionID
+		}
+		if fmt.Sprint(versions) != fmt.Sprint(test.versions) {
+			t.Errorf("versions as of %v = %v, want %v", test.asOf, versions, test.versions)
+		}
+	}
+}
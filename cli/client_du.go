@@ -0,0 +1,317 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+type DuOption struct {
+	// Depth of the sub-prefixes summarized, 0 for only the total.
+	Depth int
+	// Versions counts noncurrent versions, Uploads counts the parts of incomplete multipart uploads.
+	Versions bool
+	Uploads  bool
+	Human    bool
+	JSON     bool
+}
+
+// Objects are counted as they are listed, only the summaries are kept.
+type duReport struct {
+	Prefix         string       `json:"prefix"`
+	Total          *duSummary   `json:"total"`
+	Prefixes       []*duSummary `json:"prefixes,omitempty"`
+	StorageClasses []*duSummary `json:"storageClasses"`
+	base           string       // the "directory" of Prefix, sub-prefixes start from it
+	depth          int
+	prefixes       map[string]*duSummary
+	classes        map[string]*duSummary
+}
+
+type duSummary struct {
+	Prefix             string `json:"prefix,omitempty"`
+	StorageClass       string `json:"storageClass,omitempty"`
+	Objects            int64  `json:"objects"`
+	Size               int64  `json:"size"`
+	NoncurrentVersions int64  `json:"noncurrentVersions,omitempty"`
+	NoncurrentSize     int64  `json:"noncurrentSize,omitempty"`
+	Uploads            int64  `json:"uploads,omitempty"`
+	UploadsSize        int64  `json:"uploadsSize,omitempty"`
+}
+
+type duKind int
+
+const (
+	duCurrent duKind = iota
+	duNoncurrent
+	duUpload
+)
+
+// Du summarizes the number and size of objects under cosPath, by sub-prefixes and storage classes.
+func (client *Client) Du(cosPath string, options *DuOption) bool {
+	report := &duReport{
+		Prefix:   cosPath,
+		Total:    &duSummary{Prefix: cosPath},
+		base:     cosPath[:strings.LastIndex(cosPath, "/")+1],
+		depth:    options.Depth,
+		prefixes: make(map[string]*duSummary),
+		classes:  make(map[string]*duSummary),
+	}
+	var err error
+	if options.Versions {
+		err = client.duVersions(cosPath, report)
+	} else {
+		err = client.duObjects(cosPath, report)
+	}
+	if err == nil && options.Uploads {
+		err = client.duUploads(cosPath, report)
+	}
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	report.summarize()
+	if options.JSON {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		report.print(options)
+	}
+	return true
+}
+
+func (client *Client) duObjects(cosPath string, report *duReport) error {
+	marker := ""
+	for {
+		result, _, err := client.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+			Prefix:  cosPath,
+			Marker:  marker,
+			MaxKeys: 1000,
+		})
+		if err != nil {
+			return err
+		}
+		for _, object := range result.Contents {
+			report.add(object.Key, object.StorageClass, duCurrent, object.Size)
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+func (client *Client) duVersions(cosPath string, report *duReport) error {
+	keyMarker, versionIDMarker := "", ""
+	for {
+		result, _, err := client.Client.Bucket.GetObjectVersions(context.Background(), &cos.BucketGetObjectVersionsOptions{
+			Prefix:          cosPath,
+			KeyMarker:       keyMarker,
+			VersionIdMarker: versionIDMarker,
+			MaxKeys:         1000,
+		})
+		if err != nil {
+			return err
+		}
+		// Delete markers have no size, they are not counted.
+		for _, version := range result.Version {
+			kind := duCurrent
+			if !version.IsLatest {
+				kind = duNoncurrent
+			}
+			report.add(version.Key, version.StorageClass, kind, int64(version.Size))
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+		keyMarker = result.NextKeyMarker
+		versionIDMarker = result.NextVersionIdMarker
+	}
+}
+
+// Count every incomplete multipart upload with the size of its uploaded parts.
+func (client *Client) duUploads(cosPath string, report *duReport) error {
+	keyMarker, uploadIDMarker := "", ""
+	for {
+		result, _, err := client.Client.Bucket.ListMultipartUploads(context.Background(), &cos.ListMultipartUploadsOptions{
+			Prefix:         cosPath,
+			MaxUploads:     1000,
+			KeyMarker:      keyMarker,
+			UploadIDMarker: uploadIDMarker,
+		})
+		if err != nil {
+			return err
+		}
+		for _, upload := range result.Uploads {
+			var size int64
+			partNumberMarker := ""
+			for {
+				parts, _, err := client.Client.Object.ListParts(context.Background(), upload.Key, upload.UploadID, &cos.ObjectListPartsOptions{
+					MaxParts:         "1000",
+					PartNumberMarker: partNumberMarker,
+				})
+				if err != nil {
+					return err
+				}
+				for _, part := range parts.Parts {
+					size += part.Size
+				}
+				if !parts.IsTruncated {
+					break
+				}
+				partNumberMarker = parts.NextPartNumberMarker
+			}
+			report.add(upload.Key, upload.StorageClass, duUpload, size)
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+		keyMarker = result.NextKeyMarker
+		uploadIDMarker = result.NextUploadIDMarker
+	}
+}
+
+// The sub-prefix of key with at most depth levels under the base, like a/b/ for a/b/c/d.txt with depth 2.
+func duPrefix(key string, base string, depth int) string {
+	rel := key[len(base):]
+	end := 0
+	for i := 0; i < depth; i++ {
+		next := strings.Index(rel[end:], "/")
+		if next < 0 {
+			break
+		}
+		end += next + 1
+	}
+	return base + rel[:end]
+}
+
+func (report *duReport) add(key string, storageClass string, kind duKind, size int64) {
+	summaries := []*duSummary{report.Total}
+	if report.depth > 0 {
+		prefix := duPrefix(key, report.base, report.depth)
+		summary, ok := report.prefixes[prefix]
+		if !ok {
+			summary = &duSummary{Prefix: prefix}
+			report.prefixes[prefix] = summary
+		}
+		summaries = append(summaries, summary)
+	}
+	if storageClass == "" {
+		storageClass = "STANDARD"
+	}
+	class, ok := report.classes[storageClass]
+	if !ok {
+		class = &duSummary{StorageClass: storageClass}
+		report.classes[storageClass] = class
+	}
+	summaries = append(summaries, class)
+	for _, summary := range summaries {
+		switch kind {
+		case duCurrent:
+			summary.Objects++
+			summary.Size += size
+		case duNoncurrent:
+			summary.NoncurrentVersions++
+			summary.NoncurrentSize += size
+		case duUpload:
+			summary.Uploads++
+			summary.UploadsSize += size
+		}
+	}
+}
+
+func (report *duReport) summarize() {
+	for _, summary := range report.prefixes {
+		report.Prefixes = append(report.Prefixes, summary)
+	}
+	sort.Slice(report.Prefixes, func(i, j int) bool {
+		return report.Prefixes[i].Prefix < report.Prefixes[j].Prefix
+	})
+	report.StorageClasses = make([]*duSummary, 0, len(report.classes))
+	for _, summary := range report.classes {
+		report.StorageClasses = append(report.StorageClasses, summary)
+	}
+	sort.Slice(report.StorageClasses, func(i, j int) bool {
+		return report.StorageClasses[i].StorageClass < report.StorageClasses[j].StorageClass
+	})
+}
+
+func (report *duReport) print(options *DuOption) {
+	row := func(name string, summary *duSummary) table.Row {
+		r := table.Row{name, summary.Objects, coshelper.Humanize(summary.Size, options.Human)}
+		if options.Versions {
+			r = append(r, summary.NoncurrentVersions, coshelper.Humanize(summary.NoncurrentSize, options.Human))
+		}
+		if options.Uploads {
+			r = append(r, summary.Uploads, coshelper.Humanize(summary.UploadsSize, options.Human))
+		}
+		return r
+	}
+	header := func(name string) table.Row {
+		h := table.Row{name, "Objects", "Size"}
+		if options.Versions {
+			h = append(h, "Noncurrent", "Noncurrent Size")
+		}
+		if options.Uploads {
+			h = append(h, "Uploads", "Uploads Size")
+		}
+		return h
+	}
+	columns := make([]table.ColumnConfig, 0)
+	for i := 2; i <= len(header("")); i++ {
+		columns = append(columns, table.ColumnConfig{Number: i, Align: text.AlignRight, AlignFooter: text.AlignRight})
+	}
+	if report.depth > 0 {
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.Style().Options.DrawBorder = false
+		t.Style().Options.SeparateColumns = false
+		t.SetColumnConfigs(columns)
+		t.AppendHeader(header("Prefix"))
+		for _, summary := range report.Prefixes {
+			name := summary.Prefix
+			if name == "" {
+				name = "/"
+			}
+			t.AppendRow(row(name, summary))
+		}
+		t.Render()
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.Style().Options.DrawBorder = false
+	t.Style().Options.SeparateColumns = false
+	t.SetColumnConfigs(columns)
+	t.AppendHeader(header("Storage Class"))
+	for _, summary := range report.StorageClasses {
+		t.AppendRow(row(summary.StorageClass, summary))
+	}
+	t.AppendFooter(row("Total", report.Total))
+	t.Render()
+}
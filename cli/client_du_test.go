@@ -0,0 +1,79 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import "testing"
+
+func TestDuPrefix(t *testing.T) {
+	tests := []struct {
+		key, base string
+		depth     int
+		want      string
+	}{
+		{"a/b/c/d.txt", "", 1, "a/"},
+		{"a/b/c/d.txt", "", 2, "a/b/"},
+		{"a/b/c/d.txt", "", 3, "a/b/c/"},
+		{"a/b/c/d.txt", "", 4, "a/b/c/"},
+		{"a/b/c/d.txt", "a/", 1, "a/b/"},
+		{"a/b/c/d.txt", "a/", 2, "a/b/c/"},
+		{"a/d.txt", "a/", 1, "a/"},
+		{"d.txt", "", 2, ""},
+		{"a/b//c.txt", "a/", 2, "a/b//"},
+		{"a/b/", "a/", 1, "a/b/"},
+	}
+	for _, test := range tests {
+		if got := duPrefix(test.key, test.base, test.depth); got != test.want {
+			t.Errorf("duPrefix(%q, %q, %d) = %q, want %q", test.key, test.base, test.depth, got, test.want)
+		}
+	}
+}
+
+func TestDuReportAdd(t *testing.T) {
+	report := &duReport{
+		Total:    &duSummary{Prefix: "a/"},
+		base:     "a/",
+		depth:    1,
+		prefixes: make(map[string]*duSummary),
+		classes:  make(map[string]*duSummary),
+	}
+	report.add("a/x/1.txt", "", duCurrent, 10)
+	report.add("a/x/2.txt", "ARCHIVE", duNoncurrent, 20)
+	report.add("a/y/3.txt", "STANDARD", duUpload, 30)
+	report.add("a/4.txt", "STANDARD", duCurrent, 40)
+	report.summarize()
+
+	if *report.Total != (duSummary{Prefix: "a/", Objects: 2, Size: 50, NoncurrentVersions: 1, NoncurrentSize: 20,
+		Uploads: 1, UploadsSize: 30}) {
+		t.Errorf("total = %+v", *report.Total)
+	}
+	prefixes := make(map[string]duSummary)
+	for _, summary := range report.Prefixes {
+		prefixes[summary.Prefix] = *summary
+	}
+	if len(prefixes) != 3 || prefixes["a/x/"].Objects != 1 || prefixes["a/x/"].NoncurrentVersions != 1 ||
+		prefixes["a/y/"].Uploads != 1 || prefixes["a/"].Size != 40 {
+		t.Errorf("prefixes = %+v", prefixes)
+	}
+	classes := make(map[string]duSummary)
+	for _, summary := range report.StorageClasses {
+		classes[summary.StorageClass] = *summary
+	}
+	if len(classes) != 2 || classes["STANDARD"].Objects != 2 || classes["STANDARD"].UploadsSize != 30 ||
+		classes["ARCHIVE"].NoncurrentSize != 20 {
+		t.Errorf("storage classes = %+v", classes)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+type FindOption struct {
+	Filter *coshelper.Filter
+	// Delete the matched objects.
+	Delete bool
+	// Restore the matched objects with the option if not nil.
+	Restore *RestoreOption
+	// Presign prints the URLs of the matched objects valid for the duration if not zero.
+	Presign time.Duration
+	// Print0 prints the keys separated by NUL, the keys are printed line by line if no action is set.
+	Print0 bool
+	Yes    bool
+}
+
+// Find lists the objects under cosPath page by page, and runs the actions on the matched objects of each page.
+func (client *Client) Find(cosPath string, options *FindOption) int {
+	if options.Delete && !options.Yes {
+		if !coshelper.Confirm(fmt.Sprintf("WARN: you are deleting the matched files in the %s COS path, please make sure", cosPath), "no") {
+			return -3
+		}
+	}
+	printKeys := options.Print0 || (!options.Delete && options.Restore == nil && options.Presign == 0)
+	matchedNum, failNum := 0, 0
+	nextMarker := ""
+	isTruncated := true
+	for isTruncated {
+		var result *cos.BucketGetResult
+		for i := 0; i <= client.Config.RetryTimes; i++ {
+			var err error
+			result, _, err = client.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+				Prefix:  cosPath,
+				Marker:  nextMarker,
+				MaxKeys: 1000,
+			})
+			if err == nil {
+				break
+			}
+			log.Warn(err.Error())
+			if i == client.Config.RetryTimes {
+				return -1
+			}
+			time.Sleep((1 << i) * time.Second)
+		}
+		isTruncated = result.IsTruncated
+		nextMarker = result.NextMarker
+		matches := make([]string, 0)
+		for _, file := range result.Contents {
+			if strings.HasSuffix(file.Key, "/") ||
+				options.Filter.ExcludedFile(relativeKey(file.Key, cosPath), objectAttr(file.Size, file.LastModified, file.StorageClass)) {
+				continue
+			}
+			matches = append(matches, file.Key)
+		}
+		matchedNum += len(matches)
+		if printKeys {
+			for _, key := range matches {
+				if options.Print0 {
+					fmt.Print(key + "\x00")
+				} else {
+					fmt.Println(key)
+				}
+			}
+		}
+		if options.Presign != 0 {
+			for _, key := range matches {
+				url, err := client.Client.Object.GetPresignedURL(context.Background(),
+					http.MethodGet, key, client.Config.SecretID, client.Config.SecretKey, options.Presign, nil)
+				if err != nil {
+					log.Warn(err.Error())
+					failNum++
+					continue
+				}
+				fmt.Println(url)
+			}
+		}
+		if options.Restore != nil {
+			failNum += client.restoreObjects(matches, options.Restore)
+		}
+		if options.Delete {
+			_, fail := client.DeleteObjects(matches)
+			failNum += fail
+		}
+	}
+	log.Infof("%d files matched, %d actions failed", matchedNum, failNum)
+	if failNum != 0 {
+		return -1
+	}
+	return 0
+}
+
+// Restore the objects concurrently, returning the number of failures. Objects in progress are not failures.
+func (client *Client) restoreObjects(keys []string, options *RestoreOption) int {
+	restoring := make(chan struct{}, client.Config.MaxThread)
+	restoreResult := make(chan int, client.Config.MaxThread)
+	for _, key := range keys {
+		go func(path string) {
+			restoring <- struct{}{}
+			restoreResult <- client.RestoreFile(path, options)
+			<-restoring
+		}(key)
+	}
+	failNum := 0
+	for range keys {
+		if v := <-restoreResult; v != 0 && v != -2 {
+			failNum++
+		}
+	}
+	return failNum
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// GetBucketCORS prints the CORS rules of the bucket in a table, or in JSON which can be
+// used by PutBucketCORS again.
+func (client *Client) GetBucketCORS(jsonOutput bool) bool {
+	config, configured, err := client.getBucketCORS()
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	if !configured {
+		log.Info("Not configured")
+		return true
+	}
+	if jsonOutput {
+		data, _ := json.MarshalIndent(config, "", "  ")
+		fmt.Println(string(data))
+		return true
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"ID", "Origins", "Methods", "Allowed Headers", "Expose Headers", "Max Age"})
+	for _, rule := range config.Rules {
+		maxAge := ""
+		if rule.MaxAgeSeconds != 0 {
+			maxAge = strconv.Itoa(rule.MaxAgeSeconds) + "s"
+		}
+		t.AppendRow(table.Row{
+			rule.ID,
+			strings.Join(rule.AllowedOrigins, "\n"),
+			strings.Join(rule.AllowedMethods, ","),
+			strings.Join(rule.AllowedHeaders, "\n"),
+			strings.Join(rule.ExposeHeaders, "\n"),
+			maxAge,
+		})
+		t.AppendSeparator()
+	}
+	t.Render()
+	return true
+}
+
+// Get the CORS rules of the bucket, configured is false if there is no rule.
+func (client *Client) getBucketCORS() (config *CORSConfiguration, configured bool, err error) {
+	config = &CORSConfiguration{}
+	err = client.sendBucketRequest(http.MethodGet, "/?cors", nil, config)
+	if err != nil {
+		if errorResponse, ok := err.(*cos.ErrorResponse); ok && errorResponse.Code == "NoSuchCORSConfiguration" {
+			return config, false, nil
+		}
+		return nil, false, err
+	}
+	return config, len(config.Rules) != 0, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// GetBucketEncryption prints the default encryption of the bucket, or prints it in JSON.
+func (client *Client) GetBucketEncryption(jsonOutput bool) bool {
+	config, configured, err := client.getBucketEncryption()
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	if !configured {
+		log.Info("Not configured")
+		return true
+	}
+	if jsonOutput {
+		data, _ := json.MarshalIndent(config, "", "  ")
+		fmt.Println(string(data))
+		return true
+	}
+	log.Infof("Algorithm: %s", config.Algorithm)
+	if config.KMSMasterKeyID != "" {
+		log.Infof("KMS key: %s", config.KMSMasterKeyID)
+	}
+	return true
+}
+
+// Get the default encryption of the bucket, configured is false if there is none.
+func (client *Client) getBucketEncryption() (config *BucketEncryption, configured bool, err error) {
+	config = &BucketEncryption{}
+	if err := client.sendBucketRequest(http.MethodGet, "/?encryption", nil, config); err != nil {
+		if cos.IsNotFoundError(err) {
+			return &BucketEncryption{}, false, nil
+		}
+		return nil, false, err
+	}
+	if config.Algorithm == "" {
+		return &BucketEncryption{}, false, nil
+	}
+	return config, true, nil
+}
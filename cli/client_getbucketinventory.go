@@ -0,0 +1,127 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+type listInventoryResult struct {
+	XMLName                 xml.Name                 `xml:"ListInventoryConfigurationResult"`
+	InventoryConfigurations []InventoryConfiguration `xml:"InventoryConfiguration"`
+	IsTruncated             bool                     `xml:"IsTruncated"`
+	NextContinuationToken   string                   `xml:"NextContinuationToken"`
+}
+
+// GetBucketInventory prints the inventory with the ID, or all inventories of the bucket if id is empty,
+// in a table or in JSON. A single inventory in JSON can be used by PutBucketInventory again.
+func (client *Client) GetBucketInventory(id string, jsonOutput bool) bool {
+	var configs []InventoryConfiguration
+	if id == "" {
+		var err error
+		if configs, err = client.getBucketInventories(); err != nil {
+			log.Warn(err.Error())
+			return false
+		}
+		if len(configs) == 0 {
+			log.Info("Not configured")
+			return true
+		}
+	} else {
+		config := InventoryConfiguration{}
+		if err := client.sendBucketRequest(http.MethodGet, "/?inventory&id="+url.QueryEscape(id), nil, &config); err != nil {
+			if cos.IsNotFoundError(err) {
+				log.Warnf("Inventory %s does not exist", id)
+			} else {
+				log.Warn(err.Error())
+			}
+			return false
+		}
+		config.Destination.Encrypted = config.Destination.Encryption != nil
+		configs = append(configs, config)
+	}
+	if jsonOutput {
+		var data []byte
+		if id == "" {
+			data, _ = json.MarshalIndent(configs, "", "  ")
+		} else {
+			data, _ = json.MarshalIndent(configs[0], "", "  ")
+		}
+		fmt.Println(string(data))
+		return true
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"ID", "Enabled", "Versions", "Prefix", "Frequency", "Destination", "Fields"})
+	for _, config := range configs {
+		prefix := ""
+		if config.Filter != nil {
+			prefix = config.Filter.Prefix
+		}
+		t.AppendRow(table.Row{
+			config.ID,
+			config.IsEnabled,
+			config.IncludedObjectVersions,
+			prefix,
+			config.Schedule.Frequency,
+			config.Destination.Bucket + "/" + config.Destination.Prefix,
+			strings.Join(config.OptionalFields, ","),
+		})
+	}
+	t.Render()
+	return true
+}
+
+// Get all inventories of the bucket.
+func (client *Client) getBucketInventories() ([]InventoryConfiguration, error) {
+	configs := make([]InventoryConfiguration, 0)
+	token := ""
+	for {
+		uri := "/?inventory"
+		if token != "" {
+			uri += "&continuation-token=" + url.QueryEscape(token)
+		}
+		result := &listInventoryResult{}
+		if err := client.sendBucketRequest(http.MethodGet, uri, nil, result); err != nil {
+			if cos.IsNotFoundError(err) {
+				return configs, nil
+			}
+			return nil, err
+		}
+		for _, config := range result.InventoryConfigurations {
+			config.Destination.Encrypted = config.Destination.Encryption != nil
+			if config.Filter != nil && config.Filter.Prefix == "" {
+				config.Filter = nil
+			}
+			configs = append(configs, config)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return configs, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
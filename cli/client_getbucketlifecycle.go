@@ -0,0 +1,120 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// GetBucketLifecycle prints the lifecycle of the bucket in a table, or in JSON which can be
+// used by PutBucketLifecycle again.
+func (client *Client) GetBucketLifecycle(jsonOutput bool) bool {
+	config, configured, err := client.getBucketLifecycle()
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	if !configured {
+		log.Info("Not configured")
+		return true
+	}
+	if jsonOutput {
+		data, _ := json.MarshalIndent(config, "", "  ")
+		fmt.Println(string(data))
+		return true
+	}
+	printLifecycle(config)
+	return true
+}
+
+// Get the lifecycle of the bucket, configured is false if there is no rule.
+func (client *Client) getBucketLifecycle() (config *LifecycleConfiguration, configured bool, err error) {
+	config = &LifecycleConfiguration{}
+	err = client.sendBucketRequest(http.MethodGet, "/?lifecycle", nil, config)
+	if err != nil {
+		if errorResponse, ok := err.(*cos.ErrorResponse); ok && errorResponse.Code == "NoSuchLifecycleConfiguration" {
+			return config, false, nil
+		}
+		return nil, false, err
+	}
+	return config, len(config.Rules) != 0, nil
+}
+
+func printLifecycle(config *LifecycleConfiguration) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"ID", "Status", "Filter", "Actions"})
+	for _, rule := range config.Rules {
+		filters := make([]string, 0)
+		if rule.Filter.Prefix != "" {
+			filters = append(filters, "prefix: "+rule.Filter.Prefix)
+		}
+		for _, tag := range rule.Filter.Tags {
+			filters = append(filters, fmt.Sprintf("tag: %s=%s", tag.Key, tag.Value))
+		}
+		if len(filters) == 0 {
+			filters = append(filters, "all objects")
+		}
+		actions := make([]string, 0)
+		for _, transition := range rule.Transitions {
+			actions = append(actions, fmt.Sprintf("transit to %s %s", transition.StorageClass,
+				daysOrDate(transition.Days, transition.Date)))
+		}
+		if rule.Expiration != nil {
+			if rule.Expiration.ExpiredObjectDeleteMarker {
+				actions = append(actions, "remove expired delete markers")
+			} else {
+				actions = append(actions, "expire "+daysOrDate(rule.Expiration.Days, rule.Expiration.Date))
+			}
+		}
+		for _, transition := range rule.NoncurrentVersionTransitions {
+			actions = append(actions, fmt.Sprintf("transit noncurrent versions to %s after %d days",
+				transition.StorageClass, transition.NoncurrentDays))
+		}
+		if rule.NoncurrentVersionExpiration != nil {
+			actions = append(actions, fmt.Sprintf("expire noncurrent versions after %d days",
+				rule.NoncurrentVersionExpiration.NoncurrentDays))
+		}
+		if rule.AbortIncompleteMultipartUpload != nil {
+			actions = append(actions, fmt.Sprintf("abort incomplete multipart uploads after %d days",
+				rule.AbortIncompleteMultipartUpload.DaysAfterInitiation))
+		}
+		t.AppendRow(table.Row{
+			rule.ID,
+			rule.Status,
+			strings.Join(filters, "\n"),
+			strings.Join(actions, "\n"),
+		})
+		t.AppendSeparator()
+	}
+	t.Render()
+}
+
+func daysOrDate(days int, date string) string {
+	if date != "" {
+		return "on " + date
+	}
+	return fmt.Sprintf("after %d days", days)
+}
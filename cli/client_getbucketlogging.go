@@ -0,0 +1,60 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GetBucketLogging prints where the access logs of the bucket are written to, or prints it in JSON.
+func (client *Client) GetBucketLogging(jsonOutput bool) bool {
+	config, configured, err := client.getBucketLogging()
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	if !configured {
+		log.Info("Not configured")
+		return true
+	}
+	if jsonOutput {
+		data, _ := json.MarshalIndent(config, "", "  ")
+		fmt.Println(string(data))
+		return true
+	}
+	log.Infof("Target: cos://%s/%s", config.TargetBucket, config.TargetPrefix)
+	return true
+}
+
+// Get the logging of the bucket, configured is false if logging is disabled.
+func (client *Client) getBucketLogging() (config *BucketLogging, configured bool, err error) {
+	result, _, err := client.Client.Bucket.GetLogging(context.Background())
+	if err != nil {
+		return nil, false, err
+	}
+	if result.LoggingEnabled == nil || result.LoggingEnabled.TargetBucket == "" {
+		return &BucketLogging{}, false, nil
+	}
+	return &BucketLogging{
+		TargetBucket: result.LoggingEnabled.TargetBucket,
+		TargetPrefix: result.LoggingEnabled.TargetPrefix,
+	}, true, nil
+}
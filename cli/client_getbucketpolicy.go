@@ -0,0 +1,63 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// GetBucketPolicy prints the policy of the bucket in JSON.
+func (client *Client) GetBucketPolicy() bool {
+	policy, configured, err := client.getBucketPolicy()
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	if !configured {
+		log.Info("Not configured")
+		return true
+	}
+	data, _ := json.MarshalIndent(policy, "", "  ")
+	fmt.Println(string(data))
+	return true
+}
+
+// Get the policy of the bucket, configured is false if there is no policy.
+func (client *Client) getBucketPolicy() (policy *PolicyDocument, configured bool, err error) {
+	result, _, err := client.Client.Bucket.GetPolicy(context.Background())
+	if err != nil {
+		if cos.IsNotFoundError(err) {
+			return &PolicyDocument{}, false, nil
+		}
+		return nil, false, err
+	}
+	// Convert through JSON, so that the values of conditions become lists.
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, false, err
+	}
+	policy = &PolicyDocument{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, false, err
+	}
+	return policy, true, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// GetBucketReplication prints the replication of the bucket in a table, or in JSON which can be
+// used by PutBucketReplication again.
+func (client *Client) GetBucketReplication(jsonOutput bool) bool {
+	config, configured, err := client.getBucketReplication()
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	if !configured {
+		log.Info("Not configured")
+		return true
+	}
+	if jsonOutput {
+		data, _ := json.MarshalIndent(config, "", "  ")
+		fmt.Println(string(data))
+		return true
+	}
+	log.Infof("Role: %s", config.Role)
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"ID", "Status", "Prefix", "Destination", "Storage Class"})
+	for _, rule := range config.Rules {
+		t.AppendRow(table.Row{
+			rule.ID,
+			rule.Status,
+			rule.Prefix,
+			rule.Destination.Bucket,
+			rule.Destination.StorageClass,
+		})
+	}
+	t.Render()
+	return true
+}
+
+// Get the replication of the bucket, configured is false if there is no rule.
+func (client *Client) getBucketReplication() (config *ReplicationConfiguration, configured bool, err error) {
+	config = &ReplicationConfiguration{}
+	err = client.sendBucketRequest(http.MethodGet, "/?replication", nil, config)
+	if err != nil {
+		if cos.IsNotFoundError(err) {
+			return config, false, nil
+		}
+		return nil, false, err
+	}
+	return config, len(config.Rules) != 0, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// GetBucketWebsite prints the static website configuration of the bucket in a table, or in JSON
+// which can be used by PutBucketWebsite again.
+func (client *Client) GetBucketWebsite(jsonOutput bool) bool {
+	config, configured, err := client.getBucketWebsite()
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	if !configured {
+		log.Info("Not configured")
+		return true
+	}
+	if jsonOutput {
+		data, _ := json.MarshalIndent(config, "", "  ")
+		fmt.Println(string(data))
+		return true
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.Style().Options.DrawBorder = false
+	t.Style().Options.SeparateColumns = false
+	t.AppendRow(table.Row{"Index Document", config.IndexDocument})
+	if config.ErrorDocument != "" {
+		t.AppendRow(table.Row{"Error Document", config.ErrorDocument})
+	}
+	if config.RedirectAllRequestsTo != "" {
+		t.AppendRow(table.Row{"Redirect All Requests To", config.RedirectAllRequestsTo})
+	}
+	t.Render()
+	if len(config.RoutingRules) == 0 {
+		return true
+	}
+	t = table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Condition", "Redirect"})
+	for _, rule := range config.RoutingRules {
+		condition := "key prefix " + rule.Condition.KeyPrefix
+		if rule.Condition.HTTPErrorCode != 0 {
+			condition = "error " + strconv.Itoa(rule.Condition.HTTPErrorCode)
+		}
+		redirect := "key " + rule.Redirect.ReplaceKeyWith
+		if rule.Redirect.ReplaceKeyPrefixWith != "" {
+			redirect = "key prefix " + rule.Redirect.ReplaceKeyPrefixWith
+		}
+		if rule.Redirect.Protocol != "" {
+			redirect += " (" + rule.Redirect.Protocol + ")"
+		}
+		t.AppendRow(table.Row{condition, redirect})
+	}
+	t.Render()
+	return true
+}
+
+// Get the static website configuration of the bucket, configured is false if there is none.
+func (client *Client) getBucketWebsite() (config *WebsiteConfiguration, configured bool, err error) {
+	config = &WebsiteConfiguration{}
+	err = client.sendBucketRequest(http.MethodGet, "/?website", nil, config)
+	if err != nil {
+		if errorResponse, ok := err.(*cos.ErrorResponse); ok && errorResponse.Code == "NoSuchWebsiteConfiguration" {
+			return config, false, nil
+		}
+		return nil, false, err
+	}
+	return config, true, nil
+}
@@ -21,21 +21,35 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/huanght1997/cosutil/coshelper"
+
 	"github.com/jedib0t/go-pretty/v6/table"
 	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
 )
 
-func (client *Client) InfoObject(cosPath string, _ bool) bool {
-	resp, err := client.Client.Object.Head(context.Background(), cosPath, nil)
+// InfoObject prints the headers and tags of the object, sse has the key if it is encrypted with SSE-C.
+func (client *Client) InfoObject(cosPath string, _ bool, sse *coshelper.SSE) bool {
+	resp, err := client.Client.Object.Head(context.Background(), cosPath, &cos.ObjectHeadOptions{
+		XOptionHeader: sse.ReadHeaders(),
+	})
 	if err != nil {
 		log.Warn(err.Error())
 		return false
 	}
-	printInfo(&resp.Header, cosPath)
+	var tags []coshelper.Tag
+	// Tags are not in the headers, only the count is.
+	if resp.Header.Get("x-cos-tagging-count") != "" {
+		tags, err = client.objectTags(cosPath)
+		if err != nil {
+			log.Warn(err.Error())
+		}
+	}
+	printInfo(&resp.Header, cosPath, tags)
 	return true
 }
 
-func printInfo(header *http.Header, cosPath string) {
+func printInfo(header *http.Header, cosPath string, tags []coshelper.Tag) {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
 	t.Style().Options.DrawBorder = false
@@ -47,5 +61,8 @@ func printInfo(header *http.Header, cosPath string) {
 			t.AppendRow(table.Row{key, v})
 		}
 	}
+	for _, tag := range tags {
+		t.AppendRow(table.Row{"Tag", tag.Key + "=" + tag.Value})
+	}
 	t.Render()
 }
@@ -0,0 +1,285 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// Inventory reads the objects in an inventory report of a bucket page by page, in the way of Bucket.Get,
+// so that huge buckets are processed without listing them.
+type Inventory struct {
+	// SourceBucket is the bucket the report is about, like examplebucket-1250000000.
+	SourceBucket string
+	// client of the bucket where the reports are delivered to.
+	client *Client
+	// manifestDir is the directory of the manifest, empty if the manifest is on COS.
+	manifestDir string
+	// fields maps the names in the file schema to the columns.
+	fields   map[string]int
+	files    []string
+	nextFile int
+	file     io.Closer
+	reader   *csv.Reader
+	// prefixes are the common prefixes returned already.
+	prefixes map[string]struct{}
+	// err is kept once reading failed, since the report cannot be read again from where it failed.
+	err error
+}
+
+type inventoryManifest struct {
+	SourceBucket      string `json:"sourceBucket"`
+	DestinationBucket string `json:"destinationBucket"`
+	FileFormat        string `json:"fileFormat"`
+	FileSchema        string `json:"fileSchema"`
+	Files             []struct {
+		Key string `json:"key"`
+	} `json:"files"`
+}
+
+// OpenInventory reads the manifest.json of an inventory report, which is a local file, or a COS path like
+// bucket-appid.cos.ap-guangzhou.myqcloud.com/path/manifest.json. The CSV files of a local manifest are read
+// from the same directory or the sibling data directory if found there, otherwise from the inventory bucket.
+func (client *Client) OpenInventory(manifestPath string) (*Inventory, error) {
+	inventory := &Inventory{
+		fields:   make(map[string]int),
+		prefixes: make(map[string]struct{}),
+	}
+	var data []byte
+	var err error
+	if coshelper.IsFile(manifestPath) {
+		if data, err = ioutil.ReadFile(manifestPath); err != nil {
+			return nil, err
+		}
+		inventory.manifestDir = filepath.Dir(manifestPath)
+	} else {
+		if !strings.Contains(manifestPath, "/") {
+			return nil, fmt.Errorf("manifest '%s' is neither a file nor a COS path", manifestPath)
+		}
+		manifestClient, err := client.sourcePathToClient(manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := manifestClient.Client.Object.Get(context.Background(), manifestPath[strings.Index(manifestPath, "/")+1:], nil)
+		if err != nil {
+			return nil, err
+		}
+		data, err = ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	var manifest inventoryManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %s", err.Error())
+	}
+	if !strings.EqualFold(manifest.FileFormat, "CSV") {
+		return nil, fmt.Errorf("file format %s is not supported", manifest.FileFormat)
+	}
+	for i, name := range strings.Split(manifest.FileSchema, ",") {
+		inventory.fields[strings.TrimSpace(name)] = i
+	}
+	if _, ok := inventory.fields["Key"]; !ok {
+		return nil, fmt.Errorf("no Key in the file schema '%s'", manifest.FileSchema)
+	}
+	region, bucket, err := parseReplicationBucket(manifest.DestinationBucket)
+	if err != nil {
+		return nil, err
+	}
+	if inventory.client, err = client.sourcePathToClient(bucket + ".cos." + region + ".myqcloud.com/"); err != nil {
+		return nil, err
+	}
+	inventory.SourceBucket = manifest.SourceBucket
+	for _, file := range manifest.Files {
+		inventory.files = append(inventory.files, file.Key)
+	}
+	log.Infof("Read %d inventory files of %s", len(inventory.files), inventory.SourceBucket)
+	return inventory, nil
+}
+
+// Get returns the next page of at most opt.MaxKeys objects and common prefixes with opt.Prefix.
+// Unlike Bucket.Get, the objects are in the order of the report, and opt.Marker is ignored.
+func (inventory *Inventory) Get(opt *cos.BucketGetOptions) (*cos.BucketGetResult, error) {
+	if inventory.err != nil {
+		return nil, inventory.err
+	}
+	maxKeys := opt.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = 1000
+	}
+	result := &cos.BucketGetResult{
+		Name:      inventory.SourceBucket,
+		Prefix:    opt.Prefix,
+		Delimiter: opt.Delimiter,
+		MaxKeys:   maxKeys,
+	}
+	for len(result.Contents)+len(result.CommonPrefixes) < maxKeys {
+		object, err := inventory.next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			inventory.err = err
+			return nil, err
+		}
+		if object == nil || !strings.HasPrefix(object.Key, opt.Prefix) {
+			continue
+		}
+		if opt.Delimiter != "" {
+			if i := strings.Index(object.Key[len(opt.Prefix):], opt.Delimiter); i >= 0 {
+				commonPrefix := object.Key[:len(opt.Prefix)+i+len(opt.Delimiter)]
+				if _, ok := inventory.prefixes[commonPrefix]; !ok {
+					inventory.prefixes[commonPrefix] = struct{}{}
+					result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix)
+				}
+				continue
+			}
+		}
+		result.Contents = append(result.Contents, *object)
+		result.NextMarker = object.Key
+	}
+	result.IsTruncated = true
+	return result, nil
+}
+
+// Close closes the file being read.
+func (inventory *Inventory) Close() {
+	if inventory.file != nil {
+		_ = inventory.file.Close()
+		inventory.file = nil
+	}
+}
+
+// Read the next object in the report, nil for noncurrent versions and delete markers.
+func (inventory *Inventory) next() (*cos.Object, error) {
+	for {
+		if inventory.reader == nil {
+			if inventory.nextFile >= len(inventory.files) {
+				return nil, io.EOF
+			}
+			if err := inventory.openFile(inventory.files[inventory.nextFile]); err != nil {
+				return nil, err
+			}
+			inventory.nextFile++
+		}
+		record, err := inventory.reader.Read()
+		if err == io.EOF {
+			inventory.Close()
+			inventory.reader = nil
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s failed: %s", inventory.files[inventory.nextFile-1], err.Error())
+		}
+		return inventory.object(record), nil
+	}
+}
+
+func (inventory *Inventory) openFile(key string) error {
+	var file io.ReadCloser
+	if inventory.manifestDir != "" {
+		for _, localPath := range []string{
+			filepath.Join(inventory.manifestDir, path.Base(key)),
+			filepath.Join(inventory.manifestDir, "..", "data", path.Base(key)),
+		} {
+			if coshelper.IsFile(localPath) {
+				f, err := os.Open(localPath)
+				if err != nil {
+					return err
+				}
+				log.Debugf("Read inventory file %s", localPath)
+				file = f
+				break
+			}
+		}
+	}
+	if file == nil {
+		resp, err := inventory.client.Client.Object.Get(context.Background(), key, nil)
+		if err != nil {
+			return err
+		}
+		log.Debugf("Read inventory file cos://%s/%s", inventory.client.Config.Bucket, key)
+		file = resp.Body
+	}
+	var reader io.Reader = file
+	if strings.HasSuffix(key, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			_ = file.Close()
+			return fmt.Errorf("read %s failed: %s", key, err.Error())
+		}
+		reader = gzipReader
+	}
+	inventory.file = file
+	inventory.reader = csv.NewReader(reader)
+	inventory.reader.FieldsPerRecord = -1
+	return nil
+}
+
+// Convert a record of the report to an object, nil for noncurrent versions and delete markers.
+func (inventory *Inventory) object(record []string) *cos.Object {
+	field := func(name string) string {
+		if i, ok := inventory.fields[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	if strings.EqualFold(field("IsLatest"), "false") || strings.EqualFold(field("IsDeleteMarker"), "true") {
+		return nil
+	}
+	// Keys are URL encoded in the report.
+	key, err := url.QueryUnescape(field("Key"))
+	if err != nil {
+		key = field("Key")
+	}
+	size, _ := strconv.ParseInt(field("Size"), 10, 64)
+	return &cos.Object{
+		Key:          key,
+		ETag:         field("ETag"),
+		Size:         size,
+		LastModified: field("LastModifiedDate"),
+		StorageClass: field("StorageClass"),
+		VersionId:    field("VersionId"),
+	}
+}
+
+// Get a page of objects in the way of Bucket.Get, from the inventory report if inventory is not nil.
+func (client *Client) getObjects(inventory *Inventory, opt *cos.BucketGetOptions) (*cos.BucketGetResult, error) {
+	if inventory != nil {
+		return inventory.Get(opt)
+	}
+	result, _, err := client.Client.Bucket.Get(context.Background(), opt)
+	return result, err
+}
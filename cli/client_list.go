@@ -19,6 +19,7 @@ package cli
 import (
 	"context"
 	"os"
+	"sync"
 
 	"github.com/huanght1997/cosutil/coshelper"
 
@@ -34,6 +35,10 @@ type ListOption struct {
 	Num       int
 	Human     bool
 	Versions  bool
+	// Long shows the tags of objects, which takes a request for each object.
+	Long bool
+	// Inventory lists the objects instead of Bucket.Get if not nil.
+	Inventory *Inventory
 }
 
 type FileDesc struct {
@@ -43,6 +48,7 @@ type FileDesc struct {
 	Time      string
 	Class     string
 	VersionID string
+	Tags      string
 }
 
 func (client *Client) ListObjects(cosPath string, options *ListOption) bool {
@@ -75,7 +81,7 @@ func (client *Client) ListObjects(cosPath string, options *ListOption) bool {
 					MaxKeys:         1000,
 				})
 			} else {
-				res, _, err = client.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+				res, err = client.getObjects(options.Inventory, &cos.BucketGetOptions{
 					Prefix:    cosPath,
 					Delimiter: delimiter,
 					Marker:    keyMarker,
@@ -160,6 +166,9 @@ func (client *Client) ListObjects(cosPath string, options *ListOption) bool {
 				return false
 			}
 		}
+		if options.Long {
+			client.fillTags(filesInfo)
+		}
 		printFilesInfo(filesInfo, options)
 		if fileNum >= options.Num {
 			break
@@ -177,6 +186,36 @@ func (client *Client) ListObjects(cosPath string, options *ListOption) bool {
 	return true
 }
 
+// Get the tags of the files concurrently.
+func (client *Client) fillTags(filesInfo []FileDesc) {
+	running := make(chan struct{}, client.Config.MaxThread)
+	var wg sync.WaitGroup
+	for i := range filesInfo {
+		if filesInfo[i].Type != "File" {
+			continue
+		}
+		wg.Add(1)
+		go func(file *FileDesc) {
+			defer wg.Done()
+			running <- struct{}{}
+			defer func() {
+				<-running
+			}()
+			var versionID []string
+			if file.VersionID != "" {
+				versionID = append(versionID, file.VersionID)
+			}
+			tags, err := client.objectTags(file.Path, versionID...)
+			if err != nil {
+				log.Debugf("Get tags of %s failed: %s", file.Path, err.Error())
+				return
+			}
+			file.Tags = coshelper.FormatTags(tags)
+		}(&filesInfo[i])
+	}
+	wg.Wait()
+}
+
 func printFilesInfo(filesInfo []FileDesc, options *ListOption) {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
@@ -191,12 +230,16 @@ func printFilesInfo(filesInfo []FileDesc, options *ListOption) {
 	if options.Versions {
 		for _, row := range filesInfo {
 			if row.Type == "File" {
-				t.AppendRow(table.Row{
+				r := table.Row{
 					row.Path,
 					coshelper.Humanize(row.Size, options.Human),
 					row.Time,
 					row.VersionID,
-				})
+				}
+				if options.Long {
+					r = append(r, row.Tags)
+				}
+				t.AppendRow(r)
 			} else {
 				t.AppendRow(table.Row{
 					row.Path,
@@ -209,12 +252,16 @@ func printFilesInfo(filesInfo []FileDesc, options *ListOption) {
 	} else {
 		for _, row := range filesInfo {
 			if row.Type == "File" {
-				t.AppendRow(table.Row{
+				r := table.Row{
 					row.Path,
 					coshelper.Humanize(row.Size, options.Human),
 					row.Class,
 					row.Time,
-				})
+				}
+				if options.Long {
+					r = append(r, row.Tags)
+				}
+				t.AppendRow(r)
 			} else {
 				t.AppendRow(table.Row{
 					row.Path,
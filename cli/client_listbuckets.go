@@ -0,0 +1,206 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+type ListBucketsOption struct {
+	// Region only lists the buckets in the region if not empty.
+	Region     string
+	Versioning bool
+	// Stat counts the objects and their size by listing every bucket, which takes long for large buckets.
+	Stat  bool
+	Human bool
+	JSON  bool
+}
+
+type BucketDesc struct {
+	Name         string `json:"name"`
+	Region       string `json:"region"`
+	CreationDate string `json:"creationDate"`
+	Versioning   string `json:"versioning,omitempty"`
+	// Objects and Size are the current versions, without noncurrent versions and uncompleted multipart uploads.
+	Objects *int64 `json:"objects,omitempty"`
+	Size    *int64 `json:"size,omitempty"`
+}
+
+// ListBuckets prints the buckets of the account, in every region or in options.Region.
+func (client *Client) ListBuckets(options *ListBucketsOption) bool {
+	serviceURL := fmt.Sprintf("%s://service.cos.myqcloud.com", client.Config.Schema)
+	if options.Region != "" {
+		serviceURL = fmt.Sprintf("%s://cos.%s.myqcloud.com", client.Config.Schema, compatible(options.Region))
+	}
+	u, err := url.Parse(serviceURL)
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	serviceClient := cos.NewClient(&cos.BaseURL{ServiceURL: u}, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:     client.Config.SecretID,
+			SecretKey:    client.Config.SecretKey,
+			SessionToken: client.Config.Token,
+		},
+		Timeout: time.Duration(client.Config.Timeout) * time.Second,
+	})
+	result, _, err := serviceClient.Service.Get(context.Background())
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	buckets := make([]BucketDesc, 0, len(result.Buckets))
+	for _, bucket := range result.Buckets {
+		buckets = append(buckets, BucketDesc{
+			Name:         bucket.Name,
+			Region:       bucket.Region,
+			CreationDate: bucket.CreationDate,
+		})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Name < buckets[j].Name
+	})
+	success := true
+	if options.Versioning || options.Stat {
+		var mutex sync.Mutex
+		var wg sync.WaitGroup
+		describing := make(chan struct{}, client.Config.MaxThread)
+		for i := range buckets {
+			wg.Add(1)
+			go func(bucket *BucketDesc) {
+				defer wg.Done()
+				describing <- struct{}{}
+				defer func() { <-describing }()
+				if err := client.describeBucket(bucket, options); err != nil {
+					log.Warnf("Cannot describe bucket %s: %s", bucket.Name, err.Error())
+					mutex.Lock()
+					success = false
+					mutex.Unlock()
+				}
+			}(&buckets[i])
+		}
+		wg.Wait()
+	}
+	if options.JSON {
+		data, _ := json.MarshalIndent(buckets, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printBuckets(buckets, options)
+	}
+	return success
+}
+
+// Fill the versioning state and the statistics of bucket with a client of the bucket.
+func (client *Client) describeBucket(bucket *BucketDesc, options *ListBucketsOption) error {
+	config := *client.Config
+	config.Bucket = bucket.Name
+	config.Region = bucket.Region
+	config.Endpoint = "cos." + bucket.Region + ".myqcloud.com"
+	bucketClient := NewClient(&config)
+	if options.Versioning {
+		result, _, err := bucketClient.Client.Bucket.GetVersioning(context.Background())
+		if err != nil {
+			return err
+		}
+		bucket.Versioning = result.Status
+		if bucket.Versioning == "" {
+			bucket.Versioning = "Not configured"
+		}
+	}
+	if options.Stat {
+		var objects, size int64
+		marker := ""
+		for {
+			result, _, err := bucketClient.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+				Marker:  marker,
+				MaxKeys: 1000,
+			})
+			if err != nil {
+				return err
+			}
+			for _, object := range result.Contents {
+				objects++
+				size += object.Size
+			}
+			if !result.IsTruncated {
+				break
+			}
+			marker = result.NextMarker
+		}
+		bucket.Objects = &objects
+		bucket.Size = &size
+	}
+	return nil
+}
+
+func printBuckets(buckets []BucketDesc, options *ListBucketsOption) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.Style().Options.DrawBorder = false
+	t.Style().Options.SeparateColumns = false
+	header := table.Row{"Name", "Region", "Creation Date"}
+	if options.Versioning {
+		header = append(header, "Versioning")
+	}
+	if options.Stat {
+		header = append(header, "Objects", "Size")
+		t.SetColumnConfigs([]table.ColumnConfig{
+			{Name: "Objects", Align: text.AlignRight},
+			{Name: "Size", Align: text.AlignRight},
+		})
+	}
+	t.AppendHeader(header)
+	var totalObjects, totalSize int64
+	for _, bucket := range buckets {
+		row := table.Row{bucket.Name, bucket.Region, coshelper.ConvertTime(bucket.CreationDate)}
+		if options.Versioning {
+			row = append(row, bucket.Versioning)
+		}
+		if options.Stat {
+			if bucket.Objects != nil {
+				row = append(row, *bucket.Objects, coshelper.Humanize(*bucket.Size, options.Human))
+				totalObjects += *bucket.Objects
+				totalSize += *bucket.Size
+			} else {
+				row = append(row, "-", "-")
+			}
+		}
+		t.AppendRow(row)
+	}
+	t.Render()
+	log.Infof(" Buckets num: %d", len(buckets))
+	if options.Stat {
+		log.Infof(" Objects num: %d", totalObjects)
+		log.Infof(" Objects size: %s", coshelper.Humanize(totalSize, options.Human))
+	}
+}
@@ -0,0 +1,445 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// Columns of COS access logs used by the analysis, counted from 0, see https://cloud.tencent.com/document/product/436/16920
+// The columns are eventVersion, bucketName, qcsRegion, eventTime, eventSource, eventName, remoteIp,
+// userSecretKeyId, reservedFiled, reqBytesSent, deltaDataSize, reqPath, reqMethod, userAgent,
+// resHttpCode, resErrorCode, resErrorMsg, resBytesSent, resTotalTime and more.
+const (
+	logEventTime     = 3
+	logEventName     = 5
+	logRemoteIP      = 6
+	logReqBytesSent  = 9
+	logReqPath       = 11
+	logResHTTPCode   = 14
+	logResBytesSent  = 17
+	logMinimumFields = 18
+)
+
+type LogsAnalyzeOption struct {
+	// SourcePath is the bucket of logs as bucket-appid.cos.ap-guangzhou.myqcloud.com, empty for this bucket.
+	SourcePath string
+	// Only requests in [Since, Until) are counted, zero for no limit.
+	Since, Until time.Time
+	Top          int
+	JSON         bool
+}
+
+type logsReport struct {
+	Requests      int64            `json:"requests"`
+	BytesSent     int64            `json:"bytesSent"`
+	BytesReceived int64            `json:"bytesReceived"`
+	Errors4xx     int64            `json:"errors4xx"`
+	Errors5xx     int64            `json:"errors5xx"`
+	Malformed     int64            `json:"malformedLines"`
+	TopKeys       []logsCount      `json:"topKeys"`
+	TopClientIPs  []logsCount      `json:"topClientIps"`
+	Operations    []logsOperation  `json:"operations"`
+	Hours         []*logsHour      `json:"hours"`
+	keys          map[string]int64 // requests by key
+	ips           map[string]int64 // requests by client IP
+	operations    map[string]*logsOperation
+	hours         map[string]*logsHour
+	mutex         sync.Mutex
+}
+
+type logsCount struct {
+	Name     string `json:"name"`
+	Requests int64  `json:"requests"`
+}
+
+type logsOperation struct {
+	Operation     string `json:"operation"`
+	Requests      int64  `json:"requests"`
+	BytesSent     int64  `json:"bytesSent"`
+	BytesReceived int64  `json:"bytesReceived"`
+}
+
+type logsHour struct {
+	// Hour like 2006-01-02 15:00 in local time.
+	Hour      string  `json:"hour"`
+	Requests  int64   `json:"requests"`
+	Errors4xx int64   `json:"errors4xx"`
+	Errors5xx int64   `json:"errors5xx"`
+	Rate4xx   float64 `json:"rate4xx"`
+	Rate5xx   float64 `json:"rate5xx"`
+}
+
+// A request in the access logs.
+type logRecord struct {
+	time          time.Time
+	operation     string
+	ip            string
+	key           string
+	status        int
+	bytesSent     int64
+	bytesReceived int64
+}
+
+// LogsAnalyze downloads the access log objects with the prefix and prints the top keys, the top client IPs,
+// the bytes per operation and the error rates per hour, in tables or in JSON.
+// If analyzed successfully, return 0; otherwise return -1.
+func (client *Client) LogsAnalyze(cosPath string, options *LogsAnalyzeOption) int {
+	logClient := client
+	if options.SourcePath != "" {
+		var err error
+		if logClient, err = client.sourcePathToClient(strings.TrimSuffix(options.SourcePath, "/") + "/"); err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+	}
+	report := &logsReport{
+		keys:       make(map[string]int64),
+		ips:        make(map[string]int64),
+		operations: make(map[string]*logsOperation),
+		hours:      make(map[string]*logsHour),
+	}
+	fileNum, failNum := 0, 0
+	nextMarker := ""
+	isTruncated := true
+	reading := make(chan struct{}, client.Config.MaxThread)
+	readResult := make(chan bool, client.Config.MaxThread)
+	for isTruncated {
+		var result *cos.BucketGetResult
+		for i := 0; i <= client.Config.RetryTimes; i++ {
+			var err error
+			result, _, err = logClient.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+				Prefix:  cosPath,
+				Marker:  nextMarker,
+				MaxKeys: 1000,
+			})
+			if err == nil {
+				break
+			}
+			log.Warn(err.Error())
+			if i >= client.Config.RetryTimes {
+				return -1
+			}
+			time.Sleep((1 << i) * time.Second)
+		}
+		isTruncated = result.IsTruncated
+		nextMarker = result.NextMarker
+		tasks := 0
+		for _, file := range result.Contents {
+			// A log object has no request later than its modification.
+			if modTime, err := time.Parse(time.RFC3339, file.LastModified); err == nil &&
+				!options.Since.IsZero() && modTime.Before(options.Since) {
+				continue
+			}
+			tasks++
+			go func(key string) {
+				reading <- struct{}{}
+				readResult <- logClient.readLogObject(key, report, options)
+				<-reading
+			}(file.Key)
+		}
+		for j := 0; j < tasks; j++ {
+			fileNum++
+			if !<-readResult {
+				failNum++
+			}
+		}
+	}
+	log.Infof("%d log files read, %d log files failed", fileNum-failNum, failNum)
+	report.summarize(options.Top)
+	if options.JSON {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		report.print(options.Top)
+	}
+	if failNum != 0 {
+		return -1
+	}
+	return 0
+}
+
+// Read a log object into the report.
+func (client *Client) readLogObject(key string, report *logsReport, options *LogsAnalyzeOption) bool {
+	log.Debugf("Read cos://%s/%s", client.Config.Bucket, key)
+	resp, err := client.Client.Object.Get(context.Background(), key, nil)
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	var reader io.Reader = resp.Body
+	if strings.HasSuffix(key, ".gz") {
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			log.Warnf("Read cos://%s/%s failed: %s", client.Config.Bucket, key, err.Error())
+			return false
+		}
+		reader = gzipReader
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	records := make([]logRecord, 0)
+	malformed := int64(0)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		record, ok := parseLogLine(line)
+		if !ok {
+			malformed++
+			continue
+		}
+		if (!options.Since.IsZero() && record.time.Before(options.Since)) ||
+			(!options.Until.IsZero() && !record.time.Before(options.Until)) {
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Warnf("Read cos://%s/%s failed: %s", client.Config.Bucket, key, err.Error())
+		return false
+	}
+	report.add(records, malformed)
+	return true
+}
+
+// Parse a line of access logs, whose fields are separated by spaces and may be quoted.
+func parseLogLine(line string) (logRecord, bool) {
+	fields := splitLogFields(line)
+	if len(fields) < logMinimumFields {
+		return logRecord{}, false
+	}
+	eventTime, ok := parseLogTime(fields[logEventTime])
+	if !ok {
+		return logRecord{}, false
+	}
+	status, err := strconv.Atoi(fields[logResHTTPCode])
+	if err != nil {
+		return logRecord{}, false
+	}
+	// Cut the query before unescaping, a key may contain an escaped '?'.
+	key := fields[logReqPath]
+	if i := strings.Index(key, "?"); i >= 0 {
+		key = key[:i]
+	}
+	if unescaped, err := url.PathUnescape(key); err == nil {
+		key = unescaped
+	}
+	record := logRecord{
+		time:      eventTime,
+		operation: fields[logEventName],
+		ip:        fields[logRemoteIP],
+		key:       strings.TrimPrefix(key, "/"),
+		status:    status,
+	}
+	// "-" for no value
+	record.bytesReceived, _ = strconv.ParseInt(fields[logReqBytesSent], 10, 64)
+	record.bytesSent, _ = strconv.ParseInt(fields[logResBytesSent], 10, 64)
+	return record, true
+}
+
+func splitLogFields(line string) []string {
+	fields := make([]string, 0, 32)
+	var field strings.Builder
+	quoted, inField := false, false
+	for _, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			inField = true
+		case (c == ' ' || c == '\t') && !quoted:
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(c)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// The time of requests is like 20060102T150405Z, or in seconds since epoch.
+func parseLogTime(str string) (time.Time, bool) {
+	for _, layout := range []string{"20060102T150405Z", time.RFC3339} {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t, true
+		}
+	}
+	if seconds, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return time.Unix(seconds, 0), true
+	}
+	return time.Time{}, false
+}
+
+func (report *logsReport) add(records []logRecord, malformed int64) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+	report.Malformed += malformed
+	for _, record := range records {
+		report.Requests++
+		report.BytesSent += record.bytesSent
+		report.BytesReceived += record.bytesReceived
+		if record.key != "" {
+			report.keys[record.key]++
+		}
+		report.ips[record.ip]++
+		operation, ok := report.operations[record.operation]
+		if !ok {
+			operation = &logsOperation{Operation: record.operation}
+			report.operations[record.operation] = operation
+		}
+		operation.Requests++
+		operation.BytesSent += record.bytesSent
+		operation.BytesReceived += record.bytesReceived
+		hourName := record.time.Local().Format("2006-01-02 15:00")
+		hour, ok := report.hours[hourName]
+		if !ok {
+			hour = &logsHour{Hour: hourName}
+			report.hours[hourName] = hour
+		}
+		hour.Requests++
+		switch {
+		case record.status >= 500:
+			report.Errors5xx++
+			hour.Errors5xx++
+		case record.status >= 400:
+			report.Errors4xx++
+			hour.Errors4xx++
+		}
+	}
+}
+
+// Fill the exported fields of the report from the maps.
+func (report *logsReport) summarize(top int) {
+	report.TopKeys = topCounts(report.keys, top)
+	report.TopClientIPs = topCounts(report.ips, top)
+	report.Operations = make([]logsOperation, 0, len(report.operations))
+	for _, operation := range report.operations {
+		report.Operations = append(report.Operations, *operation)
+	}
+	sort.Slice(report.Operations, func(i, j int) bool {
+		if report.Operations[i].BytesSent+report.Operations[i].BytesReceived !=
+			report.Operations[j].BytesSent+report.Operations[j].BytesReceived {
+			return report.Operations[i].BytesSent+report.Operations[i].BytesReceived >
+				report.Operations[j].BytesSent+report.Operations[j].BytesReceived
+		}
+		return report.Operations[i].Operation < report.Operations[j].Operation
+	})
+	report.Hours = make([]*logsHour, 0, len(report.hours))
+	for _, hour := range report.hours {
+		hour.Rate4xx = float64(hour.Errors4xx) / float64(hour.Requests)
+		hour.Rate5xx = float64(hour.Errors5xx) / float64(hour.Requests)
+		report.Hours = append(report.Hours, hour)
+	}
+	sort.Slice(report.Hours, func(i, j int) bool {
+		return report.Hours[i].Hour < report.Hours[j].Hour
+	})
+}
+
+// The top n names by requests.
+func topCounts(counts map[string]int64, n int) []logsCount {
+	result := make([]logsCount, 0, len(counts))
+	for name, requests := range counts {
+		result = append(result, logsCount{Name: name, Requests: requests})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Requests != result[j].Requests {
+			return result[i].Requests > result[j].Requests
+		}
+		return result[i].Name < result[j].Name
+	})
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+func (report *logsReport) print(top int) {
+	log.Infof("%d requests, %s sent, %s received, %d 4xx errors, %d 5xx errors, %d malformed lines",
+		report.Requests, coshelper.Humanize(report.BytesSent, true), coshelper.Humanize(report.BytesReceived, true),
+		report.Errors4xx, report.Errors5xx, report.Malformed)
+	for _, counts := range []struct {
+		title, header string
+		rows          []logsCount
+	}{
+		{"keys", "Key", report.TopKeys},
+		{"client IPs", "Client IP", report.TopClientIPs},
+	} {
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetTitle(fmt.Sprintf("Top %d %s", top, counts.title))
+		t.AppendHeader(table.Row{counts.header, "Requests"})
+		for _, row := range counts.rows {
+			t.AppendRow(table.Row{row.Name, row.Requests})
+		}
+		t.Render()
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Operation", "Requests", "Sent", "Received"})
+	for _, operation := range report.Operations {
+		t.AppendRow(table.Row{
+			operation.Operation,
+			operation.Requests,
+			coshelper.Humanize(operation.BytesSent, true),
+			coshelper.Humanize(operation.BytesReceived, true),
+		})
+	}
+	t.Render()
+	t = table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Hour", "Requests", "4xx", "5xx", "4xx Rate", "5xx Rate"})
+	for _, hour := range report.Hours {
+		t.AppendRow(table.Row{
+			hour.Hour,
+			hour.Requests,
+			hour.Errors4xx,
+			hour.Errors5xx,
+			fmt.Sprintf("%.2f%%", hour.Rate4xx*100),
+			fmt.Sprintf("%.2f%%", hour.Rate5xx*100),
+		})
+	}
+	t.Render()
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLogLine(t *testing.T) {
+	// A line in the documented format, with reservedFiled between userSecretKeyId and reqBytesSent.
+	line := `1.0 examplebucket-1250000000 ap-beijing 20180705T144313Z examplebucket-1250000000.cos.ap-beijing.myqcloud.com ` +
+		`GetObject 10.0.0.1 AKIDNYVCdoJQyGJ5brTf - 83 - /dir/a%3Fb.txt?versionId=1 GET "cos-go-sdk-v5/0.7.24 (linux)" ` +
+		`200 - - 1024 12 - STANDARD 100000000001 10 - 5b3e2a6d_1c9a2809_3c8d_2e1a - - - - -`
+	record, ok := parseLogLine(line)
+	if !ok {
+		t.Fatal("the line is not parsed")
+	}
+	want := logRecord{
+		time:          time.Date(2018, 7, 5, 14, 43, 13, 0, time.UTC),
+		operation:     "GetObject",
+		ip:            "10.0.0.1",
+		key:           "dir/a?b.txt",
+		status:        200,
+		bytesSent:     1024,
+		bytesReceived: 83,
+	}
+	if !record.time.Equal(want.time) {
+		t.Errorf("time = %v, want %v", record.time, want.time)
+	}
+	record.time = want.time
+	if record != want {
+		t.Errorf("record = %+v, want %+v", record, want)
+	}
+}
+
+func TestParseLogLineMalformed(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"1.0 examplebucket-1250000000 ap-beijing",
+		`1.0 b ap-beijing notatime s GetObject 10.0.0.1 - - 0 - /a GET "ua" 200 - - 0 1`,
+		`1.0 b ap-beijing 20180705T144313Z s GetObject 10.0.0.1 - - 0 - /a GET "ua" OK - - 0 1`,
+	} {
+		if _, ok := parseLogLine(line); ok {
+			t.Errorf("%q is parsed", line)
+		}
+	}
+}
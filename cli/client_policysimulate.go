@@ -0,0 +1,250 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type PolicySimulateOption struct {
+	Principal string
+	Action    string
+	Resource  string
+	// Values of condition keys, like qcs:ip.
+	Context map[string]string
+}
+
+var uinRegex = regexp.MustCompile(`^\d+$`)
+
+// SimulatePolicy evaluates the policy locally, explaining whether the request would be allowed.
+// If policy is nil, the policy of the bucket is fetched. client is only used to fetch the policy
+// and to expand a COS path to the resource name, so it can be nil for offline reviews with
+// a policy file and a full resource name.
+// An explicit deny overrides any allow, and requests matching no statement are denied.
+// If allowed, return 0; if denied, return 1; if failed, return -1
+func (client *Client) SimulatePolicy(policy *PolicyDocument, options *PolicySimulateOption) int {
+	if policy == nil {
+		var configured bool
+		var err error
+		policy, configured, err = client.getBucketPolicy()
+		if err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+		if !configured {
+			log.Info("Policy is not configured")
+		}
+	}
+	principal := normalizePrincipal(options.Principal)
+	action := options.Action
+	if action != "*" && !strings.HasPrefix(action, "name/") {
+		action = "name/" + action
+	}
+	resource := options.Resource
+	if !strings.HasPrefix(resource, "qcs::") {
+		resource = client.policyResource(resource)
+	}
+	request := fmt.Sprintf("%s %s on %s", principal, action, resource)
+
+	allowed := false
+	for i, statement := range policy.Statement {
+		name := fmt.Sprintf("Statement %d (%s)", i+1, statement.Effect)
+		principals := statement.Principal
+		if len(principals) == 0 {
+			principals = policy.Principal
+		}
+		if reason := statement.mismatch(principals, principal, action, resource, options.Context); reason != "" {
+			log.Infof("%s does not match: %s", name, reason)
+			continue
+		}
+		log.Infof("%s matches", name)
+		if strings.EqualFold(statement.Effect, "deny") {
+			log.Infof("DENIED: %s, explicitly denied by statement %d", request, i+1)
+			return 1
+		}
+		allowed = true
+	}
+	if !allowed {
+		log.Infof("DENIED: %s, no statement allows it", request)
+		return 1
+	}
+	log.Infof("ALLOWED: %s", request)
+	return 0
+}
+
+// The resource name of the object in the bucket, like qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/a.txt
+func (client *Client) policyResource(key string) string {
+	bucket := client.Config.Bucket
+	appID := bucket[strings.LastIndex(bucket, "-")+1:]
+	region := client.Config.Region
+	if region == "" {
+		// Only a custom endpoint is configured, guess the region from it.
+		region = strings.TrimSuffix(strings.TrimPrefix(client.Config.Endpoint, "cos."), ".myqcloud.com")
+	}
+	return fmt.Sprintf("qcs::cos:%s:uid/%s:%s/%s", region, appID, bucket, strings.TrimLeft(key, "/"))
+}
+
+// Expand "anyone" and bare UINs of root accounts to principal names.
+func normalizePrincipal(principal string) string {
+	switch {
+	case principal == "anyone" || principal == "*":
+		return "qcs::cam::anyone:anyone"
+	case uinRegex.MatchString(principal):
+		return fmt.Sprintf("qcs::cam::uin/%s:uin/%s", principal, principal)
+	default:
+		return principal
+	}
+}
+
+// Return why the statement does not match the request, or "" if it matches.
+func (statement *PolicyStatement) mismatch(principals PolicyPrincipal, principal, action, resource string,
+	context map[string]string) string {
+	principalMatched := false
+	for _, values := range principals {
+		for _, value := range values {
+			if value == "*" || value == "qcs::cam::anyone:anyone" || policyWildcardMatch(value, principal, false) {
+				principalMatched = true
+			}
+		}
+	}
+	if !principalMatched {
+		return "principal does not match"
+	}
+	actionMatched := false
+	for _, value := range statement.Action {
+		if policyWildcardMatch(value, action, true) {
+			actionMatched = true
+			break
+		}
+	}
+	if !actionMatched {
+		return fmt.Sprintf("action is not in %s", strings.Join(statement.Action, ", "))
+	}
+	resourceMatched := false
+	for _, value := range statement.Resource {
+		if policyWildcardMatch(value, resource, false) {
+			resourceMatched = true
+			break
+		}
+	}
+	if !resourceMatched {
+		return fmt.Sprintf("resource is not in %s", strings.Join(statement.Resource, ", "))
+	}
+	for operator, conditions := range statement.Condition {
+		for key, values := range conditions {
+			value, ok := context[key]
+			if !evaluateCondition(operator, values, value, ok) {
+				if !ok {
+					return fmt.Sprintf("condition %s on %s is not satisfied, %s is not given", operator, key, key)
+				}
+				return fmt.Sprintf("condition %s on %s is not satisfied by %s", operator, key, value)
+			}
+		}
+	}
+	return ""
+}
+
+// Whether value satisfies the condition on a key. A positive operator is satisfied if any of
+// values matches, and a negative one if none matches. Missing keys only satisfy negative operators.
+func evaluateCondition(operator string, values []string, value string, present bool) bool {
+	negative := strings.Contains(operator, "_not_")
+	if !present {
+		return negative
+	}
+	matched := false
+	for _, expected := range values {
+		if conditionMatch(strings.Replace(operator, "_not_", "_", 1), expected, value) {
+			matched = true
+			break
+		}
+	}
+	return matched != negative
+}
+
+func conditionMatch(operator string, expected string, value string) bool {
+	switch operator {
+	case "string_equal":
+		return expected == value
+	case "string_like":
+		return policyWildcardMatch(expected, value, false)
+	case "ip_equal":
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return false
+		}
+		if !strings.Contains(expected, "/") {
+			return ip.Equal(net.ParseIP(expected))
+		}
+		_, network, err := net.ParseCIDR(expected)
+		return err == nil && network.Contains(ip)
+	case "bool_equal":
+		return strings.EqualFold(expected, value)
+	}
+	if strings.HasPrefix(operator, "numeric_") {
+		a, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		b, err := strconv.ParseFloat(expected, 64)
+		if err != nil {
+			return false
+		}
+		switch operator {
+		case "numeric_equal":
+			return a == b
+		case "numeric_less_than":
+			return a < b
+		case "numeric_less_than_equal":
+			return a <= b
+		case "numeric_greater_than":
+			return a > b
+		case "numeric_greater_than_equal":
+			return a >= b
+		}
+	}
+	return false
+}
+
+// Match s against pattern where '*' matches any string.
+func policyWildcardMatch(pattern string, s string, ignoreCase bool) bool {
+	if ignoreCase {
+		pattern = strings.ToLower(pattern)
+		s = strings.ToLower(s)
+	}
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
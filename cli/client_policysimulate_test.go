@@ -0,0 +1,213 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPolicyWildcardMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		ignoreCase bool
+		match      bool
+	}{
+		{"*", "anything", false, true},
+		{"name/cos:GetObject", "name/cos:GetObject", false, true},
+		{"name/cos:Get*", "name/cos:GetObject", false, true},
+		{"name/cos:get*", "name/cos:GetObject", true, true},
+		{"name/cos:get*", "name/cos:GetObject", false, false},
+		{"qcs::cos:*:uid/1250000000:bucket-1250000000/dir/*", "qcs::cos:ap-guangzhou:uid/1250000000:bucket-1250000000/dir/a.txt", false, true},
+		{"qcs::cos:*:uid/1250000000:bucket-1250000000/dir/*", "qcs::cos:ap-guangzhou:uid/1250000000:bucket-1250000000/a.txt", false, false},
+		{"a*b*c", "axxbyyc", false, true},
+		{"a*b*c", "axxcyyb", false, false},
+		{"a*bc*c", "abc", false, false},
+	}
+	for _, test := range tests {
+		if got := policyWildcardMatch(test.pattern, test.s, test.ignoreCase); got != test.match {
+			t.Errorf("policyWildcardMatch(%q, %q, %v) = %v, want %v",
+				test.pattern, test.s, test.ignoreCase, got, test.match)
+		}
+	}
+}
+
+func TestNormalizePrincipal(t *testing.T) {
+	tests := map[string]string{
+		"anyone":                    "qcs::cam::anyone:anyone",
+		"*":                         "qcs::cam::anyone:anyone",
+		"100000000001":              "qcs::cam::uin/100000000001:uin/100000000001",
+		"qcs::cam::uin/1:uin/2":     "qcs::cam::uin/1:uin/2",
+		"qcs::cam::uin/1:uin/1abc2": "qcs::cam::uin/1:uin/1abc2",
+	}
+	for principal, want := range tests {
+		if got := normalizePrincipal(principal); got != want {
+			t.Errorf("normalizePrincipal(%q) = %q, want %q", principal, got, want)
+		}
+	}
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	tests := []struct {
+		operator string
+		values   []string
+		value    string
+		present  bool
+		want     bool
+	}{
+		{"string_equal", []string{"a", "b"}, "b", true, true},
+		{"string_equal", []string{"a", "b"}, "c", true, false},
+		{"string_equal", []string{"a"}, "", false, false},
+		{"string_not_equal", []string{"a", "b"}, "c", true, true},
+		{"string_not_equal", []string{"a", "b"}, "a", true, false},
+		{"string_not_equal", []string{"a"}, "", false, true},
+		{"string_like", []string{"image/*"}, "image/png", true, true},
+		{"ip_equal", []string{"10.0.0.0/8"}, "10.1.2.3", true, true},
+		{"ip_equal", []string{"10.0.0.0/8"}, "192.168.0.1", true, false},
+		{"ip_equal", []string{"192.168.0.1"}, "192.168.0.1", true, true},
+		{"ip_equal", []string{"192.168.0.1"}, "not an ip", true, false},
+		{"ip_not_equal", []string{"10.0.0.0/8"}, "192.168.0.1", true, true},
+		{"bool_equal", []string{"true"}, "TRUE", true, true},
+		{"numeric_less_than_equal", []string{"100"}, "100", true, true},
+		{"numeric_less_than", []string{"100"}, "100", true, false},
+		{"numeric_greater_than", []string{"100"}, "100.5", true, true},
+		{"numeric_greater_than_equal", []string{"100"}, "99", true, false},
+		{"numeric_equal", []string{"1"}, "1.0", true, true},
+		{"numeric_not_equal", []string{"1"}, "2", true, true},
+		{"numeric_equal", []string{"1"}, "one", true, false},
+		{"unknown_operator", []string{"a"}, "a", true, false},
+	}
+	for _, test := range tests {
+		if got := evaluateCondition(test.operator, test.values, test.value, test.present); got != test.want {
+			t.Errorf("evaluateCondition(%q, %v, %q, %v) = %v, want %v",
+				test.operator, test.values, test.value, test.present, got, test.want)
+		}
+	}
+}
+
+func TestStatementMismatch(t *testing.T) {
+	resource := "qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/dir/a.txt"
+	principals := PolicyPrincipal{"qcs": {"qcs::cam::uin/100000000001:uin/100000000002"}}
+	statement := PolicyStatement{
+		Effect:    "allow",
+		Action:    PolicyValues{"name/cos:GetObject", "name/cos:HeadObject"},
+		Resource:  PolicyValues{"qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/dir/*"},
+		Condition: map[string]map[string]PolicyValues{"ip_equal": {"qcs:ip": {"10.0.0.0/8"}}},
+	}
+	context := map[string]string{"qcs:ip": "10.0.0.1"}
+	tests := []struct {
+		name       string
+		principals PolicyPrincipal
+		principal  string
+		action     string
+		resource   string
+		context    map[string]string
+		reason     string
+	}{
+		{"match", principals, "qcs::cam::uin/100000000001:uin/100000000002", "name/cos:getobject", resource, context, ""},
+		{"anyone", PolicyPrincipal{"qcs": {"qcs::cam::anyone:anyone"}}, "qcs::cam::uin/1:uin/1", "name/cos:GetObject", resource, context, ""},
+		{"principal", principals, "qcs::cam::uin/100000000001:uin/100000000003", "name/cos:GetObject", resource, context, "principal"},
+		{"action", principals, "qcs::cam::uin/100000000001:uin/100000000002", "name/cos:PutObject", resource, context, "action"},
+		{"resource", principals, "qcs::cam::uin/100000000001:uin/100000000002", "name/cos:GetObject",
+			strings.Replace(resource, "/dir/", "/other/", 1), context, "resource"},
+		{"condition", principals, "qcs::cam::uin/100000000001:uin/100000000002", "name/cos:GetObject", resource,
+			map[string]string{"qcs:ip": "192.168.0.1"}, "not satisfied by 192.168.0.1"},
+		{"missing condition key", principals, "qcs::cam::uin/100000000001:uin/100000000002", "name/cos:GetObject", resource,
+			nil, "qcs:ip is not given"},
+	}
+	for _, test := range tests {
+		reason := statement.mismatch(test.principals, test.principal, test.action, test.resource, test.context)
+		switch {
+		case test.reason == "" && reason != "":
+			t.Errorf("%s: does not match: %s", test.name, reason)
+		case test.reason != "" && !strings.Contains(reason, test.reason):
+			t.Errorf("%s: reason %q, want %q", test.name, reason, test.reason)
+		}
+	}
+}
+
+func TestSimulatePolicy(t *testing.T) {
+	client := &Client{Config: &ClientConfig{
+		Bucket:   "examplebucket-1250000000",
+		Endpoint: "cos.ap-guangzhou.myqcloud.com",
+		Region:   "ap-guangzhou",
+	}}
+	policy := &PolicyDocument{
+		Principal: PolicyPrincipal{"qcs": {"qcs::cam::anyone:anyone"}},
+		Statement: []PolicyStatement{
+			{
+				Effect:   "allow",
+				Action:   PolicyValues{"name/cos:GetObject"},
+				Resource: PolicyValues{"qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/*"},
+			},
+			{
+				Effect:   "deny",
+				Action:   PolicyValues{"*"},
+				Resource: PolicyValues{"qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/private/*"},
+			},
+		},
+	}
+	tests := []struct {
+		principal, action, resource string
+		want                        int
+	}{
+		{"anyone", "cos:GetObject", "a.txt", 0},
+		{"100000000001", "name/cos:GetObject", "/dir/a.txt", 0},
+		{"anyone", "cos:PutObject", "a.txt", 1},
+		{"anyone", "cos:GetObject", "private/a.txt", 1},
+		{"anyone", "cos:GetObject", "qcs::cos:ap-beijing:uid/1250000000:examplebucket-1250000000/a.txt", 1},
+	}
+	for _, test := range tests {
+		option := &PolicySimulateOption{Principal: test.principal, Action: test.action, Resource: test.resource}
+		if got := client.SimulatePolicy(policy, option); got != test.want {
+			t.Errorf("SimulatePolicy(%s %s %s) = %d, want %d", test.principal, test.action, test.resource, got, test.want)
+		}
+	}
+}
+
+func TestPolicyResource(t *testing.T) {
+	client := &Client{Config: &ClientConfig{
+		Bucket:   "examplebucket-1250000000",
+		Endpoint: "cos.accelerate.myqcloud.com",
+		Region:   "ap-guangzhou",
+	}}
+	want := "qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/dir/a.txt"
+	if got := client.policyResource("/dir/a.txt"); got != want {
+		t.Errorf("policyResource = %q, want %q", got, want)
+	}
+}
+
+func TestSimulatePolicyOffline(t *testing.T) {
+	// Without configuration, a policy file and a full resource name are enough.
+	var client *Client
+	policy := &PolicyDocument{
+		Statement: []PolicyStatement{{
+			Principal: PolicyPrincipal{"qcs": {"qcs::cam::anyone:anyone"}},
+			Effect:    "allow",
+			Action:    PolicyValues{"name/cos:GetObject"},
+			Resource:  PolicyValues{"qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/*"},
+		}},
+	}
+	option := &PolicySimulateOption{
+		Principal: "anyone",
+		Action:    "cos:GetObject",
+		Resource:  "qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/a.txt",
+	}
+	if got := client.SimulatePolicy(policy, option); got != 0 {
+		t.Errorf("SimulatePolicy = %d, want 0", got)
+	}
+}
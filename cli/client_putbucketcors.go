@@ -0,0 +1,116 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CORSConfiguration is the CORS rules of a bucket, read from JSON or YAML files and sent in XML.
+type CORSConfiguration struct {
+	XMLName xml.Name   `xml:"CORSConfiguration" json:"-" yaml:"-"`
+	Rules   []CORSRule `xml:"CORSRule" json:"rules" yaml:"rules"`
+}
+
+type CORSRule struct {
+	ID             string   `xml:"ID,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	AllowedOrigins []string `xml:"AllowedOrigin" json:"allowedOrigins" yaml:"allowedOrigins"`
+	AllowedMethods []string `xml:"AllowedMethod" json:"allowedMethods" yaml:"allowedMethods"`
+	AllowedHeaders []string `xml:"AllowedHeader,omitempty" json:"allowedHeaders,omitempty" yaml:"allowedHeaders,omitempty"`
+	ExposeHeaders  []string `xml:"ExposeHeader,omitempty" json:"exposeHeaders,omitempty" yaml:"exposeHeaders,omitempty"`
+	MaxAgeSeconds  int      `xml:"MaxAgeSeconds,omitempty" json:"maxAgeSeconds,omitempty" yaml:"maxAgeSeconds,omitempty"`
+}
+
+// Methods allowed in CORS rules.
+var corsMethods = []string{"GET", "PUT", "POST", "DELETE", "HEAD"}
+
+// Validate checks the configuration before sending it, so that mistakes are found with clear messages.
+func (config *CORSConfiguration) Validate() error {
+	if len(config.Rules) == 0 {
+		return fmt.Errorf("no rules")
+	}
+	if len(config.Rules) > 100 {
+		return fmt.Errorf("%d rules, at most 100 rules are allowed", len(config.Rules))
+	}
+	ids := make(map[string]struct{})
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		name := fmt.Sprintf("rule %d", i+1)
+		if rule.ID != "" {
+			name = fmt.Sprintf("rule '%s'", rule.ID)
+			if _, ok := ids[rule.ID]; ok {
+				return fmt.Errorf("%s: id is duplicated", name)
+			}
+			ids[rule.ID] = struct{}{}
+		}
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("%s: %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+func (rule *CORSRule) validate() error {
+	if len(rule.AllowedOrigins) == 0 {
+		return fmt.Errorf("allowedOrigins is required")
+	}
+	for _, origin := range rule.AllowedOrigins {
+		if origin == "" {
+			return fmt.Errorf("empty origin")
+		}
+		if strings.Count(origin, "*") > 1 {
+			return fmt.Errorf("origin '%s' has more than one '*'", origin)
+		}
+	}
+	if len(rule.AllowedMethods) == 0 {
+		return fmt.Errorf("allowedMethods is required")
+	}
+	for i, method := range rule.AllowedMethods {
+		rule.AllowedMethods[i] = strings.ToUpper(method)
+		if !containsString(corsMethods, rule.AllowedMethods[i]) {
+			return fmt.Errorf("method '%s' is not one of %s", method, strings.Join(corsMethods, ", "))
+		}
+	}
+	for _, header := range rule.AllowedHeaders {
+		if strings.Count(header, "*") > 1 {
+			return fmt.Errorf("header '%s' has more than one '*'", header)
+		}
+	}
+	if rule.MaxAgeSeconds < 0 {
+		return fmt.Errorf("maxAgeSeconds should not be negative")
+	}
+	return nil
+}
+
+// PutBucketCORS validates and sets the CORS rules of the bucket, the old ones are replaced.
+func (client *Client) PutBucketCORS(config *CORSConfiguration) bool {
+	if err := config.Validate(); err != nil {
+		log.Warnf("Invalid CORS rules: %s", err.Error())
+		return false
+	}
+	if err := client.sendBucketRequest(http.MethodPut, "/?cors", config, nil); err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	log.Infof("CORS of %s is set with %d rules", client.Config.Bucket, len(config.Rules))
+	return true
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// BucketEncryption is the default server-side encryption of new objects in a bucket, read from JSON or YAML
+// files and sent in XML. The SDK can not send the KMS key, so the requests are sent by ourselves.
+type BucketEncryption struct {
+	XMLName xml.Name `xml:"ServerSideEncryptionConfiguration" json:"-" yaml:"-"`
+	// Algorithm is AES256 for SSE-COS, or KMS for SSE-KMS.
+	Algorithm      string `xml:"Rule>ApplyServerSideEncryptionByDefault>SSEAlgorithm" json:"algorithm" yaml:"algorithm"`
+	KMSMasterKeyID string `xml:"Rule>ApplyServerSideEncryptionByDefault>KMSMasterKeyID,omitempty" json:"kmsMasterKeyId,omitempty" yaml:"kmsMasterKeyId,omitempty"`
+}
+
+// Validate checks the configuration before sending it, so that mistakes are found with clear messages.
+func (config *BucketEncryption) Validate() error {
+	switch strings.ToLower(config.Algorithm) {
+	case "aes256", "cos":
+		config.Algorithm = "AES256"
+	case "kms":
+		config.Algorithm = "KMS"
+	default:
+		return fmt.Errorf("algorithm must be AES256 (cos) or KMS (kms)")
+	}
+	if config.KMSMasterKeyID != "" && config.Algorithm != "KMS" {
+		return fmt.Errorf("kmsMasterKeyId can only be used with KMS")
+	}
+	return nil
+}
+
+// PutBucketEncryption validates and sets the default encryption of the bucket.
+func (client *Client) PutBucketEncryption(config *BucketEncryption) bool {
+	if err := config.Validate(); err != nil {
+		log.Warnf("Invalid encryption: %s", err.Error())
+		return false
+	}
+	if err := client.sendBucketRequest(http.MethodPut, "/?encryption", config, nil); err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	log.Infof("New objects of %s are encrypted with %s by default", client.Config.Bucket, config.Algorithm)
+	return true
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// InventoryFields are the optional fields of objects an inventory report can have.
+var InventoryFields = []string{
+	"Size",
+	"LastModifiedDate",
+	"ETag",
+	"StorageClass",
+	"IsMultipartUploaded",
+	"ReplicationStatus",
+}
+
+var inventoryIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// InventoryConfiguration is an inventory of a bucket, which reports the objects of the bucket in CSV files
+// daily or weekly. It is read from JSON or YAML files and sent in XML.
+type InventoryConfiguration struct {
+	XMLName   xml.Name `xml:"InventoryConfiguration" json:"-" yaml:"-"`
+	ID        string   `xml:"Id" json:"id" yaml:"id"`
+	IsEnabled bool     `xml:"IsEnabled" json:"enabled" yaml:"enabled"`
+	// IncludedObjectVersions is All or Current.
+	IncludedObjectVersions string               `xml:"IncludedObjectVersions" json:"includedObjectVersions" yaml:"includedObjectVersions"`
+	Filter                 *InventoryFilter     `xml:"Filter,omitempty" json:"filter,omitempty" yaml:"filter,omitempty"`
+	OptionalFields         []string             `xml:"OptionalFields>Field,omitempty" json:"optionalFields,omitempty" yaml:"optionalFields,omitempty"`
+	Schedule               InventorySchedule    `xml:"Schedule" json:"schedule" yaml:"schedule"`
+	Destination            InventoryDestination `xml:"Destination>COSBucketDestination" json:"destination" yaml:"destination"`
+}
+
+type InventoryFilter struct {
+	Prefix string `xml:"Prefix" json:"prefix" yaml:"prefix"`
+}
+
+type InventorySchedule struct {
+	// Frequency is Daily or Weekly.
+	Frequency string `xml:"Frequency" json:"frequency" yaml:"frequency"`
+}
+
+type InventoryDestination struct {
+	// Bucket like qcs::cos:ap-guangzhou::examplebucket-1250000000
+	Bucket    string `xml:"Bucket" json:"bucket" yaml:"bucket"`
+	AccountID string `xml:"AccountId,omitempty" json:"accountId,omitempty" yaml:"accountId,omitempty"`
+	Prefix    string `xml:"Prefix,omitempty" json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	// Format is CSV, the only format supported by COS.
+	Format string `xml:"Format" json:"format" yaml:"format"`
+	// Encrypted tells whether the reports are encrypted with SSE-COS, which is sent as Encryption.
+	Encrypted  bool                 `xml:"-" json:"encrypted,omitempty" yaml:"encrypted,omitempty"`
+	Encryption *InventoryEncryption `xml:"Encryption,omitempty" json:"-" yaml:"-"`
+}
+
+type InventoryEncryption struct {
+	SSECOS string `xml:"SSE-COS"`
+}
+
+// Validate checks the configuration before sending it, so that mistakes are found with clear messages.
+func (config *InventoryConfiguration) Validate() error {
+	if !inventoryIDPattern.MatchString(config.ID) {
+		return fmt.Errorf("id must have 1 to 64 letters, digits, '.', '-' or '_'")
+	}
+	switch strings.ToLower(config.IncludedObjectVersions) {
+	case "all":
+		config.IncludedObjectVersions = "All"
+	case "current":
+		config.IncludedObjectVersions = "Current"
+	default:
+		return fmt.Errorf("includedObjectVersions must be All or Current")
+	}
+	if config.Filter != nil {
+		if strings.HasPrefix(config.Filter.Prefix, "/") {
+			return fmt.Errorf("filter: prefix should not start with '/'")
+		}
+		if config.Filter.Prefix == "" {
+			config.Filter = nil
+		}
+	}
+	for i, field := range config.OptionalFields {
+		valid := false
+		for _, name := range InventoryFields {
+			if strings.EqualFold(field, name) {
+				config.OptionalFields[i] = name
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("optionalFields: '%s' must be one of %s", field, strings.Join(InventoryFields, ", "))
+		}
+	}
+	switch strings.ToLower(config.Schedule.Frequency) {
+	case "daily":
+		config.Schedule.Frequency = "Daily"
+	case "weekly":
+		config.Schedule.Frequency = "Weekly"
+	default:
+		return fmt.Errorf("schedule: frequency must be Daily or Weekly")
+	}
+	if _, _, err := parseReplicationBucket(config.Destination.Bucket); err != nil {
+		return fmt.Errorf("destination: %s", err.Error())
+	}
+	if strings.HasPrefix(config.Destination.Prefix, "/") {
+		return fmt.Errorf("destination: prefix should not start with '/'")
+	}
+	if config.Destination.Format == "" {
+		config.Destination.Format = "CSV"
+	}
+	if !strings.EqualFold(config.Destination.Format, "CSV") {
+		return fmt.Errorf("destination: format must be CSV")
+	}
+	config.Destination.Format = "CSV"
+	config.Destination.Encryption = nil
+	if config.Destination.Encrypted {
+		config.Destination.Encryption = &InventoryEncryption{}
+	}
+	return nil
+}
+
+// PutBucketInventory validates and sets the inventory of the bucket, the old one with the same ID is replaced.
+func (client *Client) PutBucketInventory(config *InventoryConfiguration) bool {
+	if err := config.Validate(); err != nil {
+		log.Warnf("Invalid inventory: %s", err.Error())
+		return false
+	}
+	if err := client.sendBucketRequest(http.MethodPut, "/?inventory&id="+url.QueryEscape(config.ID), config, nil); err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	log.Infof("Inventory %s of %s is set", config.ID, client.Config.Bucket)
+	return true
+}
@@ -0,0 +1,283 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LifecycleConfiguration is the lifecycle of a bucket. It is read from JSON or YAML files,
+// and sent in XML. The SDK does not support tag filters and multiple transitions, so it is defined here.
+type LifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration" json:"-" yaml:"-"`
+	Rules   []LifecycleRule `xml:"Rule" json:"rules" yaml:"rules"`
+}
+
+type LifecycleRule struct {
+	ID                             string                                   `xml:"ID" json:"id" yaml:"id"`
+	Status                         string                                   `xml:"Status" json:"status" yaml:"status"`
+	Filter                         LifecycleFilter                          `xml:"Filter" json:"filter" yaml:"filter"`
+	Transitions                    []LifecycleTransition                    `xml:"Transition,omitempty" json:"transitions,omitempty" yaml:"transitions,omitempty"`
+	Expiration                     *LifecycleExpiration                     `xml:"Expiration,omitempty" json:"expiration,omitempty" yaml:"expiration,omitempty"`
+	NoncurrentVersionTransitions   []LifecycleNoncurrentVersionTransition   `xml:"NoncurrentVersionTransition,omitempty" json:"noncurrentVersionTransitions,omitempty" yaml:"noncurrentVersionTransitions,omitempty"`
+	NoncurrentVersionExpiration    *LifecycleNoncurrentVersionExpiration    `xml:"NoncurrentVersionExpiration,omitempty" json:"noncurrentVersionExpiration,omitempty" yaml:"noncurrentVersionExpiration,omitempty"`
+	AbortIncompleteMultipartUpload *LifecycleAbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty" json:"abortIncompleteMultipartUpload,omitempty" yaml:"abortIncompleteMultipartUpload,omitempty"`
+}
+
+// LifecycleFilter selects objects by prefix and tags. In XML, multiple conditions are put in <And>.
+type LifecycleFilter struct {
+	Prefix string         `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Tags   []LifecycleTag `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type LifecycleTag struct {
+	Key   string `xml:"Key" json:"key" yaml:"key"`
+	Value string `xml:"Value" json:"value" yaml:"value"`
+}
+
+type LifecycleTransition struct {
+	Days         int    `xml:"Days,omitempty" json:"days,omitempty" yaml:"days,omitempty"`
+	Date         string `xml:"Date,omitempty" json:"date,omitempty" yaml:"date,omitempty"`
+	StorageClass string `xml:"StorageClass" json:"storageClass" yaml:"storageClass"`
+}
+
+type LifecycleExpiration struct {
+	Days                      int    `xml:"Days,omitempty" json:"days,omitempty" yaml:"days,omitempty"`
+	Date                      string `xml:"Date,omitempty" json:"date,omitempty" yaml:"date,omitempty"`
+	ExpiredObjectDeleteMarker bool   `xml:"ExpiredObjectDeleteMarker,omitempty" json:"expiredObjectDeleteMarker,omitempty" yaml:"expiredObjectDeleteMarker,omitempty"`
+}
+
+type LifecycleNoncurrentVersionTransition struct {
+	NoncurrentDays int    `xml:"NoncurrentDays" json:"noncurrentDays" yaml:"noncurrentDays"`
+	StorageClass   string `xml:"StorageClass" json:"storageClass" yaml:"storageClass"`
+}
+
+type LifecycleNoncurrentVersionExpiration struct {
+	NoncurrentDays int `xml:"NoncurrentDays" json:"noncurrentDays" yaml:"noncurrentDays"`
+}
+
+type LifecycleAbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation" json:"daysAfterInitiation" yaml:"daysAfterInitiation"`
+}
+
+type lifecycleAndOperator struct {
+	Prefix string         `xml:"Prefix,omitempty"`
+	Tags   []LifecycleTag `xml:"Tag,omitempty"`
+}
+
+type lifecycleFilterXML struct {
+	Prefix *string               `xml:"Prefix,omitempty"`
+	Tag    *LifecycleTag         `xml:"Tag,omitempty"`
+	And    *lifecycleAndOperator `xml:"And,omitempty"`
+}
+
+func (f LifecycleFilter) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	var filter lifecycleFilterXML
+	switch {
+	case len(f.Tags) == 0:
+		filter.Prefix = &f.Prefix
+	case f.Prefix == "" && len(f.Tags) == 1:
+		filter.Tag = &f.Tags[0]
+	default:
+		filter.And = &lifecycleAndOperator{
+			Prefix: f.Prefix,
+			Tags:   f.Tags,
+		}
+	}
+	return e.EncodeElement(filter, start)
+}
+
+func (f *LifecycleFilter) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var filter lifecycleFilterXML
+	if err := d.DecodeElement(&filter, &start); err != nil {
+		return err
+	}
+	*f = LifecycleFilter{}
+	if filter.Prefix != nil {
+		f.Prefix = *filter.Prefix
+	}
+	if filter.Tag != nil {
+		f.Tags = append(f.Tags, *filter.Tag)
+	}
+	if filter.And != nil {
+		f.Prefix = filter.And.Prefix
+		f.Tags = append(f.Tags, filter.And.Tags...)
+	}
+	return nil
+}
+
+// Storage classes objects can transit to.
+var lifecycleStorageClasses = []string{"STANDARD_IA", "INTELLIGENT_TIERING", "ARCHIVE", "DEEP_ARCHIVE"}
+
+// Validate checks the configuration before sending it, so that mistakes are found with clear messages.
+func (config *LifecycleConfiguration) Validate() error {
+	if len(config.Rules) == 0 {
+		return fmt.Errorf("no rules")
+	}
+	if len(config.Rules) > 1000 {
+		return fmt.Errorf("%d rules, at most 1000 rules are allowed", len(config.Rules))
+	}
+	ids := make(map[string]struct{})
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if rule.ID == "" {
+			return fmt.Errorf("rule %d: id is required", i+1)
+		}
+		if len(rule.ID) > 255 {
+			return fmt.Errorf("rule '%s': id is longer than 255 characters", rule.ID)
+		}
+		if _, ok := ids[rule.ID]; ok {
+			return fmt.Errorf("rule '%s': id is duplicated", rule.ID)
+		}
+		ids[rule.ID] = struct{}{}
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule '%s': %s", rule.ID, err.Error())
+		}
+	}
+	return nil
+}
+
+func (rule *LifecycleRule) validate() error {
+	switch strings.ToLower(rule.Status) {
+	case "enabled":
+		rule.Status = "Enabled"
+	case "disabled":
+		rule.Status = "Disabled"
+	default:
+		return fmt.Errorf("status must be Enabled or Disabled")
+	}
+	if strings.HasPrefix(rule.Filter.Prefix, "/") {
+		return fmt.Errorf("prefix should not start with '/'")
+	}
+	tagKeys := make(map[string]struct{})
+	for _, tag := range rule.Filter.Tags {
+		if tag.Key == "" || len(tag.Key) > 128 {
+			return fmt.Errorf("tag key must have 1 to 128 characters")
+		}
+		if len(tag.Value) > 256 {
+			return fmt.Errorf("tag value of '%s' is longer than 256 characters", tag.Key)
+		}
+		if _, ok := tagKeys[tag.Key]; ok {
+			return fmt.Errorf("tag key '%s' is duplicated", tag.Key)
+		}
+		tagKeys[tag.Key] = struct{}{}
+	}
+	if len(rule.Transitions) == 0 && rule.Expiration == nil && len(rule.NoncurrentVersionTransitions) == 0 &&
+		rule.NoncurrentVersionExpiration == nil && rule.AbortIncompleteMultipartUpload == nil {
+		return fmt.Errorf("no action")
+	}
+	lastDays := 0
+	for i := range rule.Transitions {
+		transition := &rule.Transitions[i]
+		var err error
+		if err = validateDaysOrDate(transition.Days, transition.Date); err != nil {
+			return fmt.Errorf("transition: %s", err.Error())
+		}
+		if transition.StorageClass, err = validateLifecycleStorageClass(transition.StorageClass); err != nil {
+			return fmt.Errorf("transition: %s", err.Error())
+		}
+		if transition.Days != 0 {
+			if transition.Days <= lastDays {
+				return fmt.Errorf("transition: days should increase")
+			}
+			lastDays = transition.Days
+		}
+	}
+	if rule.Expiration != nil {
+		if rule.Expiration.ExpiredObjectDeleteMarker {
+			if rule.Expiration.Days != 0 || rule.Expiration.Date != "" {
+				return fmt.Errorf("expiration: expiredObjectDeleteMarker can not be used with days or date")
+			}
+		} else {
+			if err := validateDaysOrDate(rule.Expiration.Days, rule.Expiration.Date); err != nil {
+				return fmt.Errorf("expiration: %s", err.Error())
+			}
+			if rule.Expiration.Days != 0 && rule.Expiration.Days <= lastDays {
+				return fmt.Errorf("expiration: days should be greater than days of transitions")
+			}
+		}
+	}
+	lastDays = 0
+	for i := range rule.NoncurrentVersionTransitions {
+		transition := &rule.NoncurrentVersionTransitions[i]
+		if transition.NoncurrentDays <= lastDays {
+			return fmt.Errorf("noncurrent version transition: noncurrentDays should be positive and increase")
+		}
+		lastDays = transition.NoncurrentDays
+		var err error
+		if transition.StorageClass, err = validateLifecycleStorageClass(transition.StorageClass); err != nil {
+			return fmt.Errorf("noncurrent version transition: %s", err.Error())
+		}
+	}
+	if rule.NoncurrentVersionExpiration != nil && rule.NoncurrentVersionExpiration.NoncurrentDays <= lastDays {
+		return fmt.Errorf("noncurrent version expiration: noncurrentDays should be positive and greater than days of transitions")
+	}
+	if rule.AbortIncompleteMultipartUpload != nil && rule.AbortIncompleteMultipartUpload.DaysAfterInitiation <= 0 {
+		return fmt.Errorf("abort incomplete multipart upload: daysAfterInitiation should be positive")
+	}
+	return nil
+}
+
+func validateDaysOrDate(days int, date string) error {
+	if days != 0 && date != "" {
+		return fmt.Errorf("days and date can not be used together")
+	}
+	if date != "" {
+		t, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return fmt.Errorf("date should be like 2020-01-02T00:00:00+08:00")
+		}
+		if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 {
+			return fmt.Errorf("date should be at midnight")
+		}
+		return nil
+	}
+	if days <= 0 {
+		return fmt.Errorf("days should be positive")
+	}
+	return nil
+}
+
+func validateLifecycleStorageClass(storageClass string) (string, error) {
+	storageClass = strings.ToUpper(storageClass)
+	for _, class := range lifecycleStorageClasses {
+		if class == storageClass {
+			return storageClass, nil
+		}
+	}
+	return "", fmt.Errorf("storageClass must be one of %s", strings.Join(lifecycleStorageClasses, ", "))
+}
+
+// PutBucketLifecycle validates and sets the lifecycle of the bucket, the old one is replaced.
+func (client *Client) PutBucketLifecycle(config *LifecycleConfiguration) bool {
+	if err := config.Validate(); err != nil {
+		log.Warnf("Invalid lifecycle: %s", err.Error())
+		return false
+	}
+	if err := client.sendBucketRequest(http.MethodPut, "/?lifecycle", config, nil); err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	log.Infof("Lifecycle of %s is set with %d rules", client.Config.Bucket, len(config.Rules))
+	return true
+}
@@ -0,0 +1,163 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestLifecycleValidate(t *testing.T) {
+	valid := func() LifecycleRule {
+		return LifecycleRule{
+			ID:     "rule",
+			Status: "enabled",
+			Filter: LifecycleFilter{Prefix: "logs/"},
+			Transitions: []LifecycleTransition{
+				{Days: 30, StorageClass: "standard_ia"},
+				{Days: 90, StorageClass: "ARCHIVE"},
+			},
+			Expiration: &LifecycleExpiration{Days: 365},
+		}
+	}
+	tests := []struct {
+		name   string
+		modify func(rule *LifecycleRule)
+		err    string
+	}{
+		{"valid", func(rule *LifecycleRule) {}, ""},
+		{"no id", func(rule *LifecycleRule) { rule.ID = "" }, "id is required"},
+		{"long id", func(rule *LifecycleRule) { rule.ID = strings.Repeat("a", 256) }, "longer than 255"},
+		{"bad status", func(rule *LifecycleRule) { rule.Status = "on" }, "status must be"},
+		{"slash prefix", func(rule *LifecycleRule) { rule.Filter.Prefix = "/logs/" }, "should not start with '/'"},
+		{"empty tag key", func(rule *LifecycleRule) {
+			rule.Filter.Tags = []LifecycleTag{{Key: "", Value: "v"}}
+		}, "tag key must have"},
+		{"duplicated tag", func(rule *LifecycleRule) {
+			rule.Filter.Tags = []LifecycleTag{{Key: "k", Value: "1"}, {Key: "k", Value: "2"}}
+		}, "is duplicated"},
+		{"no action", func(rule *LifecycleRule) {
+			rule.Transitions = nil
+			rule.Expiration = nil
+		}, "no action"},
+		{"days and date", func(rule *LifecycleRule) {
+			rule.Transitions[0].Date = "2020-01-02T00:00:00+08:00"
+		}, "can not be used together"},
+		{"date", func(rule *LifecycleRule) {
+			rule.Transitions = []LifecycleTransition{{Date: "2020-01-02T00:00:00+08:00", StorageClass: "ARCHIVE"}}
+		}, ""},
+		{"bad date", func(rule *LifecycleRule) {
+			rule.Transitions = []LifecycleTransition{{Date: "2020-01-02", StorageClass: "ARCHIVE"}}
+		}, "date should be like"},
+		{"date not at midnight", func(rule *LifecycleRule) {
+			rule.Transitions = []LifecycleTransition{{Date: "2020-01-02T01:00:00+08:00", StorageClass: "ARCHIVE"}}
+		}, "at midnight"},
+		{"zero days", func(rule *LifecycleRule) { rule.Transitions[0].Days = 0 }, "days should be positive"},
+		{"bad class", func(rule *LifecycleRule) { rule.Transitions[0].StorageClass = "MAZ_STANDARD" }, "storageClass must be"},
+		{"decreasing days", func(rule *LifecycleRule) { rule.Transitions[1].Days = 30 }, "days should increase"},
+		{"early expiration", func(rule *LifecycleRule) { rule.Expiration.Days = 90 }, "greater than days of transitions"},
+		{"delete marker", func(rule *LifecycleRule) {
+			rule.Expiration = &LifecycleExpiration{ExpiredObjectDeleteMarker: true}
+		}, ""},
+		{"delete marker with days", func(rule *LifecycleRule) {
+			rule.Expiration.ExpiredObjectDeleteMarker = true
+		}, "can not be used with days or date"},
+		{"noncurrent", func(rule *LifecycleRule) {
+			rule.NoncurrentVersionTransitions = []LifecycleNoncurrentVersionTransition{{NoncurrentDays: 30, StorageClass: "ARCHIVE"}}
+			rule.NoncurrentVersionExpiration = &LifecycleNoncurrentVersionExpiration{NoncurrentDays: 60}
+		}, ""},
+		{"early noncurrent expiration", func(rule *LifecycleRule) {
+			rule.NoncurrentVersionTransitions = []LifecycleNoncurrentVersionTransition{{NoncurrentDays: 30, StorageClass: "ARCHIVE"}}
+			rule.NoncurrentVersionExpiration = &LifecycleNoncurrentVersionExpiration{NoncurrentDays: 30}
+		}, "noncurrent version expiration"},
+		{"zero noncurrent days", func(rule *LifecycleRule) {
+			rule.NoncurrentVersionTransitions = []LifecycleNoncurrentVersionTransition{{StorageClass: "ARCHIVE"}}
+		}, "noncurrentDays should be positive"},
+		{"abort", func(rule *LifecycleRule) {
+			rule.AbortIncompleteMultipartUpload = &LifecycleAbortIncompleteMultipartUpload{}
+		}, "daysAfterInitiation should be positive"},
+	}
+	for _, test := range tests {
+		rule := valid()
+		test.modify(&rule)
+		config := LifecycleConfiguration{Rules: []LifecycleRule{rule}}
+		err := config.Validate()
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		case test.err != "" && err == nil:
+			t.Errorf("%s: no error, want %q", test.name, test.err)
+		case test.err != "" && !strings.Contains(err.Error(), test.err):
+			t.Errorf("%s: error %q, want %q", test.name, err.Error(), test.err)
+		}
+	}
+}
+
+func TestLifecycleValidateRules(t *testing.T) {
+	if err := (&LifecycleConfiguration{}).Validate(); err == nil {
+		t.Error("empty configuration is accepted")
+	}
+	rule := LifecycleRule{ID: "a", Status: "Enabled", Expiration: &LifecycleExpiration{Days: 1}}
+	config := LifecycleConfiguration{Rules: []LifecycleRule{rule, rule}}
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "duplicated") {
+		t.Errorf("duplicated id: error %v", err)
+	}
+	// Validate normalizes status and storage classes.
+	config = LifecycleConfiguration{Rules: []LifecycleRule{{
+		ID:          "a",
+		Status:      "ENABLED",
+		Transitions: []LifecycleTransition{{Days: 1, StorageClass: "archive"}},
+	}}}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if config.Rules[0].Status != "Enabled" || config.Rules[0].Transitions[0].StorageClass != "ARCHIVE" {
+		t.Errorf("not normalized: %+v", config.Rules[0])
+	}
+}
+
+func TestLifecycleFilterXML(t *testing.T) {
+	tests := []struct {
+		filter LifecycleFilter
+		xml    string
+	}{
+		{LifecycleFilter{}, "<Filter><Prefix></Prefix></Filter>"},
+		{LifecycleFilter{Prefix: "a/"}, "<Filter><Prefix>a/</Prefix></Filter>"},
+		{LifecycleFilter{Tags: []LifecycleTag{{Key: "k", Value: "v"}}},
+			"<Filter><Tag><Key>k</Key><Value>v</Value></Tag></Filter>"},
+		{LifecycleFilter{Prefix: "a/", Tags: []LifecycleTag{{Key: "k", Value: "v"}}},
+			"<Filter><And><Prefix>a/</Prefix><Tag><Key>k</Key><Value>v</Value></Tag></And></Filter>"},
+	}
+	for _, test := range tests {
+		var buf strings.Builder
+		start := xml.StartElement{Name: xml.Name{Local: "Filter"}}
+		if err := xml.NewEncoder(&buf).EncodeElement(test.filter, start); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != test.xml {
+			t.Errorf("marshal %+v = %s, want %s", test.filter, got, test.xml)
+		}
+		var filter LifecycleFilter
+		if err := xml.Unmarshal([]byte(test.xml), &filter); err != nil {
+			t.Fatal(err)
+		}
+		if filter.Prefix != test.filter.Prefix || len(filter.Tags) != len(test.filter.Tags) {
+			t.Errorf("unmarshal %s = %+v, want %+v", test.xml, filter, test.filter)
+		}
+	}
+}
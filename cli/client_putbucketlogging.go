@@ -0,0 +1,67 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+var bucketNamePattern = regexp.MustCompile(`^[a-z0-9-]+-[0-9]+$`)
+
+// BucketLogging tells where the access logs of a bucket are written to.
+type BucketLogging struct {
+	// TargetBucket like examplebucket-1250000000, which must be in the same region as the bucket.
+	TargetBucket string `json:"targetBucket" yaml:"targetBucket"`
+	TargetPrefix string `json:"targetPrefix,omitempty" yaml:"targetPrefix,omitempty"`
+}
+
+// Validate checks the configuration before sending it, so that mistakes are found with clear messages.
+func (config *BucketLogging) Validate() error {
+	if !bucketNamePattern.MatchString(config.TargetBucket) {
+		return fmt.Errorf("target bucket '%s' should be like examplebucket-1250000000", config.TargetBucket)
+	}
+	if strings.HasPrefix(config.TargetPrefix, "/") {
+		return fmt.Errorf("target prefix should not start with '/'")
+	}
+	return nil
+}
+
+// PutBucketLogging validates and enables the access logging of the bucket.
+func (client *Client) PutBucketLogging(config *BucketLogging) bool {
+	if err := config.Validate(); err != nil {
+		log.Warnf("Invalid logging: %s", err.Error())
+		return false
+	}
+	_, err := client.Client.Bucket.PutLogging(context.Background(), &cos.BucketPutLoggingOptions{
+		LoggingEnabled: &cos.BucketLoggingEnabled{
+			TargetBucket: config.TargetBucket,
+			TargetPrefix: config.TargetPrefix,
+		},
+	})
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	log.Infof("Access logs of %s are written to cos://%s/%s", client.Config.Bucket, config.TargetBucket, config.TargetPrefix)
+	return true
+}
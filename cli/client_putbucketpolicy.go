@@ -0,0 +1,189 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// PolicyDocument is the policy of a bucket, read from JSON or YAML files.
+// Unlike cos.BucketPutPolicyOptions, a single string is accepted where a list is expected,
+// as in the documents generated by the console.
+type PolicyDocument struct {
+	Version   string            `json:"version,omitempty" yaml:"version,omitempty"`
+	Principal PolicyPrincipal   `json:"principal,omitempty" yaml:"principal,omitempty"`
+	Statement []PolicyStatement `json:"statement" yaml:"statement"`
+}
+
+type PolicyStatement struct {
+	Principal PolicyPrincipal                    `json:"principal,omitempty" yaml:"principal,omitempty"`
+	Effect    string                             `json:"effect" yaml:"effect"`
+	Action    PolicyValues                       `json:"action" yaml:"action"`
+	Resource  PolicyValues                       `json:"resource" yaml:"resource"`
+	Condition map[string]map[string]PolicyValues `json:"condition,omitempty" yaml:"condition,omitempty"`
+}
+
+// PolicyPrincipal maps the kind of principals (always "qcs") to them.
+type PolicyPrincipal map[string]PolicyValues
+
+// PolicyValues is a list of strings, which can be written as a single string or number too.
+type PolicyValues []string
+
+func (v *PolicyValues) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return v.set(value)
+}
+
+func (v *PolicyValues) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	return v.set(value)
+}
+
+func (v *PolicyValues) set(value interface{}) error {
+	*v = nil
+	switch value := value.(type) {
+	case nil:
+	case []interface{}:
+		for _, item := range value {
+			switch item.(type) {
+			case []interface{}, map[string]interface{}, map[interface{}]interface{}:
+				return fmt.Errorf("nested value %v", item)
+			}
+			*v = append(*v, fmt.Sprint(item))
+		}
+	case map[string]interface{}, map[interface{}]interface{}:
+		return fmt.Errorf("unexpected object %v", value)
+	default:
+		*v = append(*v, fmt.Sprint(value))
+	}
+	return nil
+}
+
+// Condition operators supported by COS.
+var policyConditionOperators = []string{
+	"string_equal", "string_not_equal", "string_like", "string_not_like",
+	"ip_equal", "ip_not_equal",
+	"numeric_equal", "numeric_not_equal", "numeric_less_than", "numeric_less_than_equal",
+	"numeric_greater_than", "numeric_greater_than_equal",
+	"bool_equal",
+}
+
+// Validate checks the policy before sending it, so that mistakes are found with clear messages.
+func (policy *PolicyDocument) Validate() error {
+	if len(policy.Statement) == 0 {
+		return fmt.Errorf("no statement")
+	}
+	for i := range policy.Statement {
+		statement := &policy.Statement[i]
+		switch strings.ToLower(statement.Effect) {
+		case "allow":
+			statement.Effect = "allow"
+		case "deny":
+			statement.Effect = "deny"
+		default:
+			return fmt.Errorf("statement %d: effect must be allow or deny", i+1)
+		}
+		if len(statement.Principal) == 0 && len(policy.Principal) == 0 {
+			return fmt.Errorf("statement %d: principal is required", i+1)
+		}
+		if len(statement.Action) == 0 {
+			return fmt.Errorf("statement %d: action is required", i+1)
+		}
+		for _, action := range statement.Action {
+			if action != "*" && !strings.HasPrefix(action, "name/cos:") {
+				return fmt.Errorf("statement %d: action '%s' should be like name/cos:GetObject", i+1, action)
+			}
+		}
+		if len(statement.Resource) == 0 {
+			return fmt.Errorf("statement %d: resource is required", i+1)
+		}
+		for _, resource := range statement.Resource {
+			if resource != "*" && !strings.HasPrefix(resource, "qcs::cos:") {
+				return fmt.Errorf("statement %d: resource '%s' should be like qcs::cos:REGION:uid/APPID:BUCKET/KEY", i+1, resource)
+			}
+		}
+		for operator := range statement.Condition {
+			if !containsString(policyConditionOperators, operator) {
+				return fmt.Errorf("statement %d: unknown condition operator '%s'", i+1, operator)
+			}
+		}
+	}
+	return nil
+}
+
+func (policy *PolicyDocument) toOptions() *cos.BucketPutPolicyOptions {
+	options := &cos.BucketPutPolicyOptions{
+		Version:   policy.Version,
+		Principal: policy.Principal.toMap(),
+	}
+	for _, statement := range policy.Statement {
+		s := cos.BucketStatement{
+			Principal: statement.Principal.toMap(),
+			Effect:    statement.Effect,
+			Action:    statement.Action,
+			Resource:  statement.Resource,
+		}
+		if len(statement.Condition) != 0 {
+			s.Condition = make(map[string]map[string]interface{})
+			for operator, conditions := range statement.Condition {
+				s.Condition[operator] = make(map[string]interface{})
+				for key, values := range conditions {
+					s.Condition[operator][key] = []string(values)
+				}
+			}
+		}
+		options.Statement = append(options.Statement, s)
+	}
+	return options
+}
+
+func (p PolicyPrincipal) toMap() map[string][]string {
+	if len(p) == 0 {
+		return nil
+	}
+	m := make(map[string][]string)
+	for kind, principals := range p {
+		m[kind] = principals
+	}
+	return m
+}
+
+// PutBucketPolicy validates and sets the policy of the bucket, the old one is replaced.
+func (client *Client) PutBucketPolicy(policy *PolicyDocument) bool {
+	if err := policy.Validate(); err != nil {
+		log.Warnf("Invalid policy: %s", err.Error())
+		return false
+	}
+	if _, err := client.Client.Bucket.PutPolicy(context.Background(), policy.toOptions()); err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	log.Infof("Policy of %s is set with %d statements", client.Config.Bucket, len(policy.Statement))
+	return true
+}
@@ -0,0 +1,129 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ReplicationConfiguration is the cross-region replication of a bucket, read from JSON or YAML
+// files and sent in XML. Versioning must be enabled on both buckets.
+type ReplicationConfiguration struct {
+	XMLName xml.Name `xml:"ReplicationConfiguration" json:"-" yaml:"-"`
+	// Role like qcs::cam::uin/100000000001:uin/100000000001
+	Role  string            `xml:"Role" json:"role" yaml:"role"`
+	Rules []ReplicationRule `xml:"Rule" json:"rules" yaml:"rules"`
+}
+
+type ReplicationRule struct {
+	ID          string                 `xml:"ID,omitempty" json:"id,omitempty" yaml:"id,omitempty"`
+	Status      string                 `xml:"Status" json:"status" yaml:"status"`
+	Prefix      string                 `xml:"Prefix" json:"prefix" yaml:"prefix"`
+	Destination ReplicationDestination `xml:"Destination" json:"destination" yaml:"destination"`
+}
+
+type ReplicationDestination struct {
+	// Bucket like qcs::cos:ap-shanghai::examplebucket-1250000000
+	Bucket       string `xml:"Bucket" json:"bucket" yaml:"bucket"`
+	StorageClass string `xml:"StorageClass,omitempty" json:"storageClass,omitempty" yaml:"storageClass,omitempty"`
+}
+
+// Validate checks the configuration before sending it, so that mistakes are found with clear messages.
+func (config *ReplicationConfiguration) Validate() error {
+	if !strings.HasPrefix(config.Role, "qcs::cam::") {
+		return fmt.Errorf("role should be like qcs::cam::uin/100000000001:uin/100000000001")
+	}
+	if len(config.Rules) == 0 {
+		return fmt.Errorf("no rules")
+	}
+	if len(config.Rules) > 1000 {
+		return fmt.Errorf("%d rules, at most 1000 rules are allowed", len(config.Rules))
+	}
+	ids := make(map[string]struct{})
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		name := fmt.Sprintf("rule %d", i+1)
+		if rule.ID != "" {
+			name = fmt.Sprintf("rule '%s'", rule.ID)
+			if _, ok := ids[rule.ID]; ok {
+				return fmt.Errorf("%s: id is duplicated", name)
+			}
+			ids[rule.ID] = struct{}{}
+		}
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("%s: %s", name, err.Error())
+		}
+		for _, other := range config.Rules[:i] {
+			if strings.HasPrefix(rule.Prefix, other.Prefix) || strings.HasPrefix(other.Prefix, rule.Prefix) {
+				return fmt.Errorf("%s: prefix '%s' overlaps with prefix '%s'", name, rule.Prefix, other.Prefix)
+			}
+		}
+	}
+	return nil
+}
+
+func (rule *ReplicationRule) validate() error {
+	switch strings.ToLower(rule.Status) {
+	case "enabled":
+		rule.Status = "Enabled"
+	case "disabled":
+		rule.Status = "Disabled"
+	default:
+		return fmt.Errorf("status must be Enabled or Disabled")
+	}
+	if strings.HasPrefix(rule.Prefix, "/") {
+		return fmt.Errorf("prefix should not start with '/'")
+	}
+	if _, _, err := parseReplicationBucket(rule.Destination.Bucket); err != nil {
+		return err
+	}
+	if rule.Destination.StorageClass != "" {
+		rule.Destination.StorageClass = strings.ToUpper(rule.Destination.StorageClass)
+		if !containsString(StorageClasses, rule.Destination.StorageClass) {
+			return fmt.Errorf("storageClass must be one of %s", strings.Join(StorageClasses, ", "))
+		}
+	}
+	return nil
+}
+
+// Parse the destination bucket like qcs::cos:ap-shanghai::examplebucket-1250000000.
+func parseReplicationBucket(name string) (region string, bucket string, err error) {
+	parts := strings.Split(name, ":")
+	if len(parts) != 6 || parts[0] != "qcs" || parts[2] != "cos" || parts[3] == "" || parts[5] == "" {
+		return "", "", fmt.Errorf("destination bucket '%s' should be like qcs::cos:ap-shanghai::examplebucket-1250000000", name)
+	}
+	return parts[3], parts[5], nil
+}
+
+// PutBucketReplication validates and sets the replication of the bucket, the old one is replaced.
+func (client *Client) PutBucketReplication(config *ReplicationConfiguration) bool {
+	if err := config.Validate(); err != nil {
+		log.Warnf("Invalid replication: %s", err.Error())
+		return false
+	}
+	if err := client.sendBucketRequest(http.MethodPut, "/?replication", config, nil); err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	log.Infof("Replication of %s is set with %d rules", client.Config.Bucket, len(config.Rules))
+	return true
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// WebsiteConfiguration is the static website configuration of a bucket, read from JSON or YAML files
+// and sent in XML.
+type WebsiteConfiguration struct {
+	XMLName       xml.Name `xml:"WebsiteConfiguration" json:"-" yaml:"-"`
+	IndexDocument string   `xml:"IndexDocument>Suffix" json:"indexDocument" yaml:"indexDocument"`
+	ErrorDocument string   `xml:"ErrorDocument>Key,omitempty" json:"errorDocument,omitempty" yaml:"errorDocument,omitempty"`
+	// Only https is supported, to redirect all http requests to https.
+	RedirectAllRequestsTo string               `xml:"RedirectAllRequestsTo>Protocol,omitempty" json:"redirectAllRequestsTo,omitempty" yaml:"redirectAllRequestsTo,omitempty"`
+	RoutingRules          []WebsiteRoutingRule `xml:"RoutingRules>RoutingRule,omitempty" json:"routingRules,omitempty" yaml:"routingRules,omitempty"`
+}
+
+type WebsiteRoutingRule struct {
+	Condition WebsiteCondition `xml:"Condition" json:"condition" yaml:"condition"`
+	Redirect  WebsiteRedirect  `xml:"Redirect" json:"redirect" yaml:"redirect"`
+}
+
+// WebsiteCondition matches requests by the error code or the key prefix, only one of them can be set.
+type WebsiteCondition struct {
+	HTTPErrorCode int    `xml:"HttpErrorCodeReturnedEquals,omitempty" json:"httpErrorCodeReturnedEquals,omitempty" yaml:"httpErrorCodeReturnedEquals,omitempty"`
+	KeyPrefix     string `xml:"KeyPrefixEquals,omitempty" json:"keyPrefixEquals,omitempty" yaml:"keyPrefixEquals,omitempty"`
+}
+
+// WebsiteRedirect replaces the whole key or the prefix matched by the condition.
+type WebsiteRedirect struct {
+	Protocol             string `xml:"Protocol,omitempty" json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	ReplaceKeyWith       string `xml:"ReplaceKeyWith,omitempty" json:"replaceKeyWith,omitempty" yaml:"replaceKeyWith,omitempty"`
+	ReplaceKeyPrefixWith string `xml:"ReplaceKeyPrefixWith,omitempty" json:"replaceKeyPrefixWith,omitempty" yaml:"replaceKeyPrefixWith,omitempty"`
+}
+
+// Validate checks the configuration before sending it, so that mistakes are found with clear messages.
+func (config *WebsiteConfiguration) Validate() error {
+	if config.IndexDocument == "" {
+		return fmt.Errorf("indexDocument is required")
+	}
+	if strings.Contains(config.IndexDocument, "/") {
+		return fmt.Errorf("indexDocument should be a file name like index.html")
+	}
+	if strings.HasPrefix(config.ErrorDocument, "/") {
+		return fmt.Errorf("errorDocument should not start with '/'")
+	}
+	if config.RedirectAllRequestsTo != "" {
+		if !strings.EqualFold(config.RedirectAllRequestsTo, "https") {
+			return fmt.Errorf("redirectAllRequestsTo can only be https")
+		}
+		config.RedirectAllRequestsTo = "https"
+	}
+	if len(config.RoutingRules) > 100 {
+		return fmt.Errorf("%d routing rules, at most 100 rules are allowed", len(config.RoutingRules))
+	}
+	for i := range config.RoutingRules {
+		if err := config.RoutingRules[i].validate(); err != nil {
+			return fmt.Errorf("routing rule %d: %s", i+1, err.Error())
+		}
+	}
+	return nil
+}
+
+func (rule *WebsiteRoutingRule) validate() error {
+	condition, redirect := &rule.Condition, &rule.Redirect
+	switch {
+	case condition.HTTPErrorCode == 0 && condition.KeyPrefix == "":
+		return fmt.Errorf("condition needs httpErrorCodeReturnedEquals or keyPrefixEquals")
+	case condition.HTTPErrorCode != 0 && condition.KeyPrefix != "":
+		return fmt.Errorf("httpErrorCodeReturnedEquals and keyPrefixEquals can not be used together")
+	case condition.HTTPErrorCode != 0 && (condition.HTTPErrorCode < 400 || condition.HTTPErrorCode > 499):
+		return fmt.Errorf("httpErrorCodeReturnedEquals should be a 4xx code")
+	case strings.HasPrefix(condition.KeyPrefix, "/"):
+		return fmt.Errorf("keyPrefixEquals should not start with '/'")
+	}
+	switch {
+	case redirect.ReplaceKeyWith == "" && redirect.ReplaceKeyPrefixWith == "":
+		return fmt.Errorf("redirect needs replaceKeyWith or replaceKeyPrefixWith")
+	case redirect.ReplaceKeyWith != "" && redirect.ReplaceKeyPrefixWith != "":
+		return fmt.Errorf("replaceKeyWith and replaceKeyPrefixWith can not be used together")
+	case redirect.ReplaceKeyPrefixWith != "" && condition.KeyPrefix == "":
+		return fmt.Errorf("replaceKeyPrefixWith can only be used with keyPrefixEquals")
+	}
+	if redirect.Protocol != "" {
+		redirect.Protocol = strings.ToLower(redirect.Protocol)
+		if redirect.Protocol != "http" && redirect.Protocol != "https" {
+			return fmt.Errorf("protocol should be http or https")
+		}
+	}
+	return nil
+}
+
+// PutBucketWebsite validates and sets the static website configuration of the bucket.
+func (client *Client) PutBucketWebsite(config *WebsiteConfiguration) bool {
+	if err := config.Validate(); err != nil {
+		log.Warnf("Invalid website configuration: %s", err.Error())
+		return false
+	}
+	if err := client.sendBucketRequest(http.MethodPut, "/?website", config, nil); err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	log.Infof("Website of %s is set", client.Config.Bucket)
+	return true
+}
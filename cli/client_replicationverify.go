@@ -0,0 +1,222 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+type ReplicationVerifyOption struct {
+	// DestPath overrides the destination buckets of the replication rules,
+	// like bucket-appid.cos.ap-shanghai.myqcloud.com
+	DestPath string
+	// AllVersions compares every version instead of the latest ones.
+	AllVersions bool
+}
+
+// A version of an object, compared between the source and destination buckets.
+type replicaVersion struct {
+	VersionID    string
+	Size         int64
+	ETag         string
+	LastModified string
+	DeleteMarker bool
+}
+
+type replicationVerifyResult struct {
+	checked, synced, missing, mismatched, extra, uncovered int
+	maxLag                                                 time.Duration
+}
+
+// ReplicationVerify compares the objects with prefix cosPath with their replicas in the destination
+// buckets of the replication rules, by keys, sizes, ETags and version IDs, and reports missing
+// objects and how long the oldest unreplicated change has been waiting.
+// If all objects are replicated, return 0; if some are not, return 1; if failed, return -1
+func (client *Client) ReplicationVerify(cosPath string, options *ReplicationVerifyOption) int {
+	// destination path like bucket-appid.cos.ap-shanghai.myqcloud.com/ => rule prefixes replicated to it
+	destinations := make(map[string][]string)
+	if options.DestPath != "" {
+		destPath := strings.TrimSuffix(options.DestPath, "/") + "/"
+		destinations[destPath] = []string{""}
+	} else {
+		config, configured, err := client.getBucketReplication()
+		if err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+		if !configured {
+			log.Warn("Replication is not configured, use --dest to specify the destination bucket")
+			return -1
+		}
+		for _, rule := range config.Rules {
+			if rule.Status != "Enabled" {
+				continue
+			}
+			if !strings.HasPrefix(rule.Prefix, cosPath) && !strings.HasPrefix(cosPath, rule.Prefix) {
+				continue
+			}
+			region, bucket, err := parseReplicationBucket(rule.Destination.Bucket)
+			if err != nil {
+				log.Warn(err.Error())
+				return -1
+			}
+			destPath := bucket + ".cos." + region + ".myqcloud.com/"
+			destinations[destPath] = append(destinations[destPath], rule.Prefix)
+		}
+	}
+
+	sourceVersions, err := client.listReplicaVersions(cosPath, options.AllVersions)
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	result := &replicationVerifyResult{}
+	covered := make(map[string]struct{})
+	for destPath, prefixes := range destinations {
+		destClient, err := client.sourcePathToClient(destPath)
+		if err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+		log.Infof("Compare cos://%s/%s with cos://%s/%s", client.Config.Bucket, cosPath, destClient.Config.Bucket, cosPath)
+		destVersions, err := destClient.listReplicaVersions(cosPath, options.AllVersions)
+		if err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+		for key, versions := range sourceVersions {
+			if !hasAnyPrefix(key, prefixes) {
+				continue
+			}
+			covered[key] = struct{}{}
+			result.compare(key, versions, destVersions[key], destClient.Config.Bucket)
+		}
+		for key := range destVersions {
+			if _, ok := sourceVersions[key]; !ok && hasAnyPrefix(key, prefixes) {
+				log.Debugf("Extra cos://%s/%s", destClient.Config.Bucket, key)
+				result.extra++
+			}
+		}
+	}
+	for key := range sourceVersions {
+		if _, ok := covered[key]; !ok {
+			log.Debugf("Skip %s, not replicated by any rule", key)
+			result.uncovered++
+		}
+	}
+
+	log.Infof("%d versions checked, %d in sync, %d missing, %d mismatched, %d extra in destination, %d objects not covered by rules",
+		result.checked, result.synced, result.missing, result.mismatched, result.extra, result.uncovered)
+	if result.missing != 0 || result.mismatched != 0 {
+		log.Infof("The oldest unreplicated change was made %s ago", result.maxLag.Round(time.Second))
+		return 1
+	}
+	return 0
+}
+
+func (result *replicationVerifyResult) compare(key string, source []replicaVersion, dest []replicaVersion, destBucket string) {
+	destByID := make(map[string]replicaVersion)
+	for _, version := range dest {
+		destByID[version.VersionID] = version
+	}
+	for _, version := range source {
+		result.checked++
+		replica, ok := destByID[version.VersionID]
+		switch {
+		case !ok:
+			log.Warnf("Missing %s (version %s) in %s", key, version.VersionID, destBucket)
+			result.missing++
+		case replica.DeleteMarker != version.DeleteMarker || replica.Size != version.Size || replica.ETag != version.ETag:
+			log.Warnf("Mismatch %s (version %s): size %d, ETag %s in source; size %d, ETag %s in %s",
+				key, version.VersionID, version.Size, version.ETag, replica.Size, replica.ETag, destBucket)
+			result.mismatched++
+		default:
+			result.synced++
+			continue
+		}
+		if modified, err := time.Parse(time.RFC3339, version.LastModified); err == nil {
+			if lag := time.Since(modified); lag > result.maxLag {
+				result.maxLag = lag
+			}
+		}
+	}
+}
+
+// List the versions of the objects with prefix cosPath, only the latest ones if not allVersions.
+func (client *Client) listReplicaVersions(cosPath string, allVersions bool) (map[string][]replicaVersion, error) {
+	versions := make(map[string][]replicaVersion)
+	add := func(key string, isLatest bool, version replicaVersion) {
+		if allVersions || isLatest {
+			versions[key] = append(versions[key], version)
+		}
+	}
+	keyMarker, versionIDMarker := "", ""
+	isTruncated := true
+	for isTruncated {
+		var result *cos.BucketGetObjectVersionsResult
+		for i := 0; i <= client.Config.RetryTimes; i++ {
+			var err error
+			result, _, err = client.Client.Bucket.GetObjectVersions(context.Background(), &cos.BucketGetObjectVersionsOptions{
+				Prefix:          cosPath,
+				KeyMarker:       keyMarker,
+				VersionIdMarker: versionIDMarker,
+				MaxKeys:         1000,
+			})
+			if err == nil {
+				break
+			}
+			if i >= client.Config.RetryTimes {
+				return nil, err
+			}
+			log.Warn(err.Error())
+			time.Sleep((1 << i) * time.Second)
+		}
+		isTruncated = result.IsTruncated
+		keyMarker = result.NextKeyMarker
+		versionIDMarker = result.NextVersionIdMarker
+		for _, version := range result.Version {
+			add(version.Key, version.IsLatest, replicaVersion{
+				VersionID:    version.VersionId,
+				Size:         int64(version.Size),
+				ETag:         version.ETag,
+				LastModified: version.LastModified,
+			})
+		}
+		for _, marker := range result.DeleteMarker {
+			add(marker.Key, marker.IsLatest, replicaVersion{
+				VersionID:    marker.VersionId,
+				LastModified: marker.LastModified,
+				DeleteMarker: true,
+			})
+		}
+	}
+	return versions, nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -21,6 +21,8 @@ import (
 	"io/ioutil"
 	"time"
 
+	"github.com/huanght1997/cosutil/coshelper"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/tencentyun/cos-go-sdk-v5"
 )
@@ -32,8 +34,11 @@ const (
 )
 
 type RestoreOption struct {
-	Day  int
-	Tier int
+	Day    int
+	Tier   int
+	Filter *coshelper.Filter
+	// Inventory lists the objects instead of Bucket.Get if not nil.
+	Inventory *Inventory
 }
 
 func (client *Client) RestoreFolder(cosPath string, options *RestoreOption) int {
@@ -44,7 +49,7 @@ func (client *Client) RestoreFolder(cosPath string, options *RestoreOption) int
 	restoreResult := make(chan int, client.Config.MaxThread)
 	for isTruncated {
 		for i := 0; i <= client.Config.RetryTimes; i++ {
-			result, _, err := client.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+			result, err := client.getObjects(options.Inventory, &cos.BucketGetOptions{
 				Prefix:  cosPath,
 				Marker:  nextMarker,
 				MaxKeys: 1000,
@@ -54,14 +59,20 @@ func (client *Client) RestoreFolder(cosPath string, options *RestoreOption) int
 			} else {
 				isTruncated = result.IsTruncated
 				nextMarker = result.NextMarker
+				tasks := 0
 				for _, file := range result.Contents {
+					if options.Filter.ExcludedFile(relativeKey(file.Key, cosPath), objectAttr(file.Size, file.LastModified, file.StorageClass)) {
+						log.Debugf("Skip %s", file.Key)
+						continue
+					}
+					tasks++
 					go func(path string) {
 						restoring <- struct{}{}
 						restoreResult <- client.RestoreFile(path, options)
 						<-restoring
 					}(file.Key)
 				}
-				for j := 0; j < len(result.Contents); j++ {
+				for j := 0; j < tasks; j++ {
 					v := <-restoreResult
 					switch v {
 					case 0:
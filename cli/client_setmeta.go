@@ -0,0 +1,187 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// MetaHeaders are the standard headers kept as metadata of objects, besides x-cos-meta-*.
+var MetaHeaders = []string{
+	"Cache-Control",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Type",
+	"Expires",
+}
+
+type SetMetaOption struct {
+	// Headers are set to the values, or removed if the values are empty.
+	Headers map[string]string
+	// Meta are set as x-cos-meta-* headers, and RemoveMeta are removed.
+	Meta       map[string]string
+	RemoveMeta []string
+	Filter     *coshelper.Filter
+	DryRun     bool
+}
+
+// SetMetaFolder changes the metadata of the objects with prefix cosPath.
+func (client *Client) SetMetaFolder(cosPath string, options *SetMetaOption) int {
+	successNum, skipNum, failNum := 0, 0, 0
+	nextMarker := ""
+	isTruncated := true
+	for isTruncated {
+		var result *cos.BucketGetResult
+		for i := 0; i <= client.Config.RetryTimes; i++ {
+			var err error
+			result, _, err = client.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+				Prefix:  cosPath,
+				Marker:  nextMarker,
+				MaxKeys: 1000,
+			})
+			if err == nil {
+				break
+			}
+			log.Warn(err.Error())
+			if i >= client.Config.RetryTimes {
+				return -1
+			}
+			time.Sleep((1 << i) * time.Second)
+		}
+		isTruncated = result.IsTruncated
+		nextMarker = result.NextMarker
+		setting := make(chan struct{}, client.Config.MaxThread)
+		setResult := make(chan int, client.Config.MaxThread)
+		tasks := 0
+		for _, file := range result.Contents {
+			// directory placeholders
+			if strings.HasSuffix(file.Key, "/") {
+				continue
+			}
+			if options.Filter.ExcludedFile(relativeKey(file.Key, cosPath), objectAttr(file.Size, file.LastModified, file.StorageClass)) {
+				log.Debugf("Skip %s", file.Key)
+				skipNum++
+				continue
+			}
+			tasks++
+			go func(key string) {
+				setting <- struct{}{}
+				setResult <- client.SetMetaFile(key, options)
+				<-setting
+			}(file.Key)
+		}
+		for j := 0; j < tasks; j++ {
+			switch <-setResult {
+			case 0:
+				successNum++
+			case -2:
+				skipNum++
+			default:
+				failNum++
+			}
+		}
+	}
+	if options.DryRun {
+		log.Infof("%d files would be changed, %d files skipped", successNum, skipNum)
+		return 0
+	}
+	log.Infof("%d files changed, %d files skipped, %d files failed", successNum, skipNum, failNum)
+	if failNum != 0 {
+		return -1
+	}
+	return 0
+}
+
+// SetMetaFile changes the metadata of the object by copying it to itself with the Replaced directive.
+// Metadata not mentioned, the storage class, tags, ACL and encryption are kept. Objects larger than 5GB are
+// copied in parts. Return -2 if nothing changes.
+func (client *Client) SetMetaFile(cosPath string, options *SetMetaOption) int {
+	resp, err := client.Client.Object.Head(context.Background(), cosPath, nil)
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	meta := objectMetaHeaders(resp.Header)
+	newMeta := meta.Clone()
+	for key, value := range options.Headers {
+		if value == "" {
+			newMeta.Del(key)
+		} else {
+			newMeta.Set(key, value)
+		}
+	}
+	for key, value := range options.Meta {
+		newMeta.Set("x-cos-meta-"+key, value)
+	}
+	for _, key := range options.RemoveMeta {
+		newMeta.Del("x-cos-meta-" + key)
+	}
+	changes := diffMeta(meta, newMeta)
+	if len(changes) == 0 {
+		log.Debugf("Skip cos://%s/%s, metadata is not changed", client.Config.Bucket, cosPath)
+		return -2
+	}
+	log.Infof("Set metadata of cos://%s/%s: %s", client.Config.Bucket, cosPath, strings.Join(changes, ", "))
+	if options.DryRun {
+		return 0
+	}
+	copyOptions := &CopyOption{
+		Force:        true,
+		Directive:    "Replaced",
+		SkipMd5:      true,
+		StorageClass: resp.Header.Get("x-cos-storage-class"),
+	}
+	return client.copyInPlace(cosPath, resp.Header, &newMeta, copyOptions)
+}
+
+// Describe the changes of metadata like "Content-Type: text/plain => text/html".
+func diffMeta(oldMeta http.Header, newMeta http.Header) []string {
+	keys := make([]string, 0)
+	for key := range oldMeta {
+		keys = append(keys, key)
+	}
+	for key := range newMeta {
+		if _, ok := oldMeta[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	changes := make([]string, 0)
+	for _, key := range keys {
+		oldValue, newValue := oldMeta.Get(key), newMeta.Get(key)
+		switch {
+		case oldValue == newValue:
+		case newValue == "":
+			changes = append(changes, key+": "+oldValue+" => (removed)")
+		case oldValue == "":
+			changes = append(changes, key+": (added) "+newValue)
+		default:
+			changes = append(changes, key+": "+oldValue+" => "+newValue)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,263 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// At most 50 tags for a bucket, and 10 tags for an object.
+const (
+	maxBucketTags = 50
+	maxObjectTags = 10
+)
+
+type TaggingOption struct {
+	Filter *coshelper.Filter
+}
+
+func (client *Client) PutBucketTagging(tags []coshelper.Tag) bool {
+	if len(tags) > maxBucketTags {
+		log.Warnf("%d tags, at most %d tags are allowed for a bucket", len(tags), maxBucketTags)
+		return false
+	}
+	opt := &cos.BucketPutTaggingOptions{}
+	for _, tag := range tags {
+		opt.TagSet = append(opt.TagSet, cos.BucketTaggingTag{Key: tag.Key, Value: tag.Value})
+	}
+	if _, err := client.Client.Bucket.PutTagging(context.Background(), opt); err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	log.Infof("Tags of %s are set: %s", client.Config.Bucket, coshelper.FormatTags(tags))
+	return true
+}
+
+func (client *Client) GetBucketTagging() bool {
+	tags, err := client.bucketTags()
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	if len(tags) == 0 {
+		log.Info("Not configured")
+		return true
+	}
+	printTags(tags)
+	return true
+}
+
+// Tags of the bucket, empty if there is none.
+func (client *Client) bucketTags() ([]coshelper.Tag, error) {
+	result, _, err := client.Client.Bucket.GetTagging(context.Background())
+	if err != nil {
+		if cos.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	tags := make([]coshelper.Tag, 0, len(result.TagSet))
+	for _, tag := range result.TagSet {
+		tags = append(tags, coshelper.Tag{Key: tag.Key, Value: tag.Value})
+	}
+	return tags, nil
+}
+
+func (client *Client) DeleteBucketTagging() bool {
+	if _, err := client.Client.Bucket.DeleteTagging(context.Background()); err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	log.Infof("Tags of %s are deleted", client.Config.Bucket)
+	return true
+}
+
+func (client *Client) GetObjectTagging(cosPath string) bool {
+	tags, err := client.objectTags(cosPath)
+	if err != nil {
+		log.Warn(err.Error())
+		return false
+	}
+	if len(tags) == 0 {
+		log.Infof("cos://%s/%s has no tags", client.Config.Bucket, cosPath)
+		return true
+	}
+	printTags(tags)
+	return true
+}
+
+// PutObjectTagging replaces the tags of an object.
+// If successfully, return 0; if failed, return -1
+func (client *Client) PutObjectTagging(cosPath string, tags []coshelper.Tag) int {
+	if err := CheckObjectTags(tags); err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	opt := &cos.ObjectPutTaggingOptions{}
+	for _, tag := range tags {
+		opt.TagSet = append(opt.TagSet, cos.ObjectTaggingTag{Key: tag.Key, Value: tag.Value})
+	}
+	log.Infof("Tag cos://%s/%s: %s", client.Config.Bucket, cosPath, coshelper.FormatTags(tags))
+	return client.retryTagging(func() error {
+		_, err := client.Client.Object.PutTagging(context.Background(), cosPath, opt)
+		return err
+	})
+}
+
+// DeleteObjectTagging removes all tags of an object.
+// If successfully, return 0; if failed, return -1
+func (client *Client) DeleteObjectTagging(cosPath string) int {
+	log.Infof("Delete tags of cos://%s/%s", client.Config.Bucket, cosPath)
+	return client.retryTagging(func() error {
+		_, err := client.Client.Object.DeleteTagging(context.Background(), cosPath)
+		return err
+	})
+}
+
+// PutObjectTaggingFolder replaces the tags of all objects with prefix cosPath.
+func (client *Client) PutObjectTaggingFolder(cosPath string, tags []coshelper.Tag, options *TaggingOption) int {
+	if err := CheckObjectTags(tags); err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	return client.taggingFolder(cosPath, "tagged", options, func(key string) int {
+		return client.PutObjectTagging(key, tags)
+	})
+}
+
+// DeleteObjectTaggingFolder removes the tags of all objects with prefix cosPath.
+func (client *Client) DeleteObjectTaggingFolder(cosPath string, options *TaggingOption) int {
+	return client.taggingFolder(cosPath, "untagged", options, client.DeleteObjectTagging)
+}
+
+func (client *Client) taggingFolder(cosPath string, verb string, options *TaggingOption, tagging func(key string) int) int {
+	successNum, skipNum, failNum := 0, 0, 0
+	nextMarker := ""
+	isTruncated := true
+	for isTruncated {
+		var result *cos.BucketGetResult
+		for i := 0; i <= client.Config.RetryTimes; i++ {
+			var err error
+			result, _, err = client.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+				Prefix:  cosPath,
+				Marker:  nextMarker,
+				MaxKeys: 1000,
+			})
+			if err == nil {
+				break
+			}
+			log.Warn(err.Error())
+			if i >= client.Config.RetryTimes {
+				return -1
+			}
+			time.Sleep((1 << i) * time.Second)
+		}
+		isTruncated = result.IsTruncated
+		nextMarker = result.NextMarker
+		running := make(chan struct{}, client.Config.MaxThread)
+		taggingResult := make(chan int, client.Config.MaxThread)
+		tasks := 0
+		for _, file := range result.Contents {
+			// directory placeholders
+			if strings.HasSuffix(file.Key, "/") {
+				continue
+			}
+			if options.Filter.ExcludedFile(relativeKey(file.Key, cosPath), objectAttr(file.Size, file.LastModified, file.StorageClass)) {
+				log.Debugf("Skip %s", file.Key)
+				skipNum++
+				continue
+			}
+			tasks++
+			go func(key string) {
+				running <- struct{}{}
+				taggingResult <- tagging(key)
+				<-running
+			}(file.Key)
+		}
+		for j := 0; j < tasks; j++ {
+			switch <-taggingResult {
+			case 0:
+				successNum++
+			default:
+				failNum++
+			}
+		}
+	}
+	log.Infof("%d files %s, %d files skipped, %d files failed", successNum, verb, skipNum, failNum)
+	if failNum != 0 {
+		return -1
+	}
+	return 0
+}
+
+func (client *Client) retryTagging(request func() error) int {
+	for i := 0; i <= client.Config.RetryTimes; i++ {
+		err := request()
+		if err == nil {
+			return 0
+		}
+		log.Warn(err.Error())
+		if cos.IsNotFoundError(err) {
+			return -1
+		}
+		if i < client.Config.RetryTimes {
+			time.Sleep((1 << i) * time.Second)
+		}
+	}
+	return -1
+}
+
+// Tags of the object, or of the version if versionID is given.
+func (client *Client) objectTags(cosPath string, versionID ...string) ([]coshelper.Tag, error) {
+	result, _, err := client.Client.Object.GetTagging(context.Background(), cosPath, versionID...)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]coshelper.Tag, 0, len(result.TagSet))
+	for _, tag := range result.TagSet {
+		tags = append(tags, coshelper.Tag{Key: tag.Key, Value: tag.Value})
+	}
+	return tags, nil
+}
+
+func printTags(tags []coshelper.Tag) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Key", "Value"})
+	for _, tag := range tags {
+		t.AppendRow(table.Row{tag.Key, tag.Value})
+	}
+	t.Render()
+}
+
+// CheckObjectTags checks whether the tags can be attached to an object.
+func CheckObjectTags(tags []coshelper.Tag) error {
+	if len(tags) > maxObjectTags {
+		return fmt.Errorf("%d tags, at most %d tags are allowed for an object", len(tags), maxObjectTags)
+	}
+	return nil
+}
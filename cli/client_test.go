@@ -0,0 +1,39 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import "testing"
+
+func TestRelativeKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		prefix string
+		want   string
+	}{
+		{"a/b/c.txt", "a/b/", "c.txt"},
+		{"a/b/c.txt", "a/b", "b/c.txt"},
+		{"a/b.txt", "a/b", "b.txt"},
+		{"abc.txt", "abc", "abc.txt"},
+		{"abc/d.txt", "abc", "abc/d.txt"},
+		{"abc/d.txt", "", "abc/d.txt"},
+	}
+	for _, tt := range tests {
+		if got := relativeKey(tt.key, tt.prefix); got != tt.want {
+			t.Errorf("relativeKey(%q, %q) = %q, want %q", tt.key, tt.prefix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// StorageClasses are the storage classes objects can be put in.
+var StorageClasses = []string{
+	"STANDARD",
+	"STANDARD_IA",
+	"ARCHIVE",
+	"DEEP_ARCHIVE",
+	"INTELLIGENT_TIERING",
+}
+
+type TransitionOption struct {
+	StorageClass string
+	Filter       *coshelper.Filter
+	DryRun       bool
+	// Inventory lists the objects instead of Bucket.Get if not nil.
+	Inventory *Inventory
+}
+
+// TransitionFolder moves the objects with prefix cosPath to options.StorageClass,
+// by copying every object to itself. Objects larger than 5GB are copied in parts.
+func (client *Client) TransitionFolder(cosPath string, options *TransitionOption) int {
+	successNum, skipNum, failNum := 0, 0, 0
+	var totalSize int64
+	nextMarker := ""
+	isTruncated := true
+	for isTruncated {
+		var result *cos.BucketGetResult
+		for i := 0; i <= client.Config.RetryTimes; i++ {
+			var err error
+			result, err = client.getObjects(options.Inventory, &cos.BucketGetOptions{
+				Prefix:  cosPath,
+				Marker:  nextMarker,
+				MaxKeys: 1000,
+			})
+			if err == nil {
+				break
+			}
+			log.Warn(err.Error())
+			if i >= client.Config.RetryTimes {
+				return -1
+			}
+			time.Sleep((1 << i) * time.Second)
+		}
+		isTruncated = result.IsTruncated
+		nextMarker = result.NextMarker
+		transiting := make(chan struct{}, client.Config.MaxThread)
+		transitionResult := make(chan int, client.Config.MaxThread)
+		tasks := 0
+		for _, file := range result.Contents {
+			// directory placeholders
+			if strings.HasSuffix(file.Key, "/") {
+				continue
+			}
+			if options.Filter.ExcludedFile(relativeKey(file.Key, cosPath), objectAttr(file.Size, file.LastModified, file.StorageClass)) {
+				log.Debugf("Skip %s", file.Key)
+				skipNum++
+				continue
+			}
+			if strings.EqualFold(file.StorageClass, options.StorageClass) {
+				log.Debugf("Skip %s, already in %s", file.Key, options.StorageClass)
+				skipNum++
+				continue
+			}
+			if options.DryRun {
+				log.Infof("Transition cos://%s/%s: %s => %s",
+					client.Config.Bucket, file.Key, file.StorageClass, options.StorageClass)
+				successNum++
+				totalSize += file.Size
+				continue
+			}
+			tasks++
+			totalSize += file.Size
+			go func(key, storageClass string) {
+				transiting <- struct{}{}
+				transitionResult <- client.transitionFile(key, storageClass, options)
+				<-transiting
+			}(file.Key, file.StorageClass)
+		}
+		for j := 0; j < tasks; j++ {
+			switch <-transitionResult {
+			case 0:
+				successNum++
+			default:
+				failNum++
+			}
+		}
+	}
+	if options.DryRun {
+		log.Infof("%d files (%s) would be transitioned to %s, %d files skipped",
+			successNum, coshelper.Humanize(totalSize, true), options.StorageClass, skipNum)
+		return 0
+	}
+	log.Infof("%d files transitioned to %s, %d files skipped, %d files failed, %s in total",
+		successNum, options.StorageClass, skipNum, failNum, coshelper.Humanize(totalSize, true))
+	if failNum != 0 {
+		return -1
+	}
+	return 0
+}
+
+func (client *Client) transitionFile(cosPath string, storageClass string, options *TransitionOption) int {
+	log.Infof("Transition cos://%s/%s: %s => %s",
+		client.Config.Bucket, cosPath, storageClass, options.StorageClass)
+	resp, err := client.Client.Object.Head(context.Background(), cosPath, nil)
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	return client.copyInPlace(cosPath, resp.Header, nil, &CopyOption{
+		Force:        true,
+		Directive:    "Copy",
+		SkipMd5:      true,
+		StorageClass: options.StorageClass,
+	})
+}
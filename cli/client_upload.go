@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -33,7 +34,6 @@ import (
 
 	"github.com/huanght1997/cosutil/coshelper"
 
-	"github.com/danwakefield/fnmatch"
 	"github.com/mitchellh/go-homedir"
 	"github.com/schollz/progressbar/v3"
 	log "github.com/sirupsen/logrus"
@@ -43,18 +43,52 @@ import (
 type UploadOption struct {
 	SkipMd5 bool
 	Sync    bool
-	Include []string
-	Ignore  []string
+	Filter  *coshelper.Filter
 	Force   bool
-	Yes		bool
+	Yes     bool
 	Delete  bool
+	Symlink int
+	// Compress is the algorithm to compress files with, empty for no compression.
+	Compress string
+	// Only compress the files whose names match one of the patterns if not empty.
+	CompressInclude []string
+	// HeaderRules sets headers of objects by their names.
+	HeaderRules *coshelper.HeaderRules
+	// StorageClass of the uploaded objects, empty for the default of the bucket.
+	StorageClass string
+	// Tags attached to the uploaded objects.
+	Tags []coshelper.Tag
+	// SSE is the server-side encryption of the uploaded objects, nil for the default of the bucket.
+	SSE *coshelper.SSE
 }
 
+// How symbolic links are handled when uploading folders.
+const (
+	// Upload the files the links point to, skip the links to directories.
+	SymlinkFile = iota
+	// Follow the links to files and directories, skip the links making loops.
+	SymlinkFollow
+	// Skip all links.
+	SymlinkSkip
+	// Store the links as small objects with the target in SymlinkTargetHeader.
+	SymlinkStore
+)
+
+// SymlinkTargetHeader keeps the escaped target of a stored symbolic link.
+const SymlinkTargetHeader = "x-cos-meta-symlink-target"
+
 // PUT object can only upload 5GB file at most.
 const (
 	singleUploadMaxSize = 5 * 1024 * 1024 * 1024
 )
 
+// A directory waiting to be traversed when uploading folders.
+// realPaths are the real paths of the directory and its ancestors, used to detect symbolic link loops.
+type uploadDirItem struct {
+	PathPair
+	realPaths []string
+}
+
 var (
 	pathDigest   string
 	uploadID     string
@@ -66,10 +100,15 @@ var (
 
 // Upload a single file.
 func (client *Client) UploadFile(localPath string, cosPath string, headers *http.Header, options *UploadOption) int {
-	fileSize, err := coshelper.GetFileSize(localPath)
+	f, err := os.Stat(localPath)
 	if err != nil {
 		return 2
 	}
+	if options.Filter.ExcludedFile(localFilterPath(localPath), localAttr(f)) {
+		log.Debugf("Skip %s", localPath)
+		return -2
+	}
+	fileSize := f.Size()
 	// Less than PartSize (MB), use put, force multipart upload if fileSize > 5GB
 	if fileSize <= int64(client.Config.PartSize)*1024*1024 && fileSize <= singleUploadMaxSize {
 		return client.singleUpload(localPath, cosPath, headers, options)
@@ -91,13 +130,18 @@ func (client *Client) UploadFolder(localPath string, cosPath string, headers *ht
 	}
 	// remove leading slashes
 	rawCosPath = strings.TrimLeft(rawCosPath, "/")
+	if options.Filter == nil {
+		options.Filter = coshelper.NewFilter(nil, nil)
+	}
 
 	// q is a slice used to act as a queue
-	q := make([]PathPair, 0)
+	q := make([]uploadDirItem, 0)
 	// add first element
-	q = append(q, PathPair{
-		LocalPath: localPath,
-		CosPath:   cosPath,
+	q = append(q, uploadDirItem{
+		PathPair: PathPair{
+			LocalPath: localPath,
+			CosPath:   cosPath,
+		},
 	})
 	uploadFileList := make([]PathPair, 0)
 	// BFS upload folders
@@ -105,8 +149,12 @@ func (client *Client) UploadFolder(localPath string, cosPath string, headers *ht
 	for len(q) > 0 {
 		localPath = q[0].LocalPath
 		cosPath = q[0].CosPath
+		realPaths := q[0].realPaths
 		// remove queue head
 		q = q[1:]
+		if realPath, err := filepath.EvalSymlinks(localPath); err == nil {
+			realPaths = append(realPaths[:len(realPaths):len(realPaths)], realPath)
+		}
 		// with suffix /, the path are folders
 		if !strings.HasSuffix(localPath, "/") {
 			localPath += "/"
@@ -116,6 +164,15 @@ func (client *Client) UploadFolder(localPath string, cosPath string, headers *ht
 		}
 		// remove leading slashes
 		cosPath = strings.TrimLeft(cosPath, "/")
+		// relative path of current directory, used by filter
+		relPath := strings.TrimPrefix(cosPath, rawCosPath)
+		// rules in the ignore file apply to current directory and its subdirectories
+		ignoreFilePath := localPath + coshelper.IgnoreFileName
+		if coshelper.IsFile(ignoreFilePath) {
+			if err := options.Filter.LoadFile(ignoreFilePath, relPath); err != nil {
+				log.Warnf("Cannot read '%s': %s", ignoreFilePath, err.Error())
+			}
+		}
 		// Get the file list under current directory
 		files, err := ioutil.ReadDir(localPath)
 		if err != nil {
@@ -124,11 +181,76 @@ func (client *Client) UploadFolder(localPath string, cosPath string, headers *ht
 		}
 		for _, file := range files {
 			filePath := path.Join(localPath, file.Name())
+			// ignore files are the rules of the transfer, not the content.
+			if file.Name() == coshelper.IgnoreFileName && !file.IsDir() {
+				log.Debugf("Skip %s", filePath)
+				continue
+			}
+			if file.Mode()&os.ModeSymlink != 0 {
+				switch options.Symlink {
+				case SymlinkSkip:
+					log.Debugf("Skip symbolic link %s", filePath)
+					skipNum++
+					continue
+				case SymlinkStore:
+					if options.Filter.ExcludedFile(relPath+file.Name(), localAttr(file)) {
+						log.Debugf("Skip %s", filePath)
+						skipNum++
+						continue
+					}
+					switch client.uploadSymlink(filePath, cosPath+file.Name(), headers, options) {
+					case 0:
+						successNum++
+					case -2:
+						skipNum++
+					default:
+						failNum++
+					}
+					continue
+				}
+				target, err := os.Stat(filePath)
+				if err != nil {
+					log.Warnf("Skip broken symbolic link %s", filePath)
+					skipNum++
+					continue
+				}
+				if target.IsDir() {
+					if options.Symlink != SymlinkFollow {
+						log.Warnf("Skip symbolic link to directory %s, use --follow-symlinks to upload its content", filePath)
+						skipNum++
+						continue
+					}
+					if realPath, err := filepath.EvalSymlinks(filePath); err != nil || containsString(realPaths, realPath) {
+						log.Warnf("Skip symbolic link %s, which makes a loop", filePath)
+						skipNum++
+						continue
+					}
+				}
+				file = target
+			}
+			if !file.IsDir() && !file.Mode().IsRegular() {
+				// sockets, named pipes and devices can not be uploaded.
+				log.Warnf("Skip special file %s", filePath)
+				skipNum++
+				continue
+			}
+			if file.IsDir() && options.Filter.Excluded(relPath+file.Name(), true) {
+				log.Debugf("Skip %s", filePath)
+				continue
+			}
+			if !file.IsDir() && options.Filter.ExcludedFile(relPath+file.Name(), localAttr(file)) {
+				log.Debugf("Skip %s", filePath)
+				skipNum++
+				continue
+			}
 			if file.IsDir() {
 				// a subdirectory, just append it to queue to wait for the next traverse
-				q = append(q, PathPair{
-					LocalPath: filePath,
-					CosPath:   cosPath + file.Name(),
+				q = append(q, uploadDirItem{
+					PathPair: PathPair{
+						LocalPath: filePath,
+						CosPath:   cosPath + file.Name(),
+					},
+					realPaths: realPaths,
 				})
 			} else {
 				// a single file, add it to upload file list
@@ -168,7 +290,7 @@ func (client *Client) UploadFolder(localPath string, cosPath string, headers *ht
 			}
 		}
 		log.Info("Synchronizing delete, please wait.")
-		ret, delSuccess, delFail := client.localToRemoteSyncDelete(rawLocalPath, rawCosPath)
+		ret, delSuccess, delFail := client.localToRemoteSyncDelete(rawLocalPath, rawCosPath, options.Filter)
 		if ret != 0 {
 			log.Warn("Sync delete fail")
 		} else {
@@ -185,6 +307,10 @@ func (client *Client) UploadFolder(localPath string, cosPath string, headers *ht
 
 // upload a single file, using PUT. If upload successfully, return 0; if skipped, return -2; if failed, return -1
 func (client *Client) singleUpload(localPath string, cosPath string, headers *http.Header, options *UploadOption) int {
+	if options.shouldCompress(localPath) {
+		return client.compressedUpload(localPath, cosPath, headers, options)
+	}
+	headers = options.objectHeaders(localPath, cosPath, headers)
 	localMd5 := ""
 	fileSize, err := coshelper.GetFileSize(localPath)
 	if err != nil {
@@ -232,6 +358,80 @@ func (client *Client) singleUpload(localPath string, cosPath string, headers *ht
 	return -1
 }
 
+// Headers of the object uploaded from localPath, the header rules are applied to headers,
+// and Content-Type is detected if it is still not specified.
+func (options *UploadOption) objectHeaders(localPath string, cosPath string, headers *http.Header) *http.Header {
+	objectHeaders := http.Header{}
+	if headers != nil {
+		objectHeaders = headers.Clone()
+	}
+	if options.StorageClass != "" {
+		objectHeaders.Set("x-cos-storage-class", options.StorageClass)
+	}
+	if len(options.Tags) != 0 {
+		objectHeaders.Set("x-cos-tagging", coshelper.EncodeTags(options.Tags))
+	}
+	options.HeaderRules.Apply(cosPath, objectHeaders)
+	options.SSE.SetWriteHeaders(objectHeaders)
+	if objectHeaders.Get("Content-Type") == "" {
+		if contentType := coshelper.DetectContentType(localPath); contentType != "" {
+			objectHeaders.Set("Content-Type", contentType)
+		}
+	}
+	return &objectHeaders
+}
+
+// Upload a symbolic link as a small object, the content and SymlinkTargetHeader of which is the target.
+// If upload successfully, return 0; if skipped, return -2; if failed, return -1
+func (client *Client) uploadSymlink(localPath string, cosPath string, headers *http.Header, options *UploadOption) int {
+	target, err := os.Readlink(localPath)
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	escapedTarget := url.PathEscape(target)
+	if options.Sync {
+		resp, err := client.Client.Object.Head(context.Background(), cosPath, &cos.ObjectHeadOptions{
+			XOptionHeader: options.SSE.ReadHeaders(),
+		})
+		if err == nil && resp.Header.Get(SymlinkTargetHeader) == escapedTarget {
+			log.Debugf("Skip %s   =>   cos://%s/%s",
+				localPath, client.Config.Bucket, cosPath)
+			return -2
+		}
+	}
+	linkHeaders := http.Header{}
+	if headers != nil {
+		linkHeaders = headers.Clone()
+	}
+	linkHeaders.Set(SymlinkTargetHeader, escapedTarget)
+	if options.StorageClass != "" {
+		linkHeaders.Set("x-cos-storage-class", options.StorageClass)
+	}
+	if len(options.Tags) != 0 {
+		linkHeaders.Set("x-cos-tagging", coshelper.EncodeTags(options.Tags))
+	}
+	options.SSE.SetWriteHeaders(linkHeaders)
+	log.Infof("Upload %s -> %s   =>   cos://%s/%s",
+		localPath, target, client.Config.Bucket, cosPath)
+	for j := 0; j <= client.Config.RetryTimes; j++ {
+		_, err := client.Client.Object.Put(context.Background(), cosPath, strings.NewReader(target), &cos.ObjectPutOptions{
+			ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+				XOptionHeader: &linkHeaders,
+			},
+		})
+		if err == nil {
+			return 0
+		}
+		log.Warn(err.Error())
+		if j < client.Config.RetryTimes {
+			time.Sleep((1 << j) * time.Second)
+		}
+	}
+	log.Warnf(`Upload symbolic link "%s" FAILED.`, localPath)
+	return -1
+}
+
 func (client *Client) uploadFiles(uploadFileList []PathPair, headers *http.Header, options *UploadOption) (successNum, skipNum, failNum int) {
 	successNum, skipNum, failNum = 0, 0, 0
 	tasks := 0
@@ -287,6 +487,10 @@ func (client *Client) uploadFiles(uploadFileList []PathPair, headers *http.Heade
 }
 
 func (client *Client) multipartUpload(localPath string, cosPath string, headers *http.Header, options *UploadOption) int {
+	if options.shouldCompress(localPath) {
+		return client.compressedUpload(localPath, cosPath, headers, options)
+	}
+	headers = options.objectHeaders(localPath, cosPath, headers)
 	fileMd5 := ""
 	f, err := os.Stat(localPath)
 	if err != nil {
@@ -330,40 +534,20 @@ func (client *Client) multipartUpload(localPath string, cosPath string, headers
 }
 
 // Check whether this sync should be processed.
-// the sync will not be processed if match one of them:
-//   the file is not in include list (default include path is '*')
-//   the file is in ignore list (default ignore path is empty)
-//   when --sync flag specified, if the remote file and the local file has the same size and same MD5.
+// the sync will not be processed when --sync flag specified, if the remote file and the local file
+// has the same size and same MD5. Filter rules are checked by callers, who know the relative path.
 // if this sync should be processed, return true; if this sync should be skipped, return false.
 func (client *Client) localToRemoteSyncCheck(localPath string, cosPath string, md5 string, size int64, options *UploadOption) bool {
-	// check this path is in ignore or include list
-	isInclude, isIgnore := false, false
-	for _, rule := range options.Include {
-		if fnmatch.Match(rule, cosPath, 0) {
-			isInclude = true
-			break
-		}
-	}
-	for _, rule := range options.Ignore {
-		if fnmatch.Match(rule, localPath, 0) {
-			isIgnore = true
-			break
-		}
-	}
-	if !isInclude || isIgnore {
-		log.Debugf("Skip %s", localPath)
-		return false
-	}
 	if options.Sync {
-		resp, err := client.Client.Object.Head(context.Background(), cosPath, nil)
+		resp, err := client.Client.Object.Head(context.Background(), cosPath, &cos.ObjectHeadOptions{
+			XOptionHeader: options.SSE.ReadHeaders(),
+		})
 		if err != nil {
 			return true
 		}
 		remoteMd5 := resp.Header.Get("x-cos-meta-md5")
-		remoteSize, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
-		if err != nil {
-			remoteSize = -1
-		}
+		// compare with the original file if the object is compressed
+		remoteSize := uncompressedSize(resp.Header)
 		if size == remoteSize {
 			if options.SkipMd5 || strings.EqualFold(md5, remoteMd5) {
 				log.Debugf("Skip %s   =>   cos://%s/%s",
@@ -375,8 +559,8 @@ func (client *Client) localToRemoteSyncCheck(localPath string, cosPath string, m
 	return true
 }
 
-// Remove objects exist on COS but not local.
-func (client *Client) localToRemoteSyncDelete(localPath string, cosPath string) (ret, successNum, failNum int) {
+// Remove objects exist on COS but not local. Objects excluded by filter are kept.
+func (client *Client) localToRemoteSyncDelete(localPath string, cosPath string, filter *coshelper.Filter) (ret, successNum, failNum int) {
 	successNum = 0
 	failNum = 0
 	nextMarker := ""
@@ -399,9 +583,12 @@ func (client *Client) localToRemoteSyncDelete(localPath string, cosPath string)
 				nextMarker = result.NextMarker
 				for _, file := range result.Contents {
 					remotePath := file.Key
+					if filter.ExcludedFile(relativeKey(remotePath, cosPath), objectAttr(file.Size, file.LastModified, file.StorageClass)) {
+						continue
+					}
 					localDeletePath := localPath + remotePath[len(cosPath):]
-					// if there is no local file, delete the file on COS
-					if !coshelper.IsFile(localDeletePath) {
+					// if there is no local file, delete the file on COS. Stored links may point to nowhere.
+					if !coshelper.IsFile(localDeletePath) && !coshelper.IsSymlink(localDeletePath) {
 						deleteList = append(deleteList, remotePath)
 					}
 				}
@@ -582,7 +769,9 @@ func (client *Client) multiUploadPartsData(localPath string, cosPath string, off
 		}
 	}()
 	for j := 0; j <= client.Config.RetryTimes; j++ {
-		resp, err := client.Client.Object.UploadPart(context.Background(), cosPath, uploadID, index, bytes.NewReader(data), nil)
+		resp, err := client.Client.Object.UploadPart(context.Background(), cosPath, uploadID, index, bytes.NewReader(data), &cos.ObjectUploadPartOptions{
+			XOptionHeader: options.SSE.ReadHeaders(),
+		})
 		if err != nil {
 			log.Warnf("Upload part failed, key: %s, partNumber: %d, round: %d, exception: %s",
 				cosPath, index, j+1, err.Error())
@@ -605,7 +794,7 @@ func (client *Client) multiUploadPartsData(localPath string, cosPath string, off
 			serverMd5 = strings.ReplaceAll(serverMd5, `"`, "")
 			md5List = append(md5List, fmt.Sprintf("%d#%s", index, serverMd5))
 			localEncryption := fmt.Sprintf("%x", md5.Sum(data))
-			if options.SkipMd5 || serverMd5 == localEncryption {
+			if options.SkipMd5 || !options.SSE.ETagIsMD5() || serverMd5 == localEncryption {
 				go updateProgress(uploadBar, chunkSize, uploadDone)
 				haveUploaded[index] = struct{}{}
 				return 0
@@ -0,0 +1,335 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// WatchFolder synchronizes localPath to cosPath first, then keeps watching
+// localPath and uploads the files created or modified in it. If options.Delete
+// is set, the objects of removed files are deleted too.
+// Events are collected until nothing happens for a debounce duration, then
+// they are handled in a batch. Ignore files created or modified while watching
+// are reloaded. It returns when interrupted, or -3 if deleting is declined in the initial sync.
+func (client *Client) WatchFolder(localPath string, cosPath string, headers *http.Header, options *UploadOption, debounce time.Duration) int {
+	if !strings.HasSuffix(localPath, "/") {
+		localPath += "/"
+	}
+	cosPath = strings.TrimLeft(cosPath, "/")
+	if cosPath != "" && !strings.HasSuffix(cosPath, "/") {
+		cosPath += "/"
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+	defer func() {
+		_ = watcher.Close()
+	}()
+	// Watch before the initial sync, so that nothing changed during it is missed.
+	watchedDirs := make(map[string]struct{})
+	if err := addWatchRecursively(watcher, localPath, watchedDirs); err != nil {
+		log.Warn(err.Error())
+		return -1
+	}
+
+	// The ignore files loaded by the initial sync must be kept in the filter for the events.
+	if options.Filter == nil {
+		options.Filter = coshelper.NewFilter(nil, nil)
+	}
+	syncOption := *options
+	syncOption.Sync = true
+	switch client.UploadFolder(localPath, cosPath, headers, &syncOption) {
+	case 0:
+	case -3:
+		// Deleting is declined, later removals must not delete without asking either.
+		return -3
+	default:
+		log.Warn("Initial synchronization is not completely successful")
+	}
+	log.Infof("Watching %s, press Ctrl+C to stop", localPath)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	// path => true if it should be uploaded, false if it should be deleted
+	pending := make(map[string]bool)
+	// directories removed or moved away, whose objects should be deleted
+	removedDirs := make(map[string]struct{})
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	failNum := 0
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return -1
+			}
+			log.Debugf("Event: %s", event.String())
+			filePath := filepath.Clean(event.Name)
+			switch {
+			case event.Op&(fsnotify.Create|fsnotify.Write) != 0:
+				f, err := os.Stat(filePath)
+				if err != nil {
+					continue
+				}
+				if f.IsDir() {
+					// A new directory, watch it and upload everything already in it.
+					if err := addWatchRecursively(watcher, filePath, watchedDirs); err != nil {
+						log.Warn(err.Error())
+					}
+					_ = filepath.Walk(filePath, func(p string, f os.FileInfo, err error) error {
+						if err == nil && f.Mode().IsRegular() {
+							pending[p] = true
+						}
+						return nil
+					})
+				} else if f.Mode().IsRegular() {
+					if filepath.Base(filePath) == coshelper.IgnoreFileName {
+						reloadIgnoreFile(localPath, filePath, options.Filter)
+					}
+					pending[filePath] = true
+				}
+			case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+				if _, ok := watchedDirs[filePath]; ok {
+					// Nothing is reported for the files in a directory moved away,
+					// so the objects under it are checked when the batch is handled.
+					unwatchRecursively(watcher, filePath, watchedDirs)
+					if options.Delete {
+						removedDirs[filePath] = struct{}{}
+						timer.Reset(debounce)
+					}
+					continue
+				} else if filepath.Base(filePath) == coshelper.IgnoreFileName {
+					reloadIgnoreFile(localPath, filePath, options.Filter)
+				}
+				if options.Delete {
+					pending[filePath] = false
+				} else {
+					delete(pending, filePath)
+				}
+			default:
+				continue
+			}
+			timer.Reset(debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return -1
+			}
+			log.Warn(err.Error())
+		case <-timer.C:
+			failNum += client.flushWatchEvents(localPath, cosPath, pending, removedDirs, headers, options)
+			pending = make(map[string]bool)
+			removedDirs = make(map[string]struct{})
+		case <-interrupt:
+			log.Info("Stop watching")
+			if len(pending) > 0 || len(removedDirs) > 0 {
+				failNum += client.flushWatchEvents(localPath, cosPath, pending, removedDirs, headers, options)
+			}
+			if failNum != 0 {
+				return -1
+			}
+			return 0
+		}
+	}
+}
+
+// Upload or delete the files in pending, and delete the objects under removedDirs which do not exist locally.
+// Return the number of failed files.
+func (client *Client) flushWatchEvents(localPath string, cosPath string, pending map[string]bool, removedDirs map[string]struct{}, headers *http.Header, options *UploadOption) int {
+	paths := make([]string, 0, len(pending))
+	for p := range pending {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	uploadFileList := make([]PathPair, 0)
+	deleteList := make([]string, 0)
+	for _, p := range paths {
+		rel, err := filepath.Rel(localPath, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		fileCosPath := cosPath + rel
+		if pending[p] {
+			// The file may have been removed again before the batch is handled.
+			f, err := os.Stat(p)
+			if err != nil || !f.Mode().IsRegular() || f.Name() == coshelper.IgnoreFileName {
+				continue
+			}
+			if options.Filter.ExcludedFile(rel, localAttr(f)) {
+				log.Debugf("Skip %s", p)
+				continue
+			}
+			uploadFileList = append(uploadFileList, PathPair{
+				LocalPath: p,
+				CosPath:   fileCosPath,
+			})
+		} else if _, err := os.Stat(p); os.IsNotExist(err) && !options.Filter.Excluded(rel, false) {
+			deleteList = append(deleteList, fileCosPath)
+		}
+	}
+	failNum := 0
+	// The files in a removed directory may be reported one by one too.
+	toDelete := make(map[string]struct{}, len(deleteList))
+	for _, key := range deleteList {
+		toDelete[key] = struct{}{}
+	}
+	dirs := make([]string, 0, len(removedDirs))
+	for dir := range removedDirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(localPath, dir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		keys, err := client.listRemovedObjects(localPath, cosPath, filepath.ToSlash(rel)+"/", options.Filter)
+		if err != nil {
+			log.Warn(err.Error())
+			failNum++
+			continue
+		}
+		for _, key := range keys {
+			if _, ok := toDelete[key]; !ok {
+				toDelete[key] = struct{}{}
+				deleteList = append(deleteList, key)
+			}
+		}
+	}
+	if len(uploadFileList) > 0 {
+		succ, skip, fail := client.uploadFiles(uploadFileList, headers, options)
+		log.Infof("%d files uploaded, %d files skipped, %d files failed",
+			succ, skip, fail)
+		failNum += fail
+	}
+	// DeleteMulti accepts 1000 keys at most.
+	for len(deleteList) > 0 {
+		n := len(deleteList)
+		if n > 1000 {
+			n = 1000
+		}
+		_, fail := client.DeleteObjects(deleteList[:n])
+		failNum += fail
+		deleteList = deleteList[n:]
+	}
+	return failNum
+}
+
+// List the objects under cosPath+relDir whose files do not exist in localPath. Objects excluded by filter are kept.
+func (client *Client) listRemovedObjects(localPath string, cosPath string, relDir string, filter *coshelper.Filter) ([]string, error) {
+	var keys []string
+	prefix := cosPath + relDir
+	nextMarker := ""
+	isTruncated := true
+	for isTruncated {
+		var result *cos.BucketGetResult
+		for i := 0; i <= client.Config.RetryTimes; i++ {
+			var err error
+			result, _, err = client.Client.Bucket.Get(context.Background(), &cos.BucketGetOptions{
+				Prefix:  prefix,
+				Marker:  nextMarker,
+				MaxKeys: 1000,
+			})
+			if err == nil {
+				break
+			}
+			if i >= client.Config.RetryTimes {
+				return nil, err
+			}
+			log.Warn(err.Error())
+			time.Sleep((1 << i) * time.Second)
+		}
+		isTruncated = result.IsTruncated
+		nextMarker = result.NextMarker
+		for _, file := range result.Contents {
+			if filter.ExcludedFile(relativeKey(file.Key, cosPath), objectAttr(file.Size, file.LastModified, file.StorageClass)) {
+				continue
+			}
+			localFilePath := filepath.Join(localPath, filepath.FromSlash(file.Key[len(cosPath):]))
+			if _, err := os.Lstat(localFilePath); os.IsNotExist(err) {
+				keys = append(keys, file.Key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// Reload the rules of the ignore file at filePath, the rules are dropped if the file is removed.
+func reloadIgnoreFile(localPath string, filePath string, filter *coshelper.Filter) {
+	base, err := filepath.Rel(localPath, filepath.Dir(filePath))
+	if err != nil {
+		return
+	}
+	if base == "." {
+		base = ""
+	}
+	if err := filter.ReloadFile(filePath, filepath.ToSlash(base)); err != nil {
+		log.Warnf("Cannot read '%s': %s", filePath, err.Error())
+		return
+	}
+	log.Infof("Rules in %s reloaded", filePath)
+}
+
+// Stop watching dir and the directories in it.
+func unwatchRecursively(watcher *fsnotify.Watcher, dir string, watchedDirs map[string]struct{}) {
+	for p := range watchedDirs {
+		if p == dir || strings.HasPrefix(p, dir+string(filepath.Separator)) {
+			// The watch of a removed directory is already gone.
+			_ = watcher.Remove(p)
+			delete(watchedDirs, p)
+		}
+	}
+}
+
+func addWatchRecursively(watcher *fsnotify.Watcher, root string, watchedDirs map[string]struct{}) error {
+	return filepath.Walk(root, func(p string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !f.IsDir() {
+			return nil
+		}
+		p = filepath.Clean(p)
+		if _, ok := watchedDirs[p]; ok {
+			return nil
+		}
+		if err := watcher.Add(p); err != nil {
+			return err
+		}
+		watchedDirs[p] = struct{}{}
+		return nil
+	})
+}
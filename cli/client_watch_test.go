@@ -0,0 +1,93 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+func TestWatchFolderDeleteDeclined(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `<ListBucketResult><IsTruncated>false</IsTruncated></ListBucketResult>`)
+		case http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPut:
+			w.Header().Set("ETag", `"e"`)
+		case http.MethodPost, http.MethodDelete:
+			deleted = true
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	client := &Client{
+		Client:     cos.NewClient(&cos.BaseURL{BucketURL: u}, &http.Client{}),
+		Config:     &ClientConfig{Bucket: "examplebucket-1250000000", PartSize: 20, MaxThread: 1, RetryTimes: 0},
+		httpClient: &http.Client{},
+	}
+
+	// Decline the confirmation of deleting.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	_, _ = w.WriteString("n\n")
+	_ = w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() {
+		os.Stdin = stdin
+	}()
+
+	done := make(chan int, 1)
+	go func() {
+		done <- client.WatchFolder(dir, "dir/", nil, &UploadOption{Delete: true, SkipMd5: true}, 10*time.Millisecond)
+	}()
+	select {
+	case ret := <-done:
+		if ret != -3 {
+			t.Errorf("WatchFolder = %d, want -3", ret)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchFolder keeps watching after deleting is declined")
+	}
+	if deleted {
+		t.Error("objects are deleted after deleting is declined")
+	}
+}
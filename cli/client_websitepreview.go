@@ -0,0 +1,212 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// Headers of objects passed to the browser by the preview server.
+var websitePreviewHeaders = []string{
+	"Accept-Ranges",
+	"Cache-Control",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Length",
+	"Content-Range",
+	"Content-Type",
+	"ETag",
+	"Last-Modified",
+}
+
+type websitePreviewHandler struct {
+	client *Client
+	prefix string
+	config *WebsiteConfiguration
+}
+
+// WebsitePreview serves the objects with prefix cosPath at addr, applying the index document,
+// error document and routing rules of config like the static website endpoint does.
+// If config is nil, the website configuration of the bucket is used.
+// It blocks until the server fails, and returns -1.
+func (client *Client) WebsitePreview(cosPath string, addr string, config *WebsiteConfiguration) int {
+	if config == nil {
+		var configured bool
+		var err error
+		config, configured, err = client.getBucketWebsite()
+		if err != nil {
+			log.Warn(err.Error())
+			return -1
+		}
+		if !configured {
+			log.Warn("Website is not configured, index.html is used as the index document")
+			config = &WebsiteConfiguration{IndexDocument: "index.html"}
+		}
+	}
+	if config.RedirectAllRequestsTo != "" {
+		log.Infof("Requests would be redirected to %s, which is not done in preview", config.RedirectAllRequestsTo)
+	}
+	cosPath = strings.TrimLeft(cosPath, "/")
+	if cosPath != "" && !strings.HasSuffix(cosPath, "/") {
+		cosPath += "/"
+	}
+	log.Infof("Preview cos://%s/%s at http://%s/", client.Config.Bucket, cosPath, addr)
+	err := http.ListenAndServe(addr, &websitePreviewHandler{
+		client: client,
+		prefix: cosPath,
+		config: config,
+	})
+	log.Warn(err.Error())
+	return -1
+}
+
+func (h *websitePreviewHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		h.error(w, r, http.StatusMethodNotAllowed)
+		return
+	}
+	p := requestKey(r)
+	for _, rule := range h.config.RoutingRules {
+		if rule.Condition.HTTPErrorCode == 0 && rule.matches(p, 0) {
+			h.redirect(w, r, rule.Redirect.Protocol, rule.redirectKey(p), http.StatusMovedPermanently)
+			return
+		}
+	}
+	key := p
+	if key == "" || strings.HasSuffix(key, "/") {
+		key += h.config.IndexDocument
+	}
+	status := h.serveObject(w, r, h.prefix+key, http.StatusOK)
+	if status == 0 {
+		return
+	}
+	// A directory without trailing slash is redirected, if it has the index document.
+	if status == http.StatusNotFound && key == p {
+		indexKey := h.prefix + p + "/" + h.config.IndexDocument
+		if _, err := h.client.Client.Object.Head(r.Context(), indexKey, nil); err == nil {
+			h.redirect(w, r, "", p+"/", http.StatusFound)
+			return
+		}
+	}
+	h.error(w, r, status)
+}
+
+// Respond with the error document or the routing rule of the status.
+func (h *websitePreviewHandler) error(w http.ResponseWriter, r *http.Request, status int) {
+	p := requestKey(r)
+	for _, rule := range h.config.RoutingRules {
+		if rule.Condition.HTTPErrorCode != 0 && rule.matches(p, status) {
+			h.redirect(w, r, rule.Redirect.Protocol, rule.redirectKey(p), http.StatusMovedPermanently)
+			return
+		}
+	}
+	if h.config.ErrorDocument != "" && status >= 400 && status < 500 && status != http.StatusMethodNotAllowed {
+		if h.serveObject(w, r, h.prefix+h.config.ErrorDocument, status) == 0 {
+			return
+		}
+	}
+	log.Infof("%s %s => %d", r.Method, r.URL.Path, status)
+	http.Error(w, http.StatusText(status), status)
+}
+
+// The key requested by r relative to the prefix. "." and ".." are resolved, so that keys outside
+// the prefix can not be requested, and the trailing slash of directories is kept.
+func requestKey(r *http.Request) string {
+	p := path.Clean("/" + r.URL.Path)
+	if strings.HasSuffix(r.URL.Path, "/") && p != "/" {
+		p += "/"
+	}
+	return strings.TrimPrefix(p, "/")
+}
+
+// Whether the rule matches the key and the status, 0 for a successful request.
+// Every condition set in the rule must be satisfied, and a rule without conditions matches nothing.
+func (rule *WebsiteRoutingRule) matches(key string, status int) bool {
+	if rule.Condition.HTTPErrorCode == 0 && rule.Condition.KeyPrefix == "" {
+		return false
+	}
+	if rule.Condition.HTTPErrorCode != 0 && rule.Condition.HTTPErrorCode != status {
+		return false
+	}
+	return strings.HasPrefix(key, rule.Condition.KeyPrefix)
+}
+
+// The key redirected to by the rule.
+func (rule *WebsiteRoutingRule) redirectKey(key string) string {
+	if rule.Redirect.ReplaceKeyPrefixWith != "" {
+		return rule.Redirect.ReplaceKeyPrefixWith + strings.TrimPrefix(key, rule.Condition.KeyPrefix)
+	}
+	return rule.Redirect.ReplaceKeyWith
+}
+
+func (h *websitePreviewHandler) redirect(w http.ResponseWriter, r *http.Request, protocol string, key string, status int) {
+	location := "/" + strings.TrimLeft(key, "/")
+	if protocol != "" {
+		location = protocol + "://" + r.Host + location
+	}
+	log.Infof("%s %s => %d %s", r.Method, r.URL.Path, status, location)
+	http.Redirect(w, r, location, status)
+}
+
+// Write the object to w with status, or with 206 for range requests.
+// If served, return 0; otherwise, return the status of the error and nothing is written.
+func (h *websitePreviewHandler) serveObject(w http.ResponseWriter, r *http.Request, key string, status int) int {
+	var resp *cos.Response
+	var err error
+	if r.Method == http.MethodHead {
+		resp, err = h.client.Client.Object.Head(r.Context(), key, nil)
+	} else {
+		var opt *cos.ObjectGetOptions
+		if rangeHeader := r.Header.Get("Range"); rangeHeader != "" && status == http.StatusOK {
+			opt = &cos.ObjectGetOptions{Range: rangeHeader}
+		}
+		resp, err = h.client.Client.Object.Get(r.Context(), key, opt)
+	}
+	if err != nil {
+		if errorResponse, ok := err.(*cos.ErrorResponse); ok && errorResponse.Response != nil {
+			return errorResponse.Response.StatusCode
+		}
+		if err != context.Canceled {
+			log.Warn(err.Error())
+		}
+		return http.StatusBadGateway
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	for _, name := range websitePreviewHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			w.Header().Set(name, value)
+		}
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		status = http.StatusPartialContent
+	}
+	log.Infof("%s %s => %d cos://%s/%s", r.Method, r.URL.Path, status, h.client.Config.Bucket, key)
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		_, _ = io.Copy(w, resp.Body)
+	}
+	return 0
+}
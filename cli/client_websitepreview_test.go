@@ -0,0 +1,80 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestKey(t *testing.T) {
+	tests := map[string]string{
+		"/":                "",
+		"/a.html":          "a.html",
+		"/docs/":           "docs/",
+		"/docs/../a.html":  "a.html",
+		"/../../secret":    "secret",
+		"/docs/./x/../":    "docs/",
+		"//docs//a.html":   "docs/a.html",
+		"/docs/%2e%2e/a/b": "a/b",
+	}
+	for target, want := range tests {
+		r := httptest.NewRequest("GET", target, nil)
+		if got := requestKey(r); got != want {
+			t.Errorf("requestKey(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestRoutingRuleMatches(t *testing.T) {
+	tests := []struct {
+		condition WebsiteCondition
+		key       string
+		status    int
+		match     bool
+	}{
+		{WebsiteCondition{KeyPrefix: "old/"}, "old/a.html", 0, true},
+		{WebsiteCondition{KeyPrefix: "old/"}, "new/a.html", 0, false},
+		{WebsiteCondition{HTTPErrorCode: 404}, "a.html", 404, true},
+		{WebsiteCondition{HTTPErrorCode: 404}, "a.html", 0, false},
+		{WebsiteCondition{HTTPErrorCode: 404}, "a.html", 403, false},
+		{WebsiteCondition{HTTPErrorCode: 404, KeyPrefix: "old/"}, "old/a.html", 404, true},
+		{WebsiteCondition{HTTPErrorCode: 404, KeyPrefix: "old/"}, "new/a.html", 404, false},
+		{WebsiteCondition{HTTPErrorCode: 404, KeyPrefix: "old/"}, "old/a.html", 0, false},
+		{WebsiteCondition{}, "a.html", 0, false},
+	}
+	for _, test := range tests {
+		rule := WebsiteRoutingRule{Condition: test.condition}
+		if got := rule.matches(test.key, test.status); got != test.match {
+			t.Errorf("%+v matches(%q, %d) = %v, want %v", test.condition, test.key, test.status, got, test.match)
+		}
+	}
+}
+
+func TestRoutingRuleRedirectKey(t *testing.T) {
+	rule := WebsiteRoutingRule{
+		Condition: WebsiteCondition{KeyPrefix: "old/"},
+		Redirect:  WebsiteRedirect{ReplaceKeyPrefixWith: "new/"},
+	}
+	if got := rule.redirectKey("old/a/b.html"); got != "new/a/b.html" {
+		t.Errorf("redirectKey = %q", got)
+	}
+	rule.Redirect = WebsiteRedirect{ReplaceKeyWith: "index.html"}
+	if got := rule.redirectKey("old/a/b.html"); got != "index.html" {
+		t.Errorf("redirectKey = %q", got)
+	}
+}
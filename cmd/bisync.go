@@ -0,0 +1,133 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/mitchellh/go-homedir"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type BisyncConfig struct {
+	dryRun, yes                           bool
+	conflict, include, ignore, filterFrom string
+	maxDelete                             int
+}
+
+var (
+	bisyncConfig BisyncConfig
+	bisyncCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use: "bisync [-h] [--conflict {both,newer,local,remote}] [--include INCLUDE] [--ignore IGNORE]" +
+			" [--filter-from FILE] [--max-delete PERCENT] [--dry-run] [-y] LOCAL_PATH COS_PATH",
+		Short: "Synchronize local directory and COS path in both directions",
+		Long: `Synchronize local directory and COS path in both directions.
+
+The state of both sides is saved after each run, and new, changed or deleted
+files found in the next run are propagated to the other side. If a file has
+been changed on both sides, --conflict decides what to do:
+  both	keep both copies, suffixed with .conflict-local and .conflict-remote
+  newer	keep the one modified later
+  local	keep the local one
+  remote	keep the one on COS
+
+Files new on both sides with the same content are not conflicts. The run is aborted
+if more than --max-delete percent of the files known by the previous run would be deleted.
+
+LOCAL_PATH	Local directory as /tmp/a
+COS_PATH	COS path as a/b/`,
+		Args: cobra.ExactArgs(2),
+		RunE: bisync,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(bisyncCmd)
+
+	bisyncCmd.Flags().SortFlags = false
+	bisyncCmd.Flags().StringVar(&bisyncConfig.conflict, "conflict", cli.ConflictBoth,
+		"Specify conflict policy, one of both, newer, local, remote")
+	bisyncCmd.Flags().StringVar(&bisyncConfig.include, "include", "*",
+		"Specify filter rules, separated by commas; Example: *.txt,*.docx,*.ppt")
+	bisyncCmd.Flags().StringVar(&bisyncConfig.ignore, "ignore", "",
+		"Specify ignored rules, separated by commas; Example: *.txt,*.docx,*.ppt")
+	bisyncCmd.Flags().StringVar(&bisyncConfig.filterFrom, "filter-from", "",
+		"Read gitignore-style filter rules from file")
+	bisyncCmd.Flags().IntVar(&bisyncConfig.maxDelete, "max-delete", 50,
+		"Abort if more than the percentage of files would be deleted, 100 for no limit")
+	bisyncCmd.Flags().BoolVar(&bisyncConfig.dryRun, "dry-run", false,
+		"Only show what would be done")
+	bisyncCmd.Flags().BoolVarP(&bisyncConfig.yes, "yes", "y", false,
+		"Skip confirmation")
+}
+
+func bisync(_ *cobra.Command, args []string) error {
+	localPath, _ := homedir.Expand(args[0])
+	cosPath := strings.TrimLeft(args[1], "/")
+	switch bisyncConfig.conflict {
+	case cli.ConflictBoth, cli.ConflictNewer, cli.ConflictLocal, cli.ConflictRemote:
+	default:
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid --conflict option: must be one of them - both, newer, local, remote",
+		}
+	}
+	if coshelper.FileExists(localPath) && !coshelper.IsDir(localPath) {
+		log.Warnf("'%s' is not a directory", localPath)
+		return coshelper.Error{
+			Code:    1,
+			Message: "local path is not a directory",
+		}
+	}
+	if bisyncConfig.maxDelete < 0 || bisyncConfig.maxDelete > 100 {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid --max-delete option: must be between 0 and 100",
+		}
+	}
+	filter, err := newFilter(bisyncConfig.include, bisyncConfig.ignore, bisyncConfig.filterFrom)
+	if err != nil {
+		return err
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	options := &cli.BisyncOption{
+		Conflict:  bisyncConfig.conflict,
+		Filter:    filter,
+		DryRun:    bisyncConfig.dryRun,
+		Yes:       bisyncConfig.yes,
+		MaxDelete: bisyncConfig.maxDelete,
+	}
+	ret := client.Bisync(localPath, cosPath, options)
+	switch ret {
+	case 0:
+		return nil
+	case -3:
+		log.Info("bisync canceled by user")
+		return nil
+	default:
+		return coshelper.Error{
+			Code:    ret,
+			Message: "bisync failed",
+		}
+	}
+}
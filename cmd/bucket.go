@@ -0,0 +1,119 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+type BucketConfig struct {
+	json, dryRun, yes, prune bool
+}
+
+var (
+	bucketConfig BucketConfig
+	bucketCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "bucket [-h] {export,apply}",
+		Short:                 "Manage bucket configurations declaratively",
+	}
+	bucketExportCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "export [-h] [--json]",
+		Short:                 "Print every configuration of bucket",
+		Long: `Print every configuration of bucket in YAML, which can be applied by bucket apply.
+
+Versioning, ACL, tags, lifecycle, CORS, policy, website, replication, inventories, logging
+and encryption are exported.`,
+		Args: cobra.ExactArgs(0),
+		RunE: bucketExport,
+	}
+	bucketApplyCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "apply [-h] [--dry-run] [--prune] [-y] FILE",
+		Short:                 "Apply configurations to bucket",
+		Long: `Compare the configurations of bucket with the file, print the plan and apply the changes.
+
+Configurations absent in the file are left as they are. With --prune, tags, lifecycle, CORS,
+policy, website, replication, inventories, logging and encryption absent in the file are deleted,
+while versioning and ACL are still left as they are.
+
+FILE	JSON or YAML file, like the output of bucket export`,
+		Args: cobra.ExactArgs(1),
+		RunE: bucketApply,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(bucketCmd)
+	bucketCmd.AddCommand(bucketExportCmd)
+	bucketCmd.AddCommand(bucketApplyCmd)
+
+	bucketExportCmd.Flags().BoolVar(&bucketConfig.json, "json", false, "Print in JSON instead of YAML")
+	bucketApplyCmd.Flags().SortFlags = false
+	bucketApplyCmd.Flags().BoolVar(&bucketConfig.dryRun, "dry-run", false, "Only print the plan")
+	bucketApplyCmd.Flags().BoolVar(&bucketConfig.prune, "prune", false,
+		"Delete the configurations absent in the file")
+	bucketApplyCmd.Flags().BoolVarP(&bucketConfig.yes, "yes", "y", false, "Skip confirmation")
+}
+
+func bucketExport(*cobra.Command, []string) error {
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if !client.ExportBucket(bucketConfig.json) {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "export bucket fail",
+		}
+	}
+	return nil
+}
+
+func bucketApply(_ *cobra.Command, args []string) error {
+	filePath, _ := homedir.Expand(args[0])
+	var config cli.BucketConfiguration
+	if err := coshelper.UnmarshalFile(filePath, &config); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid bucket configuration file: " + err.Error(),
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid bucket configuration: " + err.Error(),
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	ret := client.ApplyBucket(&config, &cli.BucketApplyOption{
+		DryRun: bucketConfig.dryRun,
+		Yes:    bucketConfig.yes,
+		Prune:  bucketConfig.prune,
+	})
+	if ret != 0 {
+		return coshelper.Error{
+			Code:    ret,
+			Message: "apply bucket fail",
+		}
+	}
+	return nil
+}
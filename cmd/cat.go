@@ -0,0 +1,129 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+type CatConfig struct {
+	byteRange, versionID string
+	lines, bytes         int64
+	sse                  SSEConfig
+}
+
+var (
+	catConfig CatConfig
+	catCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "cat [-h] [--range RANGE] [--version-id VERSION_ID] [--sse-c-key FILE] COS_PATH",
+		Short:                 "Print file on COS",
+		Long: `Print file on COS to the standard output, files with Content-Encoding gzip or zstd, like those uploaded
+with --compress, are decompressed
+
+COS_PATH	COS Path as a/b.txt`,
+		Args: cobra.ExactArgs(1),
+		RunE: cat,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(catCmd)
+
+	catCmd.Flags().SortFlags = false
+	catCmd.Flags().StringVar(&catConfig.byteRange, "range", "",
+		"Only print the range of bytes; Example: 0-1023, 1024-, -1024 (the last 1024 bytes)")
+	addCatFlags(catCmd, &catConfig)
+}
+
+// Add the flags shared by cat, head and tail.
+func addCatFlags(cmd *cobra.Command, config *CatConfig) {
+	cmd.Flags().StringVar(&config.versionID, "version-id", "", "Specify versionId of object")
+	addSSEFlags(cmd.Flags(), &config.sse, false)
+}
+
+func cat(_ *cobra.Command, args []string) error {
+	options := &cli.CatOption{}
+	if catConfig.byteRange != "" {
+		byteRange, err := cli.ParseByteRange(catConfig.byteRange)
+		if err != nil {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid --range option: " + err.Error(),
+			}
+		}
+		options.Range = byteRange
+	}
+	return catObject(args[0], &catConfig, options)
+}
+
+func catObject(cosPath string, config *CatConfig, options *cli.CatOption) error {
+	sse, err := config.sse.build()
+	if err != nil {
+		return err
+	}
+	options.VersionID = config.versionID
+	options.Headers = sse.ReadHeaders()
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if ret := client.Cat(strings.TrimLeft(cosPath, "/"), os.Stdout, options); ret != 0 {
+		return coshelper.Error{
+			Code:    ret,
+			Message: "cat failed",
+		}
+	}
+	return nil
+}
+
+// Print the first or last part of the object for head and tail.
+func catPart(cmd *cobra.Command, cosPath string, config *CatConfig, tail bool) error {
+	if cmd.Flags().Changed("lines") && cmd.Flags().Changed("bytes") {
+		return coshelper.Error{
+			Code:    1,
+			Message: "-n/--lines and -c/--bytes cannot be used together",
+		}
+	}
+	options := &cli.CatOption{Tail: tail}
+	if cmd.Flags().Changed("bytes") {
+		if config.bytes <= 0 {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid -c/--bytes option: must be positive",
+			}
+		}
+		if tail {
+			options.Range = &cli.ByteRange{Suffix: config.bytes}
+		} else {
+			options.Range = &cli.ByteRange{First: 0, Last: config.bytes - 1}
+		}
+	} else {
+		if config.lines <= 0 {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid -n/--lines option: must be positive",
+			}
+		}
+		options.Lines = int(config.lines)
+	}
+	return catObject(cosPath, config, options)
+}
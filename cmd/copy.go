@@ -28,14 +28,18 @@ import (
 
 type CopyConfig struct {
 	sync, recursive, force, yes, skipMd5, deleteTarget bool
-	headers, include, ignore, directive                string
+	headers, include, ignore, directive, filterFrom    string
+	storageClass, tags, fromInventory                  string
+	predicate                                          PredicateConfig
+	sse                                                SSEConfig
+	sourceCustomerKey                                  string
 }
 
 var (
 	copyConfig CopyConfig
 	copyCmd    = &cobra.Command{
 		DisableFlagsInUseLine: true,
-		Use:                   "copy [-h] [-H HEADERS] [-d {Copy,Replaced}] [-s] [-r] [-f] [-y] [--include INCLUDE] [--ignore IGNORE] [--skipmd5] [--delete] SOURCE_PATH COS_PATH",
+		Use:                   "copy [-h] [-H HEADERS] [-d {Copy,Replaced}] [--storage-class CLASS] [--tags TAGS] [-s] [-r] [-f] [-y] [--include INCLUDE] [--ignore IGNORE] [--filter-from FILE] [--min-size SIZE] [--max-size SIZE] [--newer-than TIME] [--older-than TIME] [--class CLASS] [--regex REGEX] [--skipmd5] [--delete] [--from-inventory MANIFEST] [--sse {cos,kms}] [--kms-key-id ID] [--sse-c-key FILE] [--source-sse-c-key FILE] SOURCE_PATH COS_PATH",
 		Short:                 "Copy file from COS to COS",
 		Long: `Copy file from COS to COS
 
@@ -52,6 +56,10 @@ func init() {
 	copyCmd.Flags().SortFlags = false
 	copyCmd.Flags().StringVarP(&copyConfig.headers, "headers", "H", "{}", "Specify HTTP headers")
 	copyCmd.Flags().StringVarP(&copyConfig.directive, "directive", "d", "Copy", "if Overwrite headers")
+	copyCmd.Flags().StringVar(&copyConfig.storageClass, "storage-class", "",
+		"Specify the storage class of target objects: "+strings.Join(cli.StorageClasses, ", "))
+	copyCmd.Flags().StringVar(&copyConfig.tags, "tags", "",
+		"Specify tags replacing the tags of source objects, separated by commas; Example: project=a,env=prod")
 	copyCmd.Flags().BoolVarP(&copyConfig.sync, "sync", "s", false, "Copy and skip the same file")
 	copyCmd.Flags().BoolVarP(&copyConfig.recursive, "recursive", "r", false, "Copy files recursively")
 	copyCmd.Flags().BoolVarP(&copyConfig.force, "force", "f", false, "Overwrite file without skip")
@@ -61,10 +69,18 @@ func init() {
 		"Specify filter rules, separated by commas; Example: *.txt,*.docx,*.ppt")
 	copyCmd.Flags().StringVar(&copyConfig.ignore, "ignore", "",
 		"Specify ignored rules, separated by commas; Example: *.txt,*.docx,*.ppt")
+	copyCmd.Flags().StringVar(&copyConfig.filterFrom, "filter-from", "",
+		"Read gitignore-style filter rules from file")
+	addPredicateFlags(copyCmd.Flags(), &copyConfig.predicate, true)
 	copyCmd.Flags().BoolVar(&copyConfig.skipMd5, "skipmd5", false,
 		"Copy sync without md5 check, only check filename and filesize")
 	copyCmd.Flags().BoolVar(&copyConfig.deleteTarget, "delete", false,
 		"Delete objects whick exists in source path but not exist in dest path")
+	copyCmd.Flags().StringVar(&copyConfig.fromInventory, "from-inventory", "",
+		"Read source objects from the inventory report with the manifest instead of listing them; Example: manifest.json")
+	addSSEFlags(copyCmd.Flags(), &copyConfig.sse, true)
+	copyCmd.Flags().StringVar(&copyConfig.sourceCustomerKey, "source-sse-c-key", "",
+		"Read the 32 bytes key of SSE-C of source objects from file, in raw bytes, base64 or hex")
 }
 
 func copyCos(_ *cobra.Command, args []string) error {
@@ -80,16 +96,61 @@ func copyCos(_ *cobra.Command, args []string) error {
 			Message: "-d/--directive flags must be 'Copy' or 'Replaced'",
 		}
 	}
+	storageClass := ""
+	if copyConfig.storageClass != "" {
+		var err error
+		if storageClass, err = checkStorageClass(copyConfig.storageClass, "--storage-class"); err != nil {
+			return err
+		}
+	}
+	tags, err := parseObjectTags(copyConfig.tags, "--tags")
+	if err != nil {
+		return err
+	}
+	filter, err := newFilter(copyConfig.include, copyConfig.ignore, copyConfig.filterFrom)
+	if err != nil {
+		return err
+	}
+	if err := copyConfig.predicate.apply(filter); err != nil {
+		return err
+	}
+	if copyConfig.fromInventory != "" && !copyConfig.recursive {
+		return coshelper.Error{
+			Code:    1,
+			Message: "--from-inventory only works with -r/--recursive",
+		}
+	}
+	// SOURCE_PATH: bucket-appid.cos.ap-guangzhou.myqcloud.com/path
+	sourceBucket := strings.Split(strings.Split(args[0], "/")[0], ".")[0]
+	inventory, err := openInventory(client, copyConfig.fromInventory, sourceBucket)
+	if err != nil {
+		return err
+	}
+	if inventory != nil {
+		defer inventory.Close()
+	}
+	sse, err := copyConfig.sse.build()
+	if err != nil {
+		return err
+	}
+	sourceSSE, err := (&SSEConfig{customerKey: copyConfig.sourceCustomerKey}).build()
+	if err != nil {
+		return err
+	}
 	options := &cli.CopyOption{
-		Sync:      copyConfig.sync,
-		Force:     copyConfig.force,
-		Yes:       copyConfig.yes,
-		Directive: copyConfig.directive,
-		SkipMd5:   copyConfig.skipMd5,
-		Ignore:    strings.Split(copyConfig.ignore, ","),
-		Include:   strings.Split(copyConfig.include, ","),
-		Delete:    copyConfig.deleteTarget,
-		Move:      false,
+		Sync:         copyConfig.sync,
+		Force:        copyConfig.force,
+		Yes:          copyConfig.yes,
+		Directive:    copyConfig.directive,
+		SkipMd5:      copyConfig.skipMd5,
+		Filter:       filter,
+		StorageClass: storageClass,
+		Tags:         tags,
+		Delete:       copyConfig.deleteTarget,
+		Move:         false,
+		Inventory:    inventory,
+		SSE:          sse,
+		SourceSSE:    sourceSSE,
 	}
 	headers := coshelper.ConvertStringToHeader(copyConfig.headers)
 	if copyConfig.recursive {
@@ -0,0 +1,85 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+type CORSCheckConfig struct {
+	origin, method string
+	headers        []string
+}
+
+var (
+	corsCheckConfig CORSCheckConfig
+	corsCheckCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "cors-check [-h] --origin ORIGIN [--method METHOD] [--header HEADER] KEY",
+		Short:                 "Check whether a browser request would be allowed by CORS rules",
+		Long: `Check whether a browser request would be allowed by CORS rules
+
+The CORS rules of bucket are fetched and evaluated locally, the first matching rule
+decides the response headers. Exit with code 1 if the request would be denied.
+
+KEY	COS path of the requested object`,
+		Args: cobra.ExactArgs(1),
+		RunE: corsCheck,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(corsCheckCmd)
+
+	corsCheckCmd.Flags().SortFlags = false
+	corsCheckCmd.Flags().StringVar(&corsCheckConfig.origin, "origin", "",
+		"Specify the origin of the request, like https://www.example.com")
+	corsCheckCmd.Flags().StringVar(&corsCheckConfig.method, "method", "GET",
+		"Specify the method of the request")
+	corsCheckCmd.Flags().StringSliceVar(&corsCheckConfig.headers, "header", nil,
+		"Specify headers the request carries, separated by commas or given multiple times")
+	_ = corsCheckCmd.MarkFlagRequired("origin")
+}
+
+func corsCheck(_ *cobra.Command, args []string) error {
+	cosPath := strings.TrimLeft(args[0], "/")
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	switch client.CORSCheck(cosPath, &cli.CORSCheckOption{
+		Origin:  corsCheckConfig.origin,
+		Method:  strings.ToUpper(corsCheckConfig.method),
+		Headers: corsCheckConfig.headers,
+	}) {
+	case 0:
+		return nil
+	case 1:
+		return coshelper.Error{
+			Code:    1,
+			Message: "request would be denied",
+		}
+	default:
+		return coshelper.Error{
+			Code:    -1,
+			Message: "cors check fail",
+		}
+	}
+}
@@ -29,15 +29,17 @@ import (
 )
 
 type DeleteConfig struct {
-	recursive, versions, force, yes bool
-	versionID                       string
+	recursive, versions, force, yes        bool
+	versionID, include, ignore, filterFrom string
+	fromInventory                          string
+	predicate                              PredicateConfig
 }
 
 var (
 	deleteConfig DeleteConfig
 	deleteCmd    = &cobra.Command{
 		DisableFlagsInUseLine: true,
-		Use:                   "delete [-h] [-r] [--versions] [--versionId VERSIONID] [-f] [-y] COS_PATH",
+		Use:                   "delete [-h] [-r] [--versions] [--versionId VERSIONID] [-f] [-y] [--include INCLUDE] [--ignore IGNORE] [--filter-from FILE] [--min-size SIZE] [--max-size SIZE] [--newer-than TIME] [--older-than TIME] [--class CLASS] [--regex REGEX] [--from-inventory MANIFEST] COS_PATH",
 		Short:                 "Delete file or files on COS",
 		Long: `Delete file or files on COS
 
@@ -61,6 +63,15 @@ func init() {
 		"Delete directly without confirmation")
 	deleteCmd.Flags().BoolVarP(&deleteConfig.yes, "yes", "y", false,
 		"Skip confirmation")
+	deleteCmd.Flags().StringVar(&deleteConfig.include, "include", "*",
+		"Specify filter rules when deleting recursively, separated by commas; Example: *.txt,*.docx,*.ppt")
+	deleteCmd.Flags().StringVar(&deleteConfig.ignore, "ignore", "",
+		"Specify ignored rules when deleting recursively, separated by commas; Example: *.txt,*.docx,*.ppt")
+	deleteCmd.Flags().StringVar(&deleteConfig.filterFrom, "filter-from", "",
+		"Read gitignore-style filter rules from file")
+	addPredicateFlags(deleteCmd.Flags(), &deleteConfig.predicate, true)
+	deleteCmd.Flags().StringVar(&deleteConfig.fromInventory, "from-inventory", "",
+		"Read objects from the inventory report with the manifest instead of listing them; Example: manifest.json")
 }
 
 func deleteCos(_ *cobra.Command, args []string) error {
@@ -70,11 +81,33 @@ func deleteCos(_ *cobra.Command, args []string) error {
 	for strings.HasPrefix(deleteCosPath, "/") {
 		deleteCosPath = deleteCosPath[1:]
 	}
+	filter, err := newFilter(deleteConfig.include, deleteConfig.ignore, deleteConfig.filterFrom)
+	if err != nil {
+		return err
+	}
+	if err := deleteConfig.predicate.apply(filter); err != nil {
+		return err
+	}
+	if deleteConfig.fromInventory != "" && (!deleteConfig.recursive || deleteConfig.versions) {
+		return coshelper.Error{
+			Code:    1,
+			Message: "--from-inventory only works with -r/--recursive and without --versions",
+		}
+	}
+	inventory, err := openInventory(client, deleteConfig.fromInventory, conf.Bucket)
+	if err != nil {
+		return err
+	}
+	if inventory != nil {
+		defer inventory.Close()
+	}
 	options := &cli.DeleteOption{
 		Force:     deleteConfig.force,
 		Yes:       deleteConfig.yes,
 		Versions:  deleteConfig.versions,
 		VersionID: deleteConfig.versionID,
+		Filter:    filter,
+		Inventory: inventory,
 	}
 	var ret int
 	if deleteConfig.recursive {
@@ -0,0 +1,87 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteTaggingConfig TaggingConfig
+	deleteTaggingCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use: "deletetagging [-h] [--bucket] [-r] [--include INCLUDE] [--ignore IGNORE] [--filter-from FILE]" +
+			" [--min-size SIZE] [--max-size SIZE] [--newer-than TIME] [--older-than TIME] [--class CLASS]" +
+			" [--regex REGEX] [COS_PATH]",
+		Short: "Delete tags of bucket or objects",
+		Long: `Delete tags of bucket or objects
+
+COS_PATH	COS path as a/b.txt, or the prefix of objects with -r; omitted with --bucket`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: deleteTagging,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(deleteTaggingCmd)
+
+	deleteTaggingCmd.Flags().SortFlags = false
+	addTaggingFlags(deleteTaggingCmd.Flags(), &deleteTaggingConfig, "Delete tags of the bucket", "Delete tags of objects with the prefix")
+}
+
+func deleteTagging(_ *cobra.Command, args []string) error {
+	if deleteTaggingConfig.bucket != (len(args) == 0) {
+		return coshelper.Error{
+			Code:    1,
+			Message: "COS_PATH must be given without --bucket, and omitted with --bucket",
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if deleteTaggingConfig.bucket {
+		if !client.DeleteBucketTagging() {
+			return coshelper.Error{
+				Code:    -1,
+				Message: "delete bucket tagging fail",
+			}
+		}
+		return nil
+	}
+	cosPath := strings.TrimLeft(args[0], "/")
+	var ret int
+	if deleteTaggingConfig.recursive {
+		options, err := deleteTaggingConfig.options()
+		if err != nil {
+			return err
+		}
+		ret = client.DeleteObjectTaggingFolder(cosPath, options)
+	} else {
+		ret = client.DeleteObjectTagging(cosPath)
+	}
+	if ret != 0 {
+		return coshelper.Error{
+			Code:    ret,
+			Message: "delete tagging fail",
+		}
+	}
+	return nil
+}
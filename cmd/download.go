@@ -17,7 +17,9 @@ limitations under the License.
 package cmd
 
 import (
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/huanght1997/cosutil/cli"
 	"github.com/huanght1997/cosutil/coshelper"
@@ -28,9 +30,12 @@ import (
 )
 
 type DownloadConfig struct {
-	force, yes, recursive, sync, skipMd5, delLocal bool
-	headers, versionID, include, ignore            string
-	num                                            int
+	force, yes, recursive, sync, skipMd5, delLocal, extract bool
+	headers, versionID, include, ignore, filterFrom, member string
+	asOf                                                    string
+	num                                                     int
+	predicate                                               PredicateConfig
+	sse                                                     SSEConfig
 }
 
 var (
@@ -38,11 +43,15 @@ var (
 	downloadConfig                     DownloadConfig
 	downloadCmd                        = &cobra.Command{
 		DisableFlagsInUseLine: true,
-		Use: "download [-h] [-f] [-y] [-r] [-s] [-H HEADERS] [--versionId VERSIONID] [--include INCLUDE] " +
-			"[--ignore IGNORE] [--skipmd5] [--delete] [-n NUM] COS_PATH LOCAL_PATH",
+		Use: "download [-h] [-f] [-y] [-r] [-s] [-H HEADERS] [--versionId VERSIONID] [--as-of TIME] [--include INCLUDE] " +
+			"[--ignore IGNORE] [--filter-from FILE] [--min-size SIZE] [--max-size SIZE] [--newer-than TIME] [--older-than TIME] " +
+			"[--class CLASS] [--regex REGEX] [--skipmd5] [--delete] [-n NUM] [--sse-c-key FILE] [--extract [--member MEMBER]] COS_PATH LOCAL_PATH",
 		Short: "Download file or directory from COS.",
 		Long: `Download file or directory from COS.
 
+With -r --as-of, the version of each object which was the latest at the time is downloaded
+from a versioned bucket, objects not existing at the time are skipped.
+
 COS_PATH	COS Path as a/b.txt
 LOCAL_PATH	Local file path as /tmp/a.txt`,
 		Args: cobra.ExactArgs(2),
@@ -65,17 +74,27 @@ func init() {
 	downloadCmd.Flags().StringVarP(&downloadConfig.headers, "headers", "H", "{}",
 		"Specify HTTP headers")
 	downloadCmd.Flags().StringVar(&downloadConfig.versionID, "versionId", "",
-		"Specify versionId of object to list")
+		"Specify versionId of object to download")
+	downloadCmd.Flags().StringVar(&downloadConfig.asOf, "as-of", "",
+		"Download the versions which were the latest at the time with -r, like 2026-09-01T00:00:00Z or 7d")
 	downloadCmd.Flags().StringVar(&downloadConfig.include, "include", "*",
 		"Specify filter rules, separated by commas: Example: *.txt,*.docx,*.ppt")
 	downloadCmd.Flags().StringVar(&downloadConfig.ignore, "ignore", "",
 		"Specify ignored rules, separated by commas; Example: *.txt,*.docx,*.ppt")
+	downloadCmd.Flags().StringVar(&downloadConfig.filterFrom, "filter-from", "",
+		"Read gitignore-style filter rules from file")
+	addPredicateFlags(downloadCmd.Flags(), &downloadConfig.predicate, true)
 	downloadCmd.Flags().BoolVar(&downloadConfig.skipMd5, "skipmd5", false,
 		"Download sync without check md5, only check filename and filesize")
 	downloadCmd.Flags().BoolVar(&downloadConfig.delLocal, "delete", false,
 		"Delete objects which exists in local but not exist in cos")
 	downloadCmd.Flags().IntVarP(&downloadConfig.num, "num", "n", 10,
 		"Specify max part num of multidownload")
+	addSSEFlags(downloadCmd.Flags(), &downloadConfig.sse, false)
+	downloadCmd.Flags().BoolVar(&downloadConfig.extract, "extract", false,
+		"Unpack the archive uploaded by 'upload --archive' into LOCAL_PATH")
+	downloadCmd.Flags().StringVar(&downloadConfig.member, "member", "",
+		"Only download the file in the archive, the archive must be uploaded with --index")
 }
 
 func download(_ *cobra.Command, args []string) error {
@@ -83,26 +102,77 @@ func download(_ *cobra.Command, args []string) error {
 	downloadCosPath = args[0]
 	conf := cli.LoadConf(cli.ConfigPath)
 	client := cli.NewClient(conf)
-	downloadCosPath, downloadLocalPath = concatPath(downloadCosPath, downloadLocalPath)
+	// LOCAL_PATH is the directory to unpack to when extracting archives.
+	if !downloadConfig.extract {
+		downloadCosPath, downloadLocalPath = concatPath(downloadCosPath, downloadLocalPath)
+	}
 	if strings.HasPrefix(downloadCosPath, "/") {
 		downloadCosPath = downloadCosPath[1:]
 	}
+	filter, err := newFilter(downloadConfig.include, downloadConfig.ignore, downloadConfig.filterFrom)
+	if err != nil {
+		return err
+	}
+	if err := downloadConfig.predicate.apply(filter); err != nil {
+		return err
+	}
 	options := &cli.DownloadOption{
 		Force:   downloadConfig.force,
 		Yes:     downloadConfig.yes,
 		Sync:    downloadConfig.sync,
 		Num:     downloadConfig.num,
-		Ignore:  strings.Split(downloadConfig.ignore, ","),
-		Include: strings.Split(downloadConfig.include, ","),
+		Filter:  filter,
 		SkipMd5: downloadConfig.skipMd5,
 		Delete:  downloadConfig.delLocal,
 	}
+	if downloadConfig.versionID != "" && (downloadConfig.recursive || downloadConfig.extract) {
+		return coshelper.Error{
+			Code:    1,
+			Message: "--versionId can not be used with -r/--recursive or --extract",
+		}
+	}
+	options.VersionID = downloadConfig.versionID
+	if downloadConfig.asOf != "" {
+		if !downloadConfig.recursive || downloadConfig.extract {
+			return coshelper.Error{
+				Code:    1,
+				Message: "--as-of only works with -r/--recursive",
+			}
+		}
+		asOf, err := coshelper.ParseTimeOrDuration(downloadConfig.asOf, time.Now())
+		if err != nil {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid --as-of option: " + err.Error(),
+			}
+		}
+		options.AsOf = asOf
+	}
 	if options.Num > 20 {
 		options.Num = 20
 	}
+	sse, err := downloadConfig.sse.build()
+	if err != nil {
+		return err
+	}
 	headers := coshelper.ConvertStringToHeader(downloadConfig.headers)
+	if headers == nil {
+		headers = &http.Header{}
+	}
+	sse.SetReadHeaders(*headers)
+	options.Headers = headers
 	var rt int
-	if downloadConfig.recursive {
+	if downloadConfig.member != "" {
+		if !downloadConfig.extract {
+			return coshelper.Error{
+				Code:    1,
+				Message: "--member can only be used with --extract",
+			}
+		}
+		rt = client.DownloadArchiveMember(downloadCosPath, downloadConfig.member, downloadLocalPath, options)
+	} else if downloadConfig.extract {
+		rt = client.ExtractArchive(downloadCosPath, downloadLocalPath, options)
+	} else if downloadConfig.recursive {
 		rt = client.DownloadFolder(downloadCosPath, downloadLocalPath, options)
 	} else {
 		rt = client.DownloadFile(downloadCosPath, downloadLocalPath, headers, options)
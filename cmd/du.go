@@ -0,0 +1,85 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+type DuConfig struct {
+	versions, uploads, human, json bool
+	depth                          int
+}
+
+var (
+	duConfig DuConfig
+	duCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "du [-h] [-d DEPTH] [--versions] [--uploads] [--human] [--json] [PREFIX]",
+		Short:                 "Summarize the size of objects on COS",
+		Long: `Summarize the number and size of objects under the prefix, by sub-prefixes and storage classes.
+
+[PREFIX]	COS path as a/, the whole bucket if absent`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: du,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(duCmd)
+
+	duCmd.Flags().SortFlags = false
+	duCmd.Flags().IntVarP(&duConfig.depth, "depth", "d", 0,
+		"Summarize sub-prefixes up to the depth under PREFIX, 0 for only the total")
+	duCmd.Flags().BoolVar(&duConfig.versions, "versions", false, "Count noncurrent versions")
+	duCmd.Flags().BoolVar(&duConfig.uploads, "uploads", false, "Count parts of incomplete multipart uploads")
+	duCmd.Flags().BoolVar(&duConfig.human, "human", false, "Humanized display")
+	duCmd.Flags().BoolVar(&duConfig.json, "json", false, "Print the summary in JSON")
+}
+
+func du(_ *cobra.Command, args []string) error {
+	cosPath := ""
+	if len(args) > 0 {
+		cosPath = strings.TrimLeft(args[0], "/")
+	}
+	if duConfig.depth < 0 {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid -d/--depth option: must not be negative",
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if !client.Du(cosPath, &cli.DuOption{
+		Depth:    duConfig.depth,
+		Versions: duConfig.versions,
+		Uploads:  duConfig.uploads,
+		Human:    duConfig.human,
+		JSON:     duConfig.json,
+	}) {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "du failed",
+		}
+	}
+	return nil
+}
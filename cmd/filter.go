@@ -0,0 +1,120 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/pflag"
+)
+
+// PredicateConfig is the selection of files by size, modification time, storage class and regular expression.
+type PredicateConfig struct {
+	minSize, maxSize, newerThan, olderThan, storageClass, regex string
+	// The value of the hidden --storage-class, which is mistaken for --class.
+	misusedStorageClass string
+}
+
+// Add the predicate flags to flags. storageClass tells whether --class makes sense for the command.
+// The storage class predicate is --class rather than --storage-class, because --storage-class of
+// upload, copy and move sets the storage class of target objects. Commands without their own
+// --storage-class get a hidden one which fails with a hint to --class.
+func addPredicateFlags(flags *pflag.FlagSet, config *PredicateConfig, storageClass bool) {
+	flags.StringVar(&config.minSize, "min-size", "",
+		"Only select files not smaller than the size; Example: 100K, 20M")
+	flags.StringVar(&config.maxSize, "max-size", "",
+		"Only select files not larger than the size; Example: 100K, 20M")
+	flags.StringVar(&config.newerThan, "newer-than", "",
+		"Only select files modified after the time or duration ago; Example: 2020-01-02, 36h, 7d")
+	flags.StringVar(&config.olderThan, "older-than", "",
+		"Only select files modified before the time or duration ago; Example: 2020-01-02, 36h, 7d")
+	if storageClass {
+		flags.StringVar(&config.storageClass, "class", "",
+			"Only select objects in the storage classes, separated by commas, not to be confused with "+
+				"--storage-class which sets the class of target objects; Example: STANDARD,ARCHIVE")
+		if flags.Lookup("storage-class") == nil {
+			flags.StringVar(&config.misusedStorageClass, "storage-class", "", "Use --class instead")
+			_ = flags.MarkHidden("storage-class")
+		}
+	}
+	flags.StringVar(&config.regex, "regex", "",
+		"Only select files whose relative path matches the regular expression")
+}
+
+// The error of --storage-class given to select objects.
+var errMisusedStorageClass = coshelper.Error{
+	Code:    1,
+	Message: "invalid --storage-class option: use --class to select objects by storage class",
+}
+
+// Apply the predicates to filter.
+func (config *PredicateConfig) apply(filter *coshelper.Filter) error {
+	if config.misusedStorageClass != "" {
+		return errMisusedStorageClass
+	}
+	var err error
+	minSize, maxSize := int64(-1), int64(-1)
+	if config.minSize != "" {
+		if minSize, err = coshelper.ParseSize(config.minSize); err != nil {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid --min-size option: " + err.Error(),
+			}
+		}
+	}
+	if config.maxSize != "" {
+		if maxSize, err = coshelper.ParseSize(config.maxSize); err != nil {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid --max-size option: " + err.Error(),
+			}
+		}
+	}
+	filter.SetSizeRange(minSize, maxSize)
+	now := time.Now()
+	var newerThan, olderThan time.Time
+	if config.newerThan != "" {
+		if newerThan, err = coshelper.ParseTimeOrDuration(config.newerThan, now); err != nil {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid --newer-than option: " + err.Error(),
+			}
+		}
+	}
+	if config.olderThan != "" {
+		if olderThan, err = coshelper.ParseTimeOrDuration(config.olderThan, now); err != nil {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid --older-than option: " + err.Error(),
+			}
+		}
+	}
+	filter.SetTimeRange(newerThan, olderThan)
+	filter.SetStorageClasses(strings.Split(config.storageClass, ","))
+	if config.regex != "" {
+		if err := filter.SetRegexp(config.regex); err != nil {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid --regex option: " + err.Error(),
+			}
+		}
+	}
+	return nil
+}
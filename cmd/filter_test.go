@@ -0,0 +1,50 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/pflag"
+)
+
+func TestMisusedStorageClass(t *testing.T) {
+	var config PredicateConfig
+	flags := pflag.NewFlagSet("delete", pflag.ContinueOnError)
+	addPredicateFlags(flags, &config, true)
+	if err := flags.Parse([]string{"--storage-class", "ARCHIVE"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.apply(coshelper.NewFilter(nil, nil)); err != errMisusedStorageClass {
+		t.Errorf("apply() = %v, want %v", err, errMisusedStorageClass)
+	}
+
+	// --storage-class of the command itself is kept.
+	var storageClass string
+	config = PredicateConfig{}
+	flags = pflag.NewFlagSet("copy", pflag.ContinueOnError)
+	flags.StringVar(&storageClass, "storage-class", "", "")
+	addPredicateFlags(flags, &config, true)
+	if err := flags.Parse([]string{"--storage-class", "ARCHIVE", "--class", "STANDARD"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.apply(coshelper.NewFilter(nil, nil)); err != nil || storageClass != "ARCHIVE" {
+		t.Errorf("apply() = %v, storage class %q, want nil, ARCHIVE", err, storageClass)
+	}
+}
@@ -0,0 +1,275 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type FindConfig struct {
+	deleteObjects, restore, print0, yes bool
+	name, size, mtime, class, regex     string
+	day                                 int
+	tier                                string
+	presign                             time.Duration
+	misusedStorageClass                 string
+}
+
+var (
+	findConfig FindConfig
+	findCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "find [-h] [--name PATTERN] [--size [+-]SIZE] [--mtime [+-]DAYS] [--class CLASS] [--regex REGEX] [--delete [-y]] [--restore [-d DAY] [-t {Expedited,Standard,Bulk}]] [--presign DURATION] [--print0] [PREFIX]",
+		Short:                 "Find objects on COS",
+		Long: `Find objects under the prefix with predicates, print them or run actions on them.
+
+Like find, --size +100M selects objects larger than 100M, -100M smaller than 100M and 100M exactly 100M.
+--mtime +30 selects objects modified more than 30 days ago, -30 less than 30 days ago, and 30 between
+30 and 31 days ago. The keys are printed if no action is specified.
+Long options can also be written with a single dash like find, as -name '*.log' -mtime +30.
+
+[PREFIX]	COS path as a/, the whole bucket if absent`,
+		// Flags are parsed by find, so that single-dash long options are accepted.
+		DisableFlagParsing: true,
+		RunE:               find,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(findCmd)
+
+	findCmd.Flags().SortFlags = false
+	findCmd.Flags().StringVar(&findConfig.name, "name", "",
+		"Only find objects whose base names match the patterns, separated by commas; Example: *.log,*.gz")
+	findCmd.Flags().StringVar(&findConfig.size, "size", "",
+		"Only find objects of the size; Example: +100M, -1K")
+	findCmd.Flags().StringVar(&findConfig.mtime, "mtime", "",
+		"Only find objects modified the days ago; Example: +30, -7")
+	findCmd.Flags().StringVar(&findConfig.class, "class", "",
+		"Only find objects in the storage classes, separated by commas, not to be confused with "+
+			"--storage-class of upload, copy and move; Example: STANDARD,ARCHIVE")
+	findCmd.Flags().StringVar(&findConfig.misusedStorageClass, "storage-class", "", "Use --class instead")
+	_ = findCmd.Flags().MarkHidden("storage-class")
+	findCmd.Flags().StringVar(&findConfig.regex, "regex", "",
+		"Only find objects whose relative path matches the regular expression")
+	findCmd.Flags().BoolVar(&findConfig.deleteObjects, "delete", false, "Delete the found objects")
+	findCmd.Flags().BoolVarP(&findConfig.yes, "yes", "y", false, "Skip confirmation of --delete")
+	findCmd.Flags().BoolVar(&findConfig.restore, "restore", false, "Restore the found objects")
+	findCmd.Flags().IntVarP(&findConfig.day, "day", "d", 7, "Specify lifetime of the restored (active) copy of --restore")
+	findCmd.Flags().StringVarP(&findConfig.tier, "tier", "t", defaultRestoreTier, "Specify the data access tier of --restore")
+	findCmd.Flags().DurationVar(&findConfig.presign, "presign", 0,
+		"Print the download URLs of the found objects valid for the duration; Example: 1h")
+	findCmd.Flags().BoolVar(&findConfig.print0, "print0", false,
+		"Print the keys separated by NUL instead of newline, for xargs -0")
+}
+
+func find(cmd *cobra.Command, args []string) error {
+	if err := cmd.Flags().Parse(findArgs(cmd.Flags(), args)); err != nil {
+		return err
+	}
+	if help, _ := cmd.Flags().GetBool("help"); help {
+		return cmd.Help()
+	}
+	args = cmd.Flags().Args()
+	if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+		return err
+	}
+	cosPath := ""
+	if len(args) > 0 {
+		cosPath = strings.TrimLeft(args[0], "/")
+	}
+	filter := coshelper.NewFilter(nil, nil)
+	filter.SetNames(strings.Split(findConfig.name, ","))
+	if findConfig.size != "" {
+		minSize, maxSize, err := parseFindSize(findConfig.size)
+		if err != nil {
+			return err
+		}
+		filter.SetSizeRange(minSize, maxSize)
+	}
+	if findConfig.mtime != "" {
+		newerThan, olderThan, err := parseFindMtime(findConfig.mtime, time.Now())
+		if err != nil {
+			return err
+		}
+		filter.SetTimeRange(newerThan, olderThan)
+	}
+	classes := make([]string, 0)
+	for _, class := range strings.Split(findConfig.class, ",") {
+		if class == "" {
+			continue
+		}
+		class, err := checkStorageClass(class, "--class")
+		if err != nil {
+			return err
+		}
+		classes = append(classes, class)
+	}
+	if findConfig.misusedStorageClass != "" {
+		return errMisusedStorageClass
+	}
+	filter.SetStorageClasses(classes)
+	if findConfig.regex != "" {
+		if err := filter.SetRegexp(findConfig.regex); err != nil {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid --regex option: " + err.Error(),
+			}
+		}
+	}
+	if findConfig.presign < 0 {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid --presign option: must be positive",
+		}
+	}
+	options := &cli.FindOption{
+		Filter:  filter,
+		Delete:  findConfig.deleteObjects,
+		Presign: findConfig.presign,
+		Print0:  findConfig.print0,
+		Yes:     findConfig.yes,
+	}
+	if findConfig.restore {
+		tier, err := parseRestoreTier(findConfig.tier, "-t")
+		if err != nil {
+			return err
+		}
+		options.Restore = &cli.RestoreOption{
+			Day:  findConfig.day,
+			Tier: tier,
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	ret := client.Find(cosPath, options)
+	switch ret {
+	case 0:
+		return nil
+	case -3:
+		log.Info("operation canceled by user")
+		return nil
+	default:
+		return coshelper.Error{
+			Code:    ret,
+			Message: "find failed",
+		}
+	}
+}
+
+// Rewrite the long options written with a single dash like find, as -name to --name.
+// Values of options and arguments after "--" are left as they are.
+func findArgs(flags *pflag.FlagSet, args []string) []string {
+	result := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			result = append(result, args[i:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			result = append(result, arg)
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		hasValue := strings.Contains(name, "=")
+		if hasValue {
+			name = name[:strings.Index(name, "=")]
+		}
+		var flag *pflag.Flag
+		if len(name) == 1 {
+			flag = flags.ShorthandLookup(name)
+		} else {
+			flag = flags.Lookup(name)
+			if flag != nil && !strings.HasPrefix(arg, "--") {
+				arg = "-" + arg
+			}
+		}
+		result = append(result, arg)
+		// The next argument is the value of the option, even if it starts with a dash like -size -1M.
+		if flag != nil && flag.NoOptDefVal == "" && !hasValue && i+1 < len(args) {
+			i++
+			result = append(result, args[i])
+		}
+	}
+	return result
+}
+
+// Parse the size like find: +N for larger than N, -N for smaller than N, N for exactly N.
+func parseFindSize(str string) (minSize int64, maxSize int64, err error) {
+	sign, value := splitFindSign(str)
+	size, err := coshelper.ParseSize(value)
+	if err != nil {
+		return 0, 0, coshelper.Error{
+			Code:    1,
+			Message: "invalid --size option: " + err.Error(),
+		}
+	}
+	switch sign {
+	case '+':
+		return size + 1, -1, nil
+	case '-':
+		if size == 0 {
+			return 0, 0, coshelper.Error{
+				Code:    1,
+				Message: "invalid --size option: no object is smaller than 0",
+			}
+		}
+		return -1, size - 1, nil
+	default:
+		return size, size, nil
+	}
+}
+
+// Parse the days like find: +N for modified more than N days ago, -N for less than N days ago,
+// N for between N and N+1 days ago.
+func parseFindMtime(str string, now time.Time) (newerThan time.Time, olderThan time.Time, err error) {
+	sign, value := splitFindSign(str)
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return time.Time{}, time.Time{}, coshelper.Error{
+			Code:    1,
+			Message: "invalid --mtime option: should be days like +30, -7 or 1",
+		}
+	}
+	day := 24 * time.Hour
+	switch sign {
+	case '+':
+		return time.Time{}, now.Add(-time.Duration(days) * day), nil
+	case '-':
+		return now.Add(-time.Duration(days) * day), time.Time{}, nil
+	default:
+		return now.Add(-time.Duration(days+1) * day), now.Add(-time.Duration(days) * day), nil
+	}
+}
+
+func splitFindSign(str string) (byte, string) {
+	str = strings.TrimSpace(str)
+	if strings.HasPrefix(str, "+") || strings.HasPrefix(str, "-") {
+		return str[0], str[1:]
+	}
+	return 0, str
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFindSize(t *testing.T) {
+	tests := []struct {
+		str              string
+		minSize, maxSize int64
+	}{
+		{"+100M", 100<<20 + 1, -1},
+		{"-1K", -1, 1023},
+		{"100", 100, 100},
+		{" 0 ", 0, 0},
+		{"+0", 1, -1},
+	}
+	for _, test := range tests {
+		minSize, maxSize, err := parseFindSize(test.str)
+		if err != nil {
+			t.Errorf("parseFindSize(%q) returns error: %v", test.str, err)
+		} else if minSize != test.minSize || maxSize != test.maxSize {
+			t.Errorf("parseFindSize(%q) = %d, %d, want %d, %d", test.str, minSize, maxSize, test.minSize, test.maxSize)
+		}
+	}
+	for _, str := range []string{"", "+", "-0", "1X", "++1"} {
+		if _, _, err := parseFindSize(str); err == nil {
+			t.Errorf("parseFindSize(%q) returns no error", str)
+		}
+	}
+}
+
+func TestParseFindMtime(t *testing.T) {
+	now := time.Date(2020, 6, 15, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	tests := []struct {
+		str                  string
+		newerThan, olderThan time.Time
+	}{
+		{"+30", time.Time{}, now.Add(-30 * day)},
+		{"-7", now.Add(-7 * day), time.Time{}},
+		{"1", now.Add(-2 * day), now.Add(-day)},
+		{"0", now.Add(-day), now},
+	}
+	for _, test := range tests {
+		newerThan, olderThan, err := parseFindMtime(test.str, now)
+		if err != nil {
+			t.Errorf("parseFindMtime(%q) returns error: %v", test.str, err)
+		} else if !newerThan.Equal(test.newerThan) || !olderThan.Equal(test.olderThan) {
+			t.Errorf("parseFindMtime(%q) = %v, %v, want %v, %v",
+				test.str, newerThan, olderThan, test.newerThan, test.olderThan)
+		}
+	}
+	for _, str := range []string{"", "+", "1.5", "7d", "+-1", "--1"} {
+		if _, _, err := parseFindMtime(str, now); err == nil {
+			t.Errorf("parseFindMtime(%q) returns no error", str)
+		}
+	}
+}
+
+func TestFindArgs(t *testing.T) {
+	tests := []struct {
+		args, want []string
+	}{
+		{[]string{"-name", "*.log", "-mtime", "+30", "a/"}, []string{"--name", "*.log", "--mtime", "+30", "a/"}},
+		{[]string{"-size", "-1M", "-class=ARCHIVE"}, []string{"--size", "-1M", "--class=ARCHIVE"}},
+		{[]string{"--size", "-1M", "--print0"}, []string{"--size", "-1M", "--print0"}},
+		{[]string{"-delete", "-y", "-print0"}, []string{"--delete", "-y", "--print0"}},
+		{[]string{"-restore", "-d", "-1", "-t", "Bulk"}, []string{"--restore", "-d", "-1", "-t", "Bulk"}},
+		{[]string{"-name", "-regex", "-regex", "-name"}, []string{"--name", "-regex", "--regex", "-name"}},
+		{[]string{"-presign", "1h", "-h"}, []string{"--presign", "1h", "-h"}},
+		{[]string{"-unknown", "-yd7"}, []string{"-unknown", "-yd7"}},
+		{[]string{"-name", "a", "--", "-name"}, []string{"--name", "a", "--", "-name"}},
+	}
+	flags := findCmd.Flags()
+	findCmd.InitDefaultHelpFlag()
+	for _, test := range tests {
+		if got := findArgs(flags, test.args); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("findArgs(%q) = %q, want %q", test.args, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	getBucketInventoryJSON bool
+	getBucketInventoryCmd  = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "getbucketinventory [-h] [--json] [ID]",
+		Short:                 "Get the inventories of bucket",
+		Long: `Get the inventories of bucket
+
+[ID]	Only get the inventory with the id`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: getBucketInventory,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(getBucketInventoryCmd)
+
+	getBucketInventoryCmd.Flags().BoolVar(&getBucketInventoryJSON, "json", false,
+		"Print inventories in JSON, an inventory given by ID can be used by putbucketinventory")
+}
+
+func getBucketInventory(_ *cobra.Command, args []string) error {
+	id := ""
+	if len(args) > 0 {
+		id = args[0]
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if !client.GetBucketInventory(id, getBucketInventoryJSON) {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "get bucket inventory fail",
+		}
+	}
+	return nil
+}
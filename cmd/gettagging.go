@@ -0,0 +1,70 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	getTaggingBucket bool
+	getTaggingCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "gettagging [-h] [--bucket] [COS_PATH]",
+		Short:                 "Get tags of bucket or object",
+		Long: `Get tags of bucket or object
+
+COS_PATH	COS path as a/b.txt; omitted with --bucket`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: getTagging,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(getTaggingCmd)
+
+	getTaggingCmd.Flags().BoolVar(&getTaggingBucket, "bucket", false, "Get tags of the bucket")
+}
+
+func getTagging(_ *cobra.Command, args []string) error {
+	if getTaggingBucket != (len(args) == 0) {
+		return coshelper.Error{
+			Code:    1,
+			Message: "COS_PATH must be given without --bucket, and omitted with --bucket",
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	var ok bool
+	if getTaggingBucket {
+		ok = client.GetBucketTagging()
+	} else {
+		ok = client.GetObjectTagging(strings.TrimLeft(args[0], "/"))
+	}
+	if !ok {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "get tagging fail",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	headConfig CatConfig
+	headCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "head [-h] [-n LINES | -c BYTES] [--version-id VERSION_ID] [--sse-c-key FILE] COS_PATH",
+		Short:                 "Print the first part of file on COS",
+		Long: `Print the first lines or bytes of file on COS to the standard output, files with Content-Encoding gzip
+or zstd, like those uploaded with --compress, are decompressed
+
+COS_PATH	COS Path as a/b.txt`,
+		Args: cobra.ExactArgs(1),
+		RunE: headObject,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(headCmd)
+
+	headCmd.Flags().SortFlags = false
+	headCmd.Flags().Int64VarP(&headConfig.lines, "lines", "n", 10, "Print the first lines")
+	headCmd.Flags().Int64VarP(&headConfig.bytes, "bytes", "c", 0, "Print the first bytes instead of lines")
+	addCatFlags(headCmd, &headConfig)
+}
+
+func headObject(cmd *cobra.Command, args []string) error {
+	return catPart(cmd, args[0], &headConfig, false)
+}
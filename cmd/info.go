@@ -28,7 +28,7 @@ import (
 var (
 	infoCmd = &cobra.Command{
 		DisableFlagsInUseLine: true,
-		Use:                   "info [-h] [--human] COS_PATH",
+		Use:                   "info [-h] [--human] [--sse-c-key FILE] COS_PATH",
 		Short:                 "Get the information of file on COS",
 		Long: `Get the information of file on COS
 
@@ -36,7 +36,8 @@ COS_PATH	COS Path as a/b.txt`,
 		Args: cobra.ExactArgs(1),
 		RunE: info,
 	}
-	human bool
+	human   bool
+	infoSSE SSEConfig
 )
 
 func init() {
@@ -44,13 +45,18 @@ func init() {
 
 	infoCmd.Flags().SortFlags = false
 	infoCmd.Flags().BoolVar(&human, "human", false, "Humanized display")
+	addSSEFlags(infoCmd.Flags(), &infoSSE, false)
 }
 
 func info(_ *cobra.Command, args []string) error {
 	cosPath := strings.TrimLeft(args[0], "/")
+	sse, err := infoSSE.build()
+	if err != nil {
+		return err
+	}
 	conf := cli.LoadConf(cli.ConfigPath)
 	client := cli.NewClient(conf)
-	if !client.InfoObject(cosPath, human) {
+	if !client.InfoObject(cosPath, human, sse) {
 		return coshelper.Error{
 			Code:    -1,
 			Message: "info object failed",
@@ -26,15 +26,16 @@ import (
 )
 
 type ListConfig struct {
-	all, recursive, versions, human bool
-	num                             int
+	all, recursive, versions, human, long bool
+	num                                   int
+	fromInventory                         string
 }
 
 var (
 	listConfig ListConfig
 	listCmd    = &cobra.Command{
 		DisableFlagsInUseLine: true,
-		Use:                   "list [-h] [-a] [-r] [-n NUM] [-v] [--human] [COS_PATH]",
+		Use:                   "list [-h] [-a] [-r] [-n NUM] [-v] [-l] [--human] [--from-inventory MANIFEST] [COS_PATH]",
 		Short:                 "List files on COS",
 		Long: `List files on COS
 
@@ -52,7 +53,10 @@ func init() {
 	listCmd.Flags().BoolVarP(&listConfig.recursive, "recursive", "r", false, "List files recursively")
 	listCmd.Flags().IntVarP(&listConfig.num, "num", "n", 100, "Specify max num of files to list")
 	listCmd.Flags().BoolVarP(&listConfig.versions, "versions", "v", false, "List objects with versions")
+	listCmd.Flags().BoolVarP(&listConfig.long, "long", "l", false, "List objects with their tags")
 	listCmd.Flags().BoolVar(&listConfig.human, "human", false, "Humanized display")
+	listCmd.Flags().StringVar(&listConfig.fromInventory, "from-inventory", "",
+		"Read objects from the inventory report with the manifest instead of listing them; Example: manifest.json")
 }
 
 func cosList(_ *cobra.Command, args []string) error {
@@ -63,12 +67,27 @@ func cosList(_ *cobra.Command, args []string) error {
 	conf := cli.LoadConf(cli.ConfigPath)
 	client := cli.NewClient(conf)
 	cosPath = strings.TrimLeft(cosPath, "/")
+	if listConfig.fromInventory != "" && listConfig.versions {
+		return coshelper.Error{
+			Code:    1,
+			Message: "--from-inventory cannot be used with -v/--versions",
+		}
+	}
+	inventory, err := openInventory(client, listConfig.fromInventory, conf.Bucket)
+	if err != nil {
+		return err
+	}
+	if inventory != nil {
+		defer inventory.Close()
+	}
 	options := &cli.ListOption{
 		Recursive: listConfig.recursive,
 		All:       listConfig.all,
 		Num:       listConfig.num,
 		Human:     listConfig.human,
 		Versions:  listConfig.versions,
+		Long:      listConfig.long,
+		Inventory: inventory,
 	}
 	if !client.ListObjects(cosPath, options) {
 		return coshelper.Error{
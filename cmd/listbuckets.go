@@ -0,0 +1,76 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+type ListBucketsConfig struct {
+	versioning, stat, human, json bool
+	region                        string
+}
+
+var (
+	listBucketsConfig ListBucketsConfig
+	listBucketsCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "listbuckets [-h] [--region REGION] [--versioning] [--stat] [--human] [--json]",
+		Short:                 "List buckets of the account",
+		Long: `List buckets of the account in every region, with their regions and creation dates.
+
+--stat lists every object of each bucket, which takes long for large buckets. Noncurrent versions
+and uncompleted multipart uploads are not counted.`,
+		Args: cobra.ExactArgs(0),
+		RunE: listBuckets,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(listBucketsCmd)
+
+	listBucketsCmd.Flags().SortFlags = false
+	listBucketsCmd.Flags().StringVar(&listBucketsConfig.region, "region", "",
+		"Only list buckets in the region; Example: ap-guangzhou")
+	listBucketsCmd.Flags().BoolVar(&listBucketsConfig.versioning, "versioning", false,
+		"Show the versioning state of buckets")
+	listBucketsCmd.Flags().BoolVar(&listBucketsConfig.stat, "stat", false,
+		"Show the number and size of objects in buckets")
+	listBucketsCmd.Flags().BoolVar(&listBucketsConfig.human, "human", false, "Humanized display")
+	listBucketsCmd.Flags().BoolVar(&listBucketsConfig.json, "json", false, "Print buckets in JSON")
+}
+
+func listBuckets(*cobra.Command, []string) error {
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if !client.ListBuckets(&cli.ListBucketsOption{
+		Region:     listBucketsConfig.region,
+		Versioning: listBucketsConfig.versioning,
+		Stat:       listBucketsConfig.stat,
+		Human:      listBucketsConfig.human,
+		JSON:       listBucketsConfig.json,
+	}) {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "list buckets fail",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+type LogsAnalyzeConfig struct {
+	from, since, until string
+	top                int
+	json               bool
+}
+
+var (
+	logsAnalyzeConfig LogsAnalyzeConfig
+	logsCmd           = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "logs [-h] {analyze}",
+		Short:                 "Work with access logs",
+	}
+	logsAnalyzeCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "analyze [-h] [--from BUCKET] [--since TIME] [--until TIME] [--top N] [--json] PREFIX",
+		Short:                 "Aggregate access logs",
+		Long: `Download the access log objects with the prefix and aggregate them locally:
+top keys, top client IPs, bytes per operation, and 4xx/5xx rates per hour.
+
+PREFIX	COS path prefix of log objects as logs/`,
+		Args: cobra.ExactArgs(1),
+		RunE: logsAnalyze,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.AddCommand(logsAnalyzeCmd)
+
+	logsAnalyzeCmd.Flags().SortFlags = false
+	logsAnalyzeCmd.Flags().StringVar(&logsAnalyzeConfig.from, "from", "",
+		"Read logs from the bucket as 'bucket-appid.cos.ap-guangzhou.myqcloud.com' instead of the configured bucket")
+	logsAnalyzeCmd.Flags().StringVar(&logsAnalyzeConfig.since, "since", "",
+		"Only count requests after the time or duration ago; Example: 2020-01-02, 36h, 7d")
+	logsAnalyzeCmd.Flags().StringVar(&logsAnalyzeConfig.until, "until", "",
+		"Only count requests before the time or duration ago; Example: 2020-01-02, 36h, 7d")
+	logsAnalyzeCmd.Flags().IntVar(&logsAnalyzeConfig.top, "top", 10,
+		"Specify the number of top keys and client IPs")
+	logsAnalyzeCmd.Flags().BoolVar(&logsAnalyzeConfig.json, "json", false, "Print the result in JSON")
+}
+
+func logsAnalyze(_ *cobra.Command, args []string) error {
+	cosPath := strings.TrimLeft(args[0], "/")
+	if logsAnalyzeConfig.top <= 0 {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid --top option: must be positive",
+		}
+	}
+	options := &cli.LogsAnalyzeOption{
+		SourcePath: logsAnalyzeConfig.from,
+		Top:        logsAnalyzeConfig.top,
+		JSON:       logsAnalyzeConfig.json,
+	}
+	now := time.Now()
+	var err error
+	if logsAnalyzeConfig.since != "" {
+		if options.Since, err = coshelper.ParseTimeOrDuration(logsAnalyzeConfig.since, now); err != nil {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid --since option: " + err.Error(),
+			}
+		}
+	}
+	if logsAnalyzeConfig.until != "" {
+		if options.Until, err = coshelper.ParseTimeOrDuration(logsAnalyzeConfig.until, now); err != nil {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid --until option: " + err.Error(),
+			}
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if client.LogsAnalyze(cosPath, options) != 0 {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "logs analyze failed",
+		}
+	}
+	return nil
+}
@@ -29,8 +29,9 @@ import (
 var (
 	moveCmd = &cobra.Command{
 		DisableFlagsInUseLine: true,
-		Use: "move [-h] [-H HEADERS] [-d {Copy, Replaced}] [-r]" +
-			" [--include INCLUDE] [--ignore IGNORE] SOURCE_PATH COS_PATH",
+		Use: "move [-h] [-H HEADERS] [-d {Copy, Replaced}] [--storage-class CLASS] [--tags TAGS] [-r]" +
+			" [--include INCLUDE] [--ignore IGNORE] [--filter-from FILE]" +
+			" [--min-size SIZE] [--max-size SIZE] [--newer-than TIME] [--older-than TIME] [--class CLASS] [--regex REGEX] SOURCE_PATH COS_PATH",
 		Short: "Move file from COS to COS",
 		Long: `Move file from COS to COS
 
@@ -47,11 +48,18 @@ func init() {
 	moveCmd.Flags().SortFlags = false
 	moveCmd.Flags().StringVarP(&copyConfig.headers, "headers", "H", "{}", "Specify HTTP headers")
 	moveCmd.Flags().StringVarP(&copyConfig.directive, "directive", "d", "Copy", "if Overwrite headers")
+	moveCmd.Flags().StringVar(&copyConfig.storageClass, "storage-class", "",
+		"Specify the storage class of target objects: "+strings.Join(cli.StorageClasses, ", "))
+	moveCmd.Flags().StringVar(&copyConfig.tags, "tags", "",
+		"Specify tags replacing the tags of source objects, separated by commas; Example: project=a,env=prod")
 	moveCmd.Flags().BoolVarP(&copyConfig.recursive, "recursive", "r", false, "Move files recursively")
 	moveCmd.Flags().StringVar(&copyConfig.include, "include", "*",
 		"Specify filter rules, separated by commas; Example: *.txt,*.docx,*.ppt")
 	moveCmd.Flags().StringVar(&copyConfig.ignore, "ignore", "",
 		"Specify ignored rules, separated by commas; Example: *.txt,*.docx,*.ppt")
+	moveCmd.Flags().StringVar(&copyConfig.filterFrom, "filter-from", "",
+		"Read gitignore-style filter rules from file")
+	addPredicateFlags(moveCmd.Flags(), &copyConfig.predicate, true)
 }
 
 func move(_ *cobra.Command, args []string) error {
@@ -67,15 +75,34 @@ func move(_ *cobra.Command, args []string) error {
 			Message: "-d/--directive flags must be 'Copy' or 'Replaced'",
 		}
 	}
+	storageClass := ""
+	if copyConfig.storageClass != "" {
+		var err error
+		if storageClass, err = checkStorageClass(copyConfig.storageClass, "--storage-class"); err != nil {
+			return err
+		}
+	}
+	tags, err := parseObjectTags(copyConfig.tags, "--tags")
+	if err != nil {
+		return err
+	}
+	filter, err := newFilter(copyConfig.include, copyConfig.ignore, copyConfig.filterFrom)
+	if err != nil {
+		return err
+	}
+	if err := copyConfig.predicate.apply(filter); err != nil {
+		return err
+	}
 	options := &cli.CopyOption{
-		Sync:      false,
-		Force:     true,
-		Directive: copyConfig.directive,
-		SkipMd5:   true,
-		Ignore:    strings.Split(copyConfig.ignore, ","),
-		Include:   strings.Split(copyConfig.include, ","),
-		Delete:    false,
-		Move:      true,
+		Sync:         false,
+		Force:        true,
+		Directive:    copyConfig.directive,
+		SkipMd5:      true,
+		Filter:       filter,
+		StorageClass: storageClass,
+		Tags:         tags,
+		Delete:       false,
+		Move:         true,
 	}
 	headers := coshelper.ConvertStringToHeader(copyConfig.headers)
 	if copyConfig.recursive {
@@ -0,0 +1,130 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+type PolicySimulateConfig struct {
+	policy, principal, action, resource, ip string
+	conditions                              []string
+}
+
+var (
+	policySimulateConfig PolicySimulateConfig
+	policyCmd            = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "policy [-h] {simulate}",
+		Short:                 "Review bucket policies",
+	}
+	policySimulateCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use: "simulate [-h] [--policy FILE] --principal PRINCIPAL --action ACTION [--ip IP]" +
+			" [--condition KEY=VALUE] --resource KEY",
+		Short: "Evaluate a policy locally",
+		Long: `Evaluate a policy locally, explaining whether a request would be allowed
+
+An explicit deny overrides any allow, and requests matching no statement are denied.
+Exit with code 1 if the request would be denied.
+
+PRINCIPAL can be a principal name like qcs::cam::uin/100000000001:uin/100000000011,
+a UIN of root account, or anyone.
+KEY can be a COS path in the bucket of configuration, or a full resource name
+like qcs::cos:ap-guangzhou:uid/1250000000:examplebucket-1250000000/a.txt.
+With --policy FILE and a full resource name, no configuration or credentials are needed.`,
+		Args: cobra.ExactArgs(0),
+		RunE: policySimulate,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policySimulateCmd)
+
+	policySimulateCmd.Flags().SortFlags = false
+	policySimulateCmd.Flags().StringVar(&policySimulateConfig.policy, "policy", "",
+		"Specify the JSON or YAML policy file, the policy of bucket is used if not specified")
+	policySimulateCmd.Flags().StringVar(&policySimulateConfig.principal, "principal", "",
+		"Specify who sends the request")
+	policySimulateCmd.Flags().StringVar(&policySimulateConfig.action, "action", "",
+		"Specify the action, like name/cos:GetObject")
+	policySimulateCmd.Flags().StringVar(&policySimulateConfig.resource, "resource", "",
+		"Specify the object of the request")
+	policySimulateCmd.Flags().StringVar(&policySimulateConfig.ip, "ip", "",
+		"Specify the source IP of the request, the value of qcs:ip")
+	policySimulateCmd.Flags().StringArrayVar(&policySimulateConfig.conditions, "condition", nil,
+		"Specify the value of a condition key, like cos:content-type=image/png; can be given multiple times")
+	_ = policySimulateCmd.MarkFlagRequired("principal")
+	_ = policySimulateCmd.MarkFlagRequired("action")
+	_ = policySimulateCmd.MarkFlagRequired("resource")
+}
+
+func policySimulate(*cobra.Command, []string) error {
+	var policy *cli.PolicyDocument
+	if policySimulateConfig.policy != "" {
+		var err error
+		if policy, err = loadPolicy(policySimulateConfig.policy); err != nil {
+			return err
+		}
+	}
+	context := make(map[string]string)
+	for _, condition := range policySimulateConfig.conditions {
+		kv := strings.SplitN(condition, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid condition '" + condition + "', should be KEY=VALUE",
+			}
+		}
+		context[kv[0]] = kv[1]
+	}
+	if policySimulateConfig.ip != "" {
+		context["qcs:ip"] = policySimulateConfig.ip
+	}
+	// The configuration is only needed to fetch the policy of the bucket or to expand a COS path,
+	// so that policy files can be reviewed offline, like on CI without credentials.
+	var client *cli.Client
+	if policy == nil || !strings.HasPrefix(policySimulateConfig.resource, "qcs::") {
+		conf := cli.LoadConf(cli.ConfigPath)
+		client = cli.NewClient(conf)
+	}
+	switch client.SimulatePolicy(policy, &cli.PolicySimulateOption{
+		Principal: policySimulateConfig.principal,
+		Action:    policySimulateConfig.action,
+		Resource:  policySimulateConfig.resource,
+		Context:   context,
+	}) {
+	case 0:
+		return nil
+	case 1:
+		return coshelper.Error{
+			Code:    1,
+			Message: "request would be denied",
+		}
+	default:
+		return coshelper.Error{
+			Code:    -1,
+			Message: "policy simulate fail",
+		}
+	}
+}
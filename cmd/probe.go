@@ -77,8 +77,6 @@ func probe(*cobra.Command, []string) error {
 		ret := client.UploadFile(filename, filename, header, &cli.UploadOption{
 			SkipMd5: true,
 			Sync:    false,
-			Include: []string{"*"},
-			Ignore:  []string{""},
 			Force:   true,
 		})
 		timeEnd := time.Now().UnixNano()
@@ -99,8 +97,6 @@ func probe(*cobra.Command, []string) error {
 			Force:   true,
 			Sync:    false,
 			Num:     10,
-			Ignore:  []string{""},
-			Include: []string{"*"},
 			SkipMd5: true,
 		})
 		timeEnd = time.Now().UnixNano()
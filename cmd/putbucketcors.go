@@ -0,0 +1,81 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+var (
+	putBucketCORSCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "putbucketcors [-h] FILE",
+		Short:                 "Set the CORS rules of bucket",
+		Long: `Set the CORS rules of bucket, the old rules are replaced
+
+FILE	JSON or YAML file of rules, like the output of getbucketcors --json:
+
+rules:
+  - id: web
+    allowedOrigins:
+      - https://*.example.com
+    allowedMethods:
+      - GET
+      - HEAD
+    allowedHeaders:
+      - "*"
+    exposeHeaders:
+      - ETag
+    maxAgeSeconds: 600`,
+		Args: cobra.ExactArgs(1),
+		RunE: putBucketCORS,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(putBucketCORSCmd)
+}
+
+func putBucketCORS(_ *cobra.Command, args []string) error {
+	filePath, _ := homedir.Expand(args[0])
+	var config cli.CORSConfiguration
+	if err := coshelper.UnmarshalFile(filePath, &config); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid CORS file: " + err.Error(),
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid CORS rules: " + err.Error(),
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if !client.PutBucketCORS(&config) {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "put bucket CORS fail",
+		}
+	}
+	return nil
+}
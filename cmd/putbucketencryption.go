@@ -0,0 +1,67 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	putBucketEncryptionKMSKeyID string
+	putBucketEncryptionCmd      = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "putbucketencryption [-h] [--kms-key-id ID] {cos,kms}",
+		Short:                 "Set the default encryption of bucket",
+		Long: `Set the default encryption of bucket, new objects are encrypted on the server side
+
+{cos,kms}	SSE-COS with keys managed by COS, or SSE-KMS with the key in KMS`,
+		Args: cobra.ExactArgs(1),
+		RunE: putBucketEncryption,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(putBucketEncryptionCmd)
+
+	putBucketEncryptionCmd.Flags().StringVar(&putBucketEncryptionKMSKeyID, "kms-key-id", "",
+		"Specify the KMS key instead of the default key")
+}
+
+func putBucketEncryption(_ *cobra.Command, args []string) error {
+	config := &cli.BucketEncryption{
+		Algorithm:      args[0],
+		KMSMasterKeyID: putBucketEncryptionKMSKeyID,
+	}
+	if err := config.Validate(); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid encryption: " + err.Error(),
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if !client.PutBucketEncryption(config) {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "put bucket encryption fail",
+		}
+	}
+	return nil
+}
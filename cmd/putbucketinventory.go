@@ -0,0 +1,112 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+var (
+	putBucketInventoryCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "putbucketinventory [-h] FILE",
+		Short:                 "Set an inventory of bucket",
+		Long: `Set an inventory of bucket, the old one with the same id is replaced
+
+FILE	JSON or YAML file of the inventory, like the output of getbucketinventory --json ID:
+
+id: daily
+enabled: true
+includedObjectVersions: Current
+filter:
+  prefix: logs/
+optionalFields:
+  - Size
+  - LastModifiedDate
+  - ETag
+  - StorageClass
+schedule:
+  frequency: Daily
+destination:
+  bucket: qcs::cos:ap-guangzhou::inventorybucket-1250000000
+  prefix: inventory/
+  format: CSV
+  encrypted: true`,
+		Args: cobra.ExactArgs(1),
+		RunE: putBucketInventory,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(putBucketInventoryCmd)
+}
+
+func putBucketInventory(_ *cobra.Command, args []string) error {
+	filePath, _ := homedir.Expand(args[0])
+	var config cli.InventoryConfiguration
+	if err := coshelper.UnmarshalFile(filePath, &config); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid inventory file: " + err.Error(),
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid inventory: " + err.Error(),
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if !client.PutBucketInventory(&config) {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "put bucket inventory fail",
+		}
+	}
+	return nil
+}
+
+// Open the inventory report given by --from-inventory, which must be of the bucket. Return nil if no manifest is given.
+func openInventory(client *cli.Client, manifestPath string, bucket string) (*cli.Inventory, error) {
+	if manifestPath == "" {
+		return nil, nil
+	}
+	if expanded, err := homedir.Expand(manifestPath); err == nil && coshelper.IsFile(expanded) {
+		manifestPath = expanded
+	}
+	inventory, err := client.OpenInventory(manifestPath)
+	if err != nil {
+		return nil, coshelper.Error{
+			Code:    1,
+			Message: "invalid --from-inventory option: " + err.Error(),
+		}
+	}
+	if inventory.SourceBucket != bucket {
+		return nil, coshelper.Error{
+			Code:    1,
+			Message: fmt.Sprintf("invalid --from-inventory option: the inventory is of %s instead of %s", inventory.SourceBucket, bucket),
+		}
+	}
+	return inventory, nil
+}
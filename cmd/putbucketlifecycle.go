@@ -0,0 +1,84 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+var (
+	putBucketLifecycleCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "putbucketlifecycle [-h] FILE",
+		Short:                 "Set the lifecycle of bucket",
+		Long: `Set the lifecycle of bucket, the old rules are replaced
+
+FILE	JSON or YAML file of rules, like the output of getbucketlifecycle --json:
+
+rules:
+  - id: archive-logs
+    status: Enabled
+    filter:
+      prefix: logs/
+      tags:
+        - key: type
+          value: access
+    transitions:
+      - days: 30
+        storageClass: ARCHIVE
+    expiration:
+      days: 365
+    abortIncompleteMultipartUpload:
+      daysAfterInitiation: 7`,
+		Args: cobra.ExactArgs(1),
+		RunE: putBucketLifecycle,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(putBucketLifecycleCmd)
+}
+
+func putBucketLifecycle(_ *cobra.Command, args []string) error {
+	filePath, _ := homedir.Expand(args[0])
+	var config cli.LifecycleConfiguration
+	if err := coshelper.UnmarshalFile(filePath, &config); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid lifecycle file: " + err.Error(),
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid lifecycle: " + err.Error(),
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if !client.PutBucketLifecycle(&config) {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "put bucket lifecycle fail",
+		}
+	}
+	return nil
+}
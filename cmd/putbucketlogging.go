@@ -0,0 +1,67 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	putBucketLoggingPrefix string
+	putBucketLoggingCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "putbucketlogging [-h] [--prefix PREFIX] TARGET_BUCKET",
+		Short:                 "Enable the access logging of bucket",
+		Long: `Enable the access logging of bucket, the logs are written to the target bucket
+
+TARGET_BUCKET	Bucket in the same region as examplebucket-1250000000`,
+		Args: cobra.ExactArgs(1),
+		RunE: putBucketLogging,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(putBucketLoggingCmd)
+
+	putBucketLoggingCmd.Flags().StringVar(&putBucketLoggingPrefix, "prefix", "",
+		"Specify the prefix of log objects; Example: logs/")
+}
+
+func putBucketLogging(_ *cobra.Command, args []string) error {
+	config := &cli.BucketLogging{
+		TargetBucket: args[0],
+		TargetPrefix: putBucketLoggingPrefix,
+	}
+	if err := config.Validate(); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid logging: " + err.Error(),
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if !client.PutBucketLogging(config) {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "put bucket logging fail",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+var (
+	putBucketPolicyCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "putbucketpolicy [-h] FILE",
+		Short:                 "Set the policy of bucket",
+		Long: `Set the policy of bucket, the old policy is replaced
+
+FILE	JSON or YAML file of policy, like the output of getbucketpolicy`,
+		Args: cobra.ExactArgs(1),
+		RunE: putBucketPolicy,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(putBucketPolicyCmd)
+}
+
+func putBucketPolicy(_ *cobra.Command, args []string) error {
+	policy, err := loadPolicy(args[0])
+	if err != nil {
+		return err
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if !client.PutBucketPolicy(policy) {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "put bucket policy fail",
+		}
+	}
+	return nil
+}
+
+// Read and validate the policy file.
+func loadPolicy(filePath string) (*cli.PolicyDocument, error) {
+	filePath, _ = homedir.Expand(filePath)
+	var policy cli.PolicyDocument
+	if err := coshelper.UnmarshalFile(filePath, &policy); err != nil {
+		return nil, coshelper.Error{
+			Code:    1,
+			Message: "invalid policy file: " + err.Error(),
+		}
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, coshelper.Error{
+			Code:    1,
+			Message: "invalid policy: " + err.Error(),
+		}
+	}
+	return &policy, nil
+}
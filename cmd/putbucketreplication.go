@@ -0,0 +1,78 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+var (
+	putBucketReplicationCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "putbucketreplication [-h] FILE",
+		Short:                 "Set the replication of bucket",
+		Long: `Set the replication of bucket, the old rules are replaced.
+Versioning must be enabled on both source and destination buckets.
+
+FILE	JSON or YAML file of rules, like the output of getbucketreplication --json:
+
+role: qcs::cam::uin/100000000001:uin/100000000001
+rules:
+  - id: dr
+    status: Enabled
+    prefix: data/
+    destination:
+      bucket: qcs::cos:ap-shanghai::examplebucket-1250000000
+      storageClass: STANDARD_IA`,
+		Args: cobra.ExactArgs(1),
+		RunE: putBucketReplication,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(putBucketReplicationCmd)
+}
+
+func putBucketReplication(_ *cobra.Command, args []string) error {
+	filePath, _ := homedir.Expand(args[0])
+	var config cli.ReplicationConfiguration
+	if err := coshelper.UnmarshalFile(filePath, &config); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid replication file: " + err.Error(),
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid replication: " + err.Error(),
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if !client.PutBucketReplication(&config) {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "put bucket replication fail",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+var (
+	putBucketWebsiteCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "putbucketwebsite [-h] FILE",
+		Short:                 "Set the static website configuration of bucket",
+		Long: `Set the static website configuration of bucket, the old one is replaced
+
+FILE	JSON or YAML file of configuration, like the output of getbucketwebsite --json:
+
+indexDocument: index.html
+errorDocument: 404.html
+routingRules:
+  - condition:
+      keyPrefixEquals: docs/
+    redirect:
+      replaceKeyPrefixWith: documents/
+  - condition:
+      httpErrorCodeReturnedEquals: 403
+    redirect:
+      replaceKeyWith: forbidden.html`,
+		Args: cobra.ExactArgs(1),
+		RunE: putBucketWebsite,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(putBucketWebsiteCmd)
+}
+
+func putBucketWebsite(_ *cobra.Command, args []string) error {
+	config, err := loadWebsiteConfiguration(args[0])
+	if err != nil {
+		return err
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if !client.PutBucketWebsite(config) {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "put bucket website fail",
+		}
+	}
+	return nil
+}
+
+// Read and validate the website configuration file.
+func loadWebsiteConfiguration(filePath string) (*cli.WebsiteConfiguration, error) {
+	filePath, _ = homedir.Expand(filePath)
+	var config cli.WebsiteConfiguration
+	if err := coshelper.UnmarshalFile(filePath, &config); err != nil {
+		return nil, coshelper.Error{
+			Code:    1,
+			Message: "invalid website file: " + err.Error(),
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return nil, coshelper.Error{
+			Code:    1,
+			Message: "invalid website configuration: " + err.Error(),
+		}
+	}
+	return &config, nil
+}
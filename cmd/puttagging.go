@@ -0,0 +1,147 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type TaggingConfig struct {
+	bucket, recursive           bool
+	include, ignore, filterFrom string
+	predicate                   PredicateConfig
+}
+
+var (
+	putTaggingConfig TaggingConfig
+	putTaggingCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use: "puttagging [-h] [--bucket] [-r] [--include INCLUDE] [--ignore IGNORE] [--filter-from FILE]" +
+			" [--min-size SIZE] [--max-size SIZE] [--newer-than TIME] [--older-than TIME] [--class CLASS]" +
+			" [--regex REGEX] [COS_PATH] TAGS",
+		Short: "Set tags of bucket or objects",
+		Long: `Set tags of bucket or objects, the old tags are replaced
+
+COS_PATH	COS path as a/b.txt, or the prefix of objects with -r; omitted with --bucket
+TAGS	Tags separated by commas as project=a,env=prod`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: putTagging,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(putTaggingCmd)
+
+	putTaggingCmd.Flags().SortFlags = false
+	addTaggingFlags(putTaggingCmd.Flags(), &putTaggingConfig, "Set tags of the bucket", "Set tags of objects with the prefix")
+}
+
+// Register flags shared by puttagging and deletetagging.
+func addTaggingFlags(flags *pflag.FlagSet, cfg *TaggingConfig, bucketUsage string, recursiveUsage string) {
+	flags.BoolVar(&cfg.bucket, "bucket", false, bucketUsage)
+	flags.BoolVarP(&cfg.recursive, "recursive", "r", false, recursiveUsage)
+	flags.StringVar(&cfg.include, "include", "*",
+		"Specify filter rules, separated by commas; Example: *.txt,*.docx,*.ppt")
+	flags.StringVar(&cfg.ignore, "ignore", "",
+		"Specify ignored rules, separated by commas; Example: *.txt,*.docx,*.ppt")
+	flags.StringVar(&cfg.filterFrom, "filter-from", "",
+		"Read gitignore-style filter rules from file")
+	addPredicateFlags(flags, &cfg.predicate, true)
+}
+
+func putTagging(_ *cobra.Command, args []string) error {
+	if putTaggingConfig.bucket != (len(args) == 1) {
+		return coshelper.Error{
+			Code:    1,
+			Message: "COS_PATH must be given without --bucket, and omitted with --bucket",
+		}
+	}
+	tags, err := coshelper.ParseTags(args[len(args)-1])
+	if err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid TAGS: " + err.Error(),
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	if putTaggingConfig.bucket {
+		if !client.PutBucketTagging(tags) {
+			return coshelper.Error{
+				Code:    -1,
+				Message: "put bucket tagging fail",
+			}
+		}
+		return nil
+	}
+	if err := cli.CheckObjectTags(tags); err != nil {
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid TAGS: " + err.Error(),
+		}
+	}
+	cosPath := strings.TrimLeft(args[0], "/")
+	var ret int
+	if putTaggingConfig.recursive {
+		options, err := putTaggingConfig.options()
+		if err != nil {
+			return err
+		}
+		ret = client.PutObjectTaggingFolder(cosPath, tags, options)
+	} else {
+		ret = client.PutObjectTagging(cosPath, tags)
+	}
+	if ret != 0 {
+		return coshelper.Error{
+			Code:    ret,
+			Message: "put tagging fail",
+		}
+	}
+	return nil
+}
+
+func (cfg *TaggingConfig) options() (*cli.TaggingOption, error) {
+	filter, err := newFilter(cfg.include, cfg.ignore, cfg.filterFrom)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.predicate.apply(filter); err != nil {
+		return nil, err
+	}
+	return &cli.TaggingOption{Filter: filter}, nil
+}
+
+// Parse the tags given by flag for objects.
+func parseObjectTags(s string, flag string) ([]coshelper.Tag, error) {
+	tags, err := coshelper.ParseTags(s)
+	if err == nil {
+		err = cli.CheckObjectTags(tags)
+	}
+	if err != nil {
+		return nil, coshelper.Error{
+			Code:    1,
+			Message: "invalid " + flag + " option: " + err.Error(),
+		}
+	}
+	return tags, nil
+}
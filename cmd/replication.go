@@ -0,0 +1,86 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+type ReplicationVerifyConfig struct {
+	dest        string
+	allVersions bool
+}
+
+var (
+	replicationVerifyConfig ReplicationVerifyConfig
+	replicationCmd          = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "replication [-h] {verify}",
+		Short:                 "Check cross-region replication",
+	}
+	replicationVerifyCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "verify [-h] [--dest DEST_BUCKET] [--all-versions] SRC_PREFIX",
+		Short:                 "Compare objects with their replicas",
+		Long: `Compare objects with their replicas in the destination buckets of replication rules,
+by keys, sizes, ETags and version IDs, and report missing objects and the replication lag.
+Exit with code 1 if some objects are not replicated.
+
+SRC_PREFIX	COS path prefix as a/b/ in the source bucket`,
+		Args: cobra.ExactArgs(1),
+		RunE: replicationVerify,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(replicationCmd)
+	replicationCmd.AddCommand(replicationVerifyCmd)
+
+	replicationVerifyCmd.Flags().SortFlags = false
+	replicationVerifyCmd.Flags().StringVar(&replicationVerifyConfig.dest, "dest", "",
+		"Specify the destination bucket as 'bucket-appid.cos.ap-shanghai.myqcloud.com' instead of the replication rules")
+	replicationVerifyCmd.Flags().BoolVar(&replicationVerifyConfig.allVersions, "all-versions", false,
+		"Compare every version instead of the latest ones")
+}
+
+func replicationVerify(_ *cobra.Command, args []string) error {
+	cosPath := strings.TrimLeft(args[0], "/")
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	switch client.ReplicationVerify(cosPath, &cli.ReplicationVerifyOption{
+		DestPath:    replicationVerifyConfig.dest,
+		AllVersions: replicationVerifyConfig.allVersions,
+	}) {
+	case 0:
+		return nil
+	case 1:
+		return coshelper.Error{
+			Code:    1,
+			Message: "some objects are not replicated",
+		}
+	default:
+		return coshelper.Error{
+			Code:    -1,
+			Message: "replication verify fail",
+		}
+	}
+}
@@ -26,16 +26,18 @@ import (
 )
 
 type RestoreConfig struct {
-	recursive bool
-	day       int
-	tier      string
+	recursive     bool
+	day           int
+	tier          string
+	fromInventory string
+	predicate     PredicateConfig
 }
 
 var (
 	restoreConfig RestoreConfig
 	restoreCmd    = &cobra.Command{
 		DisableFlagsInUseLine: true,
-		Use:                   "restore [-h] [-r] [-d DAY] [-t {Expedited,Standard,Bulk}] COS_PATH",
+		Use:                   "restore [-h] [-r] [-d DAY] [-t {Expedited,Standard,Bulk}] [--min-size SIZE] [--max-size SIZE] [--newer-than TIME] [--older-than TIME] [--class CLASS] [--regex REGEX] [--from-inventory MANIFEST] COS_PATH",
 		Short:                 "Restore",
 		Long: `Restore
 
@@ -53,30 +55,42 @@ func init() {
 		"Restore files recursively")
 	restoreCmd.Flags().IntVarP(&restoreConfig.day, "day", "d", 7,
 		"Specify lifetime of the restored (active) copy")
-	restoreCmd.Flags().StringVarP(&restoreConfig.tier, "tier", "t", "STANDARD",
+	restoreCmd.Flags().StringVarP(&restoreConfig.tier, "tier", "t", defaultRestoreTier,
 		"Specify the data access tier")
+	addPredicateFlags(restoreCmd.Flags(), &restoreConfig.predicate, true)
+	restoreCmd.Flags().StringVar(&restoreConfig.fromInventory, "from-inventory", "",
+		"Read objects from the inventory report with the manifest instead of listing them; Example: manifest.json")
 }
 
 func restore(_ *cobra.Command, args []string) error {
 	cosPath := strings.TrimLeft(args[0], "/")
 	conf := cli.LoadConf(cli.ConfigPath)
 	client := cli.NewClient(conf)
-	options := &cli.RestoreOption{
-		Day: restoreConfig.day,
+	filter := coshelper.NewFilter(nil, nil)
+	if err := restoreConfig.predicate.apply(filter); err != nil {
+		return err
 	}
-	switch strings.ToLower(restoreConfig.tier) {
-	case "expedited":
-		options.Tier = cli.Expedited
-	case "standard":
-		options.Tier = cli.Standard
-	case "bulk":
-		options.Tier = cli.Bulk
-	default:
+	if restoreConfig.fromInventory != "" && !restoreConfig.recursive {
 		return coshelper.Error{
 			Code:    1,
-			Message: "invalid -t option: must be one of them - Expedited, Standard, Bulk",
+			Message: "--from-inventory only works with -r/--recursive",
 		}
 	}
+	inventory, err := openInventory(client, restoreConfig.fromInventory, conf.Bucket)
+	if err != nil {
+		return err
+	}
+	if inventory != nil {
+		defer inventory.Close()
+	}
+	options := &cli.RestoreOption{
+		Day:       restoreConfig.day,
+		Filter:    filter,
+		Inventory: inventory,
+	}
+	if options.Tier, err = parseRestoreTier(restoreConfig.tier, "-t"); err != nil {
+		return err
+	}
 	if restoreConfig.recursive {
 		ret := client.RestoreFolder(cosPath, options)
 		if ret == 0 {
@@ -98,3 +112,22 @@ func restore(_ *cobra.Command, args []string) error {
 		return nil
 	}
 }
+
+// The default of -t of restore and find --restore, spelled as in their usage.
+const defaultRestoreTier = "Standard"
+
+func parseRestoreTier(tier string, flag string) (int, error) {
+	switch strings.ToLower(tier) {
+	case "expedited":
+		return cli.Expedited, nil
+	case "standard":
+		return cli.Standard, nil
+	case "bulk":
+		return cli.Bulk, nil
+	default:
+		return 0, coshelper.Error{
+			Code:    1,
+			Message: "invalid " + flag + " option: must be one of them - Expedited, Standard, Bulk",
+		}
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+type SetMetaConfig struct {
+	recursive, dryRun           bool
+	include, ignore, filterFrom string
+	headers, meta, removeMeta   []string
+	predicate                   PredicateConfig
+}
+
+var (
+	setMetaConfig SetMetaConfig
+	setMetaCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use: "setmeta [-h] [-r] [--header HEADER=VALUE]... [--meta KEY=VALUE]... [--remove-meta KEY]..." +
+			" [--include INCLUDE] [--ignore IGNORE] [--filter-from FILE] [--min-size SIZE] [--max-size SIZE]" +
+			" [--newer-than TIME] [--older-than TIME] [--class CLASS] [--regex REGEX] [--dry-run] COS_PATH",
+		Short: "Change the metadata of objects",
+		Long: `Change the metadata of objects in place, by copying them to themselves.
+
+Metadata not mentioned, the storage class, tags, ACL and SSE-COS or SSE-KMS encryption are kept.
+Objects in ARCHIVE or DEEP_ARCHIVE must be restored first.
+
+COS_PATH	COS Path as a/b.txt, or a prefix as a/b/ with -r`,
+		Args: cobra.ExactArgs(1),
+		RunE: setMeta,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(setMetaCmd)
+
+	setMetaCmd.Flags().SortFlags = false
+	setMetaCmd.Flags().BoolVarP(&setMetaConfig.recursive, "recursive", "r", false,
+		"Change the metadata of objects with the prefix")
+	setMetaCmd.Flags().StringArrayVar(&setMetaConfig.headers, "header", nil,
+		"Set the header, or remove it if the value is empty: "+strings.Join(cli.MetaHeaders, ", ")+"; Example: Content-Type=text/html")
+	setMetaCmd.Flags().StringArrayVar(&setMetaConfig.meta, "meta", nil,
+		"Set the user metadata x-cos-meta-KEY; Example: owner=alice")
+	setMetaCmd.Flags().StringArrayVar(&setMetaConfig.removeMeta, "remove-meta", nil,
+		"Remove the user metadata x-cos-meta-KEY; Example: owner")
+	setMetaCmd.Flags().StringVar(&setMetaConfig.include, "include", "*",
+		"Specify filter rules, separated by commas; Example: *.txt,*.docx,*.ppt")
+	setMetaCmd.Flags().StringVar(&setMetaConfig.ignore, "ignore", "",
+		"Specify ignored rules, separated by commas; Example: *.txt,*.docx,*.ppt")
+	setMetaCmd.Flags().StringVar(&setMetaConfig.filterFrom, "filter-from", "",
+		"Read gitignore-style filter rules from file")
+	addPredicateFlags(setMetaCmd.Flags(), &setMetaConfig.predicate, true)
+	setMetaCmd.Flags().BoolVar(&setMetaConfig.dryRun, "dry-run", false,
+		"Only show what would be done")
+}
+
+func setMeta(_ *cobra.Command, args []string) error {
+	cosPath := strings.TrimLeft(args[0], "/")
+	options := &cli.SetMetaOption{
+		Headers:    make(map[string]string),
+		Meta:       make(map[string]string),
+		RemoveMeta: setMetaConfig.removeMeta,
+		DryRun:     setMetaConfig.dryRun,
+	}
+	for _, header := range setMetaConfig.headers {
+		key, value, err := splitKeyValue(header, "--header")
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, metaHeader := range cli.MetaHeaders {
+			if strings.EqualFold(key, metaHeader) {
+				options.Headers[metaHeader] = value
+				found = true
+			}
+		}
+		if !found {
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid --header option: header must be one of them - " + strings.Join(cli.MetaHeaders, ", "),
+			}
+		}
+	}
+	for _, meta := range setMetaConfig.meta {
+		key, value, err := splitKeyValue(meta, "--meta")
+		if err != nil {
+			return err
+		}
+		options.Meta[key] = value
+	}
+	if len(options.Headers) == 0 && len(options.Meta) == 0 && len(options.RemoveMeta) == 0 {
+		return coshelper.Error{
+			Code:    1,
+			Message: "nothing to change, use --header, --meta or --remove-meta",
+		}
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	var ret int
+	if setMetaConfig.recursive {
+		filter, err := newFilter(setMetaConfig.include, setMetaConfig.ignore, setMetaConfig.filterFrom)
+		if err != nil {
+			return err
+		}
+		if err := setMetaConfig.predicate.apply(filter); err != nil {
+			return err
+		}
+		options.Filter = filter
+		ret = client.SetMetaFolder(cosPath, options)
+	} else {
+		ret = client.SetMetaFile(cosPath, options)
+	}
+	if ret != 0 && ret != -2 {
+		return coshelper.Error{
+			Code:    ret,
+			Message: "set metadata failed",
+		}
+	}
+	return nil
+}
+
+// Split KEY=VALUE given by flag, the key must not be empty.
+func splitKeyValue(str string, flag string) (string, string, error) {
+	i := strings.Index(str, "=")
+	if i <= 0 {
+		return "", "", coshelper.Error{
+			Code:    1,
+			Message: "invalid " + flag + " option: should be like KEY=VALUE",
+		}
+	}
+	return strings.TrimSpace(str[:i]), strings.TrimSpace(str[i+1:]), nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/pflag"
+)
+
+// SSEConfig is the server-side encryption of objects.
+type SSEConfig struct {
+	sseType, kmsKeyID, customerKey string
+}
+
+// Add the encryption flags to flags. write tells whether the command creates objects,
+// otherwise only the key of SSE-C is needed to read them.
+func addSSEFlags(flags *pflag.FlagSet, config *SSEConfig, write bool) {
+	if write {
+		flags.StringVar(&config.sseType, "sse", "",
+			"Encrypt objects on the server side: cos (SSE-COS) or kms (SSE-KMS)")
+		flags.StringVar(&config.kmsKeyID, "kms-key-id", "",
+			"Specify the KMS key of SSE-KMS instead of the default key")
+	}
+	flags.StringVar(&config.customerKey, "sse-c-key", "",
+		"Read the 32 bytes key of SSE-C from file, in raw bytes, base64 or hex")
+}
+
+// Build the encryption, nil if no encryption flags are specified.
+func (config *SSEConfig) build() (*coshelper.SSE, error) {
+	sse, err := coshelper.NewSSE(config.sseType, config.kmsKeyID, config.customerKey)
+	if err != nil {
+		return nil, coshelper.Error{
+			Code:    1,
+			Message: "invalid encryption options: " + err.Error(),
+		}
+	}
+	return sse, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+type TransitionConfig struct {
+	dryRun                          bool
+	to, include, ignore, filterFrom string
+	fromInventory                   string
+	predicate                       PredicateConfig
+}
+
+var (
+	transitionConfig TransitionConfig
+	transitionCmd    = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use: "transition [-h] --to CLASS [--include INCLUDE] [--ignore IGNORE] [--filter-from FILE]" +
+			" [--min-size SIZE] [--max-size SIZE] [--newer-than TIME] [--older-than TIME] [--class CLASS]" +
+			" [--regex REGEX] [--dry-run] [--from-inventory MANIFEST] PREFIX",
+		Short: "Change the storage class of objects",
+		Long: `Change the storage class of objects with the prefix, by copying them to themselves.
+Metadata, tags, ACL and SSE-COS or SSE-KMS encryption are kept.
+
+Objects in ARCHIVE or DEEP_ARCHIVE must be restored before transition.
+
+PREFIX	COS path prefix as a/b/`,
+		Args: cobra.ExactArgs(1),
+		RunE: transition,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(transitionCmd)
+
+	transitionCmd.Flags().SortFlags = false
+	transitionCmd.Flags().StringVar(&transitionConfig.to, "to", "",
+		"Specify the target storage class: "+strings.Join(cli.StorageClasses, ", "))
+	transitionCmd.Flags().StringVar(&transitionConfig.include, "include", "*",
+		"Specify filter rules, separated by commas; Example: *.txt,*.docx,*.ppt")
+	transitionCmd.Flags().StringVar(&transitionConfig.ignore, "ignore", "",
+		"Specify ignored rules, separated by commas; Example: *.txt,*.docx,*.ppt")
+	transitionCmd.Flags().StringVar(&transitionConfig.filterFrom, "filter-from", "",
+		"Read gitignore-style filter rules from file")
+	addPredicateFlags(transitionCmd.Flags(), &transitionConfig.predicate, true)
+	transitionCmd.Flags().BoolVar(&transitionConfig.dryRun, "dry-run", false,
+		"Only show what would be done")
+	transitionCmd.Flags().StringVar(&transitionConfig.fromInventory, "from-inventory", "",
+		"Read objects from the inventory report with the manifest instead of listing them; Example: manifest.json")
+	_ = transitionCmd.MarkFlagRequired("to")
+}
+
+func transition(_ *cobra.Command, args []string) error {
+	cosPath := strings.TrimLeft(args[0], "/")
+	storageClass, err := checkStorageClass(transitionConfig.to, "--to")
+	if err != nil {
+		return err
+	}
+	filter, err := newFilter(transitionConfig.include, transitionConfig.ignore, transitionConfig.filterFrom)
+	if err != nil {
+		return err
+	}
+	if err := transitionConfig.predicate.apply(filter); err != nil {
+		return err
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	inventory, err := openInventory(client, transitionConfig.fromInventory, conf.Bucket)
+	if err != nil {
+		return err
+	}
+	if inventory != nil {
+		defer inventory.Close()
+	}
+	ret := client.TransitionFolder(cosPath, &cli.TransitionOption{
+		StorageClass: storageClass,
+		Filter:       filter,
+		DryRun:       transitionConfig.dryRun,
+		Inventory:    inventory,
+	})
+	if ret != 0 {
+		return coshelper.Error{
+			Code:    ret,
+			Message: "transition failed",
+		}
+	}
+	return nil
+}
+
+// Check the storage class given by flag, return it in upper case.
+func checkStorageClass(storageClass string, flag string) (string, error) {
+	storageClass = strings.ToUpper(storageClass)
+	for _, class := range cli.StorageClasses {
+		if class == storageClass {
+			return storageClass, nil
+		}
+	}
+	return "", coshelper.Error{
+		Code:    1,
+		Message: "invalid " + flag + " option: must be one of them - " + strings.Join(cli.StorageClasses, ", "),
+	}
+}
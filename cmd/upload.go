@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/huanght1997/cosutil/cli"
 	"github.com/huanght1997/cosutil/coshelper"
@@ -30,8 +31,14 @@ import (
 )
 
 type UploadConfig struct {
-	recursive, sync, force, yes, skipMd5, delRemote bool
-	headers, include, ignore                        string
+	recursive, sync, force, yes, skipMd5, delRemote, watch bool
+	followSymlinks, skipSymlinks, storeSymlinks, index     bool
+	headers, include, ignore, filterFrom, archive          string
+	compress, compressInclude, mimeTypes, headerRules      string
+	storageClass, tags                                     string
+	debounce                                               time.Duration
+	predicate                                              PredicateConfig
+	sse                                                    SSEConfig
 }
 
 // uploadCmd represents the upload command
@@ -39,7 +46,7 @@ var (
 	uploadLocalPath, uploadCosPath string
 	uploadCmd                      = &cobra.Command{
 		DisableFlagsInUseLine: true,
-		Use:                   "upload [-h] [-r] [-H HEADERS] [-s] [-f] [--include INCLUDE] [--ignore IGNORE] [--skipmd5] [--delete] LOCAL_PATH COS_PATH",
+		Use:                   "upload [-h] [-r] [-H HEADERS] [--mime-types FILE] [--header-rules FILE] [--storage-class CLASS] [--tags TAGS] [--sse {cos,kms}] [--kms-key-id ID] [--sse-c-key FILE] [-s] [-f] [--include INCLUDE] [--ignore IGNORE] [--filter-from FILE] [--min-size SIZE] [--max-size SIZE] [--newer-than TIME] [--older-than TIME] [--regex REGEX] [--skipmd5] [--delete] [--compress {gzip,zstd}] [--compress-include PATTERNS] [--follow-symlinks | --skip-symlinks | --store-symlinks] [--archive {tar,tar.gz,zstd}] [--index] [--watch] [--debounce DEBOUNCE] LOCAL_PATH COS_PATH",
 		Short:                 "Upload file or directory to COS",
 		Long: `Upload file or directory to COS.
 
@@ -59,6 +66,15 @@ func init() {
 		"Upload recursively when upload directory")
 	uploadCmd.Flags().StringVarP(&uploadConfig.headers, "headers", "H", "{}",
 		"Specify HTTP headers")
+	uploadCmd.Flags().StringVar(&uploadConfig.mimeTypes, "mime-types", "",
+		"Read mime.types file to override the Content-Type of file extensions")
+	uploadCmd.Flags().StringVar(&uploadConfig.headerRules, "header-rules", "",
+		"Read rules to set headers by file name, each line is a pattern and a header; Example: *.html Cache-Control: no-cache")
+	uploadCmd.Flags().StringVar(&uploadConfig.storageClass, "storage-class", "",
+		"Specify the storage class of objects: "+strings.Join(cli.StorageClasses, ", "))
+	uploadCmd.Flags().StringVar(&uploadConfig.tags, "tags", "",
+		"Specify tags of objects, separated by commas; Example: project=a,env=prod")
+	addSSEFlags(uploadCmd.Flags(), &uploadConfig.sse, true)
 	uploadCmd.Flags().BoolVarP(&uploadConfig.sync, "sync", "s", false,
 		"Upload and skip the same file")
 	uploadCmd.Flags().BoolVarP(&uploadConfig.force, "force", "f", false,
@@ -69,10 +85,31 @@ func init() {
 		"Specify filter rules, separated by commas; Example: *.txt,*.docx,*.ppt")
 	uploadCmd.Flags().StringVar(&uploadConfig.ignore, "ignore", "",
 		"Specify ignored rules, separated by commas; Example: *.txt,*.docx,*.ppt")
+	uploadCmd.Flags().StringVar(&uploadConfig.filterFrom, "filter-from", "",
+		"Read gitignore-style filter rules from file")
+	addPredicateFlags(uploadCmd.Flags(), &uploadConfig.predicate, false)
 	uploadCmd.Flags().BoolVar(&uploadConfig.skipMd5, "skipmd5", false,
 		"Upload without x-cos-meta-md5 / sync without check md5, only check filename and filesize")
 	uploadCmd.Flags().BoolVar(&uploadConfig.delRemote, "delete", false,
 		"Delete objects which exists in COS but not exist in local")
+	uploadCmd.Flags().StringVar(&uploadConfig.compress, "compress", "",
+		"Compress files with gzip or zstd when uploading, they are decompressed automatically by download")
+	uploadCmd.Flags().StringVar(&uploadConfig.compressInclude, "compress-include", "",
+		"Only compress the files matching the patterns, separated by commas; Example: *.json,*.log")
+	uploadCmd.Flags().BoolVar(&uploadConfig.followSymlinks, "follow-symlinks", false,
+		"Upload the content of symbolic links to directories, links making loops are skipped")
+	uploadCmd.Flags().BoolVar(&uploadConfig.skipSymlinks, "skip-symlinks", false,
+		"Skip all symbolic links")
+	uploadCmd.Flags().BoolVar(&uploadConfig.storeSymlinks, "store-symlinks", false,
+		"Upload symbolic links as small objects, which are recreated as links by download")
+	uploadCmd.Flags().StringVar(&uploadConfig.archive, "archive", "",
+		"Pack the directory into a single object of the format: tar, tar.gz or zstd")
+	uploadCmd.Flags().BoolVar(&uploadConfig.index, "index", false,
+		"Upload an index with the tar archive, so that single files can be downloaded by --member")
+	uploadCmd.Flags().BoolVar(&uploadConfig.watch, "watch", false,
+		"Keep watching the directory after upload, and upload the changed files until interrupted")
+	uploadCmd.Flags().DurationVar(&uploadConfig.debounce, "debounce", 2*time.Second,
+		"Specify how long to wait for more changes before uploading in watch mode")
 }
 
 func upload(_ *cobra.Command, args []string) error {
@@ -106,16 +143,131 @@ func upload(_ *cobra.Command, args []string) error {
 
 	uploadLocalPath, uploadCosPath = concatPath(uploadLocalPath, uploadCosPath)
 	uploadCosPath = strings.TrimPrefix(uploadCosPath, "/")
+	filter, err := newFilter(uploadConfig.include, uploadConfig.ignore, uploadConfig.filterFrom)
+	if err != nil {
+		return err
+	}
+	if err := uploadConfig.predicate.apply(filter); err != nil {
+		return err
+	}
 	uploadOption := &cli.UploadOption{
 		SkipMd5: uploadConfig.skipMd5,
 		Sync:    uploadConfig.sync,
-		Include: strings.Split(uploadConfig.include, ","),
-		Ignore:  strings.Split(uploadConfig.ignore, ","),
+		Filter:  filter,
 		Force:   uploadConfig.force,
 		Yes:     uploadConfig.yes,
 		Delete:  uploadConfig.delRemote,
+		Symlink: cli.SymlinkFile,
+	}
+	if uploadConfig.storageClass != "" {
+		if uploadOption.StorageClass, err = checkStorageClass(uploadConfig.storageClass, "--storage-class"); err != nil {
+			return err
+		}
+	}
+	if uploadOption.Tags, err = parseObjectTags(uploadConfig.tags, "--tags"); err != nil {
+		return err
+	}
+	if uploadOption.SSE, err = uploadConfig.sse.build(); err != nil {
+		return err
+	}
+	switch uploadConfig.compress {
+	case "":
+	case cli.CompressGzip, cli.CompressZstd:
+		uploadOption.Compress = uploadConfig.compress
+		for _, pattern := range strings.Split(uploadConfig.compressInclude, ",") {
+			if pattern != "" {
+				uploadOption.CompressInclude = append(uploadOption.CompressInclude, pattern)
+			}
+		}
+	default:
+		return coshelper.Error{
+			Code:    1,
+			Message: "invalid --compress option: must be one of them - gzip, zstd",
+		}
+	}
+	symlinkFlags := 0
+	if uploadConfig.followSymlinks {
+		uploadOption.Symlink = cli.SymlinkFollow
+		symlinkFlags++
+	}
+	if uploadConfig.skipSymlinks {
+		uploadOption.Symlink = cli.SymlinkSkip
+		symlinkFlags++
+	}
+	if uploadConfig.storeSymlinks {
+		uploadOption.Symlink = cli.SymlinkStore
+		symlinkFlags++
+	}
+	if symlinkFlags > 1 {
+		return coshelper.Error{
+			Code:    1,
+			Message: "--follow-symlinks, --skip-symlinks and --store-symlinks can not be used together",
+		}
+	}
+	if uploadConfig.mimeTypes != "" {
+		mimeTypes, _ := homedir.Expand(uploadConfig.mimeTypes)
+		if err := coshelper.LoadMimeTypes(mimeTypes); err != nil {
+			log.Warnf("Cannot read mime types file '%s': %s", mimeTypes, err.Error())
+			return coshelper.Error{
+				Code:    1,
+				Message: "cannot read mime types file",
+			}
+		}
+	}
+	if uploadConfig.headerRules != "" {
+		headerRules, _ := homedir.Expand(uploadConfig.headerRules)
+		uploadOption.HeaderRules, err = coshelper.LoadHeaderRules(headerRules)
+		if err != nil {
+			log.Warnf("Cannot read header rules file '%s': %s", headerRules, err.Error())
+			return coshelper.Error{
+				Code:    1,
+				Message: "cannot read header rules file",
+			}
+		}
 	}
 	headers := coshelper.ConvertStringToHeader(uploadConfig.headers)
+	if uploadConfig.archive != "" {
+		switch uploadConfig.archive {
+		case cli.ArchiveTar, cli.ArchiveTarGz, cli.ArchiveZstd:
+		default:
+			return coshelper.Error{
+				Code:    1,
+				Message: "invalid --archive option: must be one of them - tar, tar.gz, zstd",
+			}
+		}
+		if !coshelper.IsDir(uploadLocalPath) {
+			log.Warnf(`"%s" is not a directory, only directory can be archived`, uploadLocalPath)
+			return coshelper.Error{
+				Code:    1,
+				Message: "archive a file",
+			}
+		}
+		ret := client.UploadArchive(uploadLocalPath, uploadCosPath, uploadConfig.archive, uploadConfig.index, headers, uploadOption)
+		if ret != 0 {
+			return coshelper.Error{
+				Code:    ret,
+				Message: fmt.Sprintf("upload failed, code: %d", ret),
+			}
+		}
+		return nil
+	}
+	if uploadConfig.watch {
+		if !coshelper.IsDir(uploadLocalPath) {
+			log.Warnf(`"%s" is not a directory, only directory can be watched`, uploadLocalPath)
+			return coshelper.Error{
+				Code:    1,
+				Message: "watch a file",
+			}
+		}
+		ret := client.WatchFolder(uploadLocalPath, uploadCosPath, headers, uploadOption, uploadConfig.debounce)
+		if ret != 0 {
+			return coshelper.Error{
+				Code:    ret,
+				Message: fmt.Sprintf("upload failed, code: %d", ret),
+			}
+		}
+		return nil
+	}
 	if uploadConfig.recursive {
 		var ret int
 		if coshelper.IsFile(uploadLocalPath) {
@@ -175,3 +327,20 @@ func concatPath(sourcePath string, targetPath string) (source, target string) {
 	}
 	return
 }
+
+// Create a filter from comma separated include and ignore patterns, and rules in filterFrom file if specified.
+// Patterns and rules are matched against the path relative to the source path.
+func newFilter(include string, ignore string, filterFrom string) (*coshelper.Filter, error) {
+	filter := coshelper.NewFilter(strings.Split(include, ","), strings.Split(ignore, ","))
+	if filterFrom != "" {
+		filterFrom, _ = homedir.Expand(filterFrom)
+		if err := filter.LoadFile(filterFrom, ""); err != nil {
+			log.Warnf("Cannot read filter file '%s': %s", filterFrom, err.Error())
+			return nil, coshelper.Error{
+				Code:    1,
+				Message: "cannot read filter file",
+			}
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/huanght1997/cosutil/cli"
+	"github.com/huanght1997/cosutil/coshelper"
+
+	"github.com/spf13/cobra"
+)
+
+type WebsitePreviewConfig struct {
+	config, host string
+	port         int
+}
+
+var (
+	websitePreviewConfig WebsitePreviewConfig
+	websiteCmd           = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "website [-h] {preview}",
+		Short:                 "Work with static websites",
+	}
+	websitePreviewCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Use:                   "preview [-h] [--config FILE] [--host HOST] [--port PORT] [PREFIX]",
+		Short:                 "Serve a bucket prefix as a static website locally",
+		Long: `Serve a bucket prefix as a static website locally, applying the index document,
+error document and routing rules, so that a deploy can be checked before it is published.
+
+[PREFIX]	COS path prefix as site/, the root of the website`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: websitePreview,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(websiteCmd)
+	websiteCmd.AddCommand(websitePreviewCmd)
+
+	websitePreviewCmd.Flags().SortFlags = false
+	websitePreviewCmd.Flags().StringVar(&websitePreviewConfig.config, "config", "",
+		"Specify the JSON or YAML website configuration, the configuration of bucket is used if not specified")
+	websitePreviewCmd.Flags().StringVar(&websitePreviewConfig.host, "host", "127.0.0.1",
+		"Specify the address to listen on")
+	websitePreviewCmd.Flags().IntVar(&websitePreviewConfig.port, "port", 8080,
+		"Specify the port to listen on")
+}
+
+func websitePreview(_ *cobra.Command, args []string) error {
+	var config *cli.WebsiteConfiguration
+	if websitePreviewConfig.config != "" {
+		var err error
+		if config, err = loadWebsiteConfiguration(websitePreviewConfig.config); err != nil {
+			return err
+		}
+	}
+	cosPath := ""
+	if len(args) > 0 {
+		cosPath = args[0]
+	}
+	conf := cli.LoadConf(cli.ConfigPath)
+	client := cli.NewClient(conf)
+	addr := net.JoinHostPort(websitePreviewConfig.host, strconv.Itoa(websitePreviewConfig.port))
+	if client.WebsitePreview(cosPath, addr, config) != 0 {
+		return coshelper.Error{
+			Code:    -1,
+			Message: "website preview fail",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,396 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coshelper
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danwakefield/fnmatch"
+	log "github.com/sirupsen/logrus"
+)
+
+// IgnoreFileName is the name of the per directory ignore file found when uploading folders.
+const IgnoreFileName = ".cosignore"
+
+// FileAttr is the attributes of a local file or an object used by filter predicates.
+// Size is -1 and ModTime is zero if unknown, StorageClass is empty for local files.
+type FileAttr struct {
+	Size         int64
+	ModTime      time.Time
+	StorageClass string
+}
+
+type filterRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+	// base is the directory the rule is defined in, relative to the root, "" for the root itself.
+	base string
+	// file is the path of the file the rule is loaded from, "" if added directly.
+	file string
+}
+
+// Filter decides whether a path should be transferred. All paths given to
+// it are relative to the root of the transfer and separated by "/". The root
+// is the folder for folders, the folder containing the prefix for object prefixes
+// without a trailing "/", and for a single file it is the working directory, or
+// the bucket for objects, so the path is the one given on the command line.
+//
+// A path is transferred only if it matches one of the include patterns, does
+// not match any ignore patterns (both are fnmatch patterns as before), matches
+// the regular expression and the name patterns if set, and is not excluded by the
+// gitignore-style rules.
+// Files can be further selected by size, modification time and storage class.
+type Filter struct {
+	include        []string
+	ignore         []string
+	rules          []filterRule
+	regex          *regexp.Regexp
+	names          []string
+	minSize        int64
+	maxSize        int64
+	newerThan      time.Time
+	olderThan      time.Time
+	storageClasses []string
+}
+
+// NewFilter creates a filter with include and ignore fnmatch patterns.
+// Empty patterns are dropped, and if no include pattern left, everything is included.
+func NewFilter(include []string, ignore []string) *Filter {
+	f := &Filter{
+		minSize: -1,
+		maxSize: -1,
+	}
+	for _, rule := range include {
+		if rule != "" {
+			f.include = append(f.include, rule)
+		}
+	}
+	for _, rule := range ignore {
+		if rule != "" {
+			f.ignore = append(f.ignore, rule)
+		}
+	}
+	return f
+}
+
+// AddRule adds a gitignore-style rule defined in directory base.
+// Blank lines and comments are ignored.
+func (f *Filter) AddRule(line string, base string) {
+	line = strings.TrimRight(line, "\r")
+	// Trailing spaces are ignored unless they are escaped.
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+	rule := filterRule{base: strings.Trim(base, "/")}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return
+	}
+	// A pattern with a slash at the beginning or in the middle is relative to base,
+	// otherwise it matches at any level below base.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	expr := globToRegexp(line)
+	if !anchored && !strings.HasPrefix(expr, "(?:.*/)?") {
+		expr = "(?:.*/)?" + expr
+	}
+	pattern, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		log.Warnf("Invalid filter rule '%s', ignore it", line)
+		return
+	}
+	rule.pattern = pattern
+	f.rules = append(f.rules, rule)
+}
+
+// LoadFile reads gitignore-style rules from file, treating them as defined in directory base.
+func (f *Filter) LoadFile(path string, base string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		n := len(f.rules)
+		f.AddRule(scanner.Text(), base)
+		if len(f.rules) > n {
+			f.rules[n].file = filepath.Clean(path)
+		}
+	}
+	return scanner.Err()
+}
+
+// ReloadFile replaces the rules loaded from file by its current content, keeping their place
+// among the other rules. The rules are dropped if the file does not exist any more.
+func (f *Filter) ReloadFile(path string, base string) error {
+	loaded := &Filter{}
+	if _, err := os.Stat(path); err == nil {
+		if err := loaded.LoadFile(path, base); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	path = filepath.Clean(path)
+	rules := make([]filterRule, 0, len(f.rules)+len(loaded.rules))
+	inserted := false
+	for _, rule := range f.rules {
+		if rule.file != path {
+			rules = append(rules, rule)
+		} else if !inserted {
+			rules = append(rules, loaded.rules...)
+			inserted = true
+		}
+	}
+	if !inserted {
+		rules = append(rules, loaded.rules...)
+	}
+	f.rules = rules
+	return nil
+}
+
+// SetRegexp only accepts files whose relative paths match expr.
+func (f *Filter) SetRegexp(expr string) error {
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+	f.regex = regex
+	return nil
+}
+
+// SetNames only accepts files whose base names match one of the fnmatch patterns.
+func (f *Filter) SetNames(patterns []string) {
+	f.names = nil
+	for _, pattern := range patterns {
+		if pattern != "" {
+			f.names = append(f.names, pattern)
+		}
+	}
+}
+
+// SetSizeRange only accepts files whose size is in [minSize, maxSize], -1 means no limit.
+func (f *Filter) SetSizeRange(minSize int64, maxSize int64) {
+	f.minSize = minSize
+	f.maxSize = maxSize
+}
+
+// SetTimeRange only accepts files modified after newerThan and before olderThan, zero means no limit.
+func (f *Filter) SetTimeRange(newerThan time.Time, olderThan time.Time) {
+	f.newerThan = newerThan
+	f.olderThan = olderThan
+}
+
+// SetStorageClasses only accepts objects in one of the storage classes. It does not affect local files.
+func (f *Filter) SetStorageClasses(classes []string) {
+	f.storageClasses = nil
+	for _, class := range classes {
+		if class != "" {
+			f.storageClasses = append(f.storageClasses, strings.ToUpper(class))
+		}
+	}
+}
+
+// ExcludedFile returns true if the file should not be transferred, checking both its path and attributes.
+func (f *Filter) ExcludedFile(path string, attr FileAttr) bool {
+	if f == nil {
+		return false
+	}
+	if f.Excluded(path, false) {
+		return true
+	}
+	if attr.Size >= 0 {
+		if (f.minSize >= 0 && attr.Size < f.minSize) || (f.maxSize >= 0 && attr.Size > f.maxSize) {
+			return true
+		}
+	}
+	if !attr.ModTime.IsZero() {
+		if (!f.newerThan.IsZero() && !attr.ModTime.After(f.newerThan)) ||
+			(!f.olderThan.IsZero() && !attr.ModTime.Before(f.olderThan)) {
+			return true
+		}
+	}
+	if len(f.storageClasses) > 0 && attr.StorageClass != "" {
+		class := strings.ToUpper(attr.StorageClass)
+		for _, c := range f.storageClasses {
+			if c == class {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Excluded returns true if the path should not be transferred.
+// isDir tells whether the path is a directory, directories are only checked
+// against the gitignore-style rules.
+// Like git, a file can not be re-included if one of its parent directories is excluded.
+func (f *Filter) Excluded(path string, isDir bool) bool {
+	if f == nil {
+		return false
+	}
+	path = strings.Trim(path, "/")
+	if len(f.rules) > 0 {
+		for i := 0; i < len(path); i++ {
+			if path[i] == '/' && f.matchRules(path[:i], true) {
+				return true
+			}
+		}
+		if f.matchRules(path, isDir) {
+			return true
+		}
+	}
+	if isDir {
+		return false
+	}
+	if f.regex != nil && !f.regex.MatchString(path) {
+		return true
+	}
+	if len(f.names) > 0 {
+		name := path[strings.LastIndex(path, "/")+1:]
+		isMatched := false
+		for _, pattern := range f.names {
+			if fnmatch.Match(pattern, name, 0) {
+				isMatched = true
+				break
+			}
+		}
+		if !isMatched {
+			return true
+		}
+	}
+	if len(f.include) > 0 {
+		isInclude := false
+		for _, rule := range f.include {
+			if fnmatch.Match(rule, path, 0) {
+				isInclude = true
+				break
+			}
+		}
+		if !isInclude {
+			return true
+		}
+	}
+	for _, rule := range f.ignore {
+		if fnmatch.Match(rule, path, 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// The last matching rule decides.
+func (f *Filter) matchRules(path string, isDir bool) bool {
+	excluded := false
+	for _, rule := range f.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		p := path
+		if rule.base != "" {
+			if !strings.HasPrefix(path, rule.base+"/") {
+				continue
+			}
+			p = path[len(rule.base)+1:]
+		}
+		if rule.pattern.MatchString(p) {
+			excluded = !rule.negate
+		}
+	}
+	return excluded
+}
+
+// Convert a gitignore glob to regular expression.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				atStart := i == 0 || glob[i-1] == '/'
+				atEnd := i+2 == len(glob) || glob[i+2] == '/'
+				if atStart && atEnd {
+					if i+2 == len(glob) {
+						// "a/**" matches everything inside a.
+						sb.WriteString(".*")
+					} else {
+						// "**/" matches zero or more directories.
+						sb.WriteString("(?:.*/)?")
+						i++
+					}
+					i++
+					continue
+				}
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == 0 {
+				// "[]" is not a class, "[]...]" has ']' as its first member.
+				next := strings.IndexByte(glob[i+2:], ']')
+				if next >= 0 {
+					end = next + 1
+				} else {
+					end = -1
+				}
+			}
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
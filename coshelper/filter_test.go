@@ -0,0 +1,232 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coshelper
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob, want string
+	}{
+		{"*.txt", `[^/]*\.txt`},
+		{"a?c", `a[^/]c`},
+		{"**/foo", `(?:.*/)?foo`},
+		{"a/**", `a/.*`},
+		{"a/**/b", `a/(?:.*/)?b`},
+		{"[abc].go", `[abc]\.go`},
+		{"[!abc]", `[^abc]`},
+		{"[]a]", `[]a]`},
+		{"[a", `\[a`},
+		{`\*`, `\*`},
+	}
+	for _, test := range tests {
+		if got := globToRegexp(test.glob); got != test.want {
+			t.Errorf("globToRegexp(%q) = %q, want %q", test.glob, got, test.want)
+		}
+	}
+}
+
+func TestFilterRules(t *testing.T) {
+	f := NewFilter(nil, nil)
+	for _, line := range []string{
+		"# comment",
+		"",
+		"*.log",
+		"!keep.log",
+		"build/",
+		"/root.txt",
+		"docs/*.md",
+		`\#hash`,
+	} {
+		f.AddRule(line, "")
+	}
+	f.AddRule("*.tmp", "sub")
+	tests := []struct {
+		path     string
+		isDir    bool
+		excluded bool
+	}{
+		{"a.log", false, true},
+		{"x/y/a.log", false, true},
+		{"keep.log", false, false},
+		{"x/keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"build/out.bin", false, true},
+		{"x/build/out.bin", false, true},
+		{"root.txt", false, true},
+		{"x/root.txt", false, false},
+		{"docs/a.md", false, true},
+		{"docs/x/a.md", false, false},
+		{"#hash", false, true},
+		{"sub/a.tmp", false, true},
+		{"sub/x/a.tmp", false, true},
+		{"a.tmp", false, false},
+		{"main.go", false, false},
+	}
+	for _, test := range tests {
+		if got := f.Excluded(test.path, test.isDir); got != test.excluded {
+			t.Errorf("Excluded(%q, %v) = %v, want %v", test.path, test.isDir, got, test.excluded)
+		}
+	}
+}
+
+func TestFilterParentExcluded(t *testing.T) {
+	// Like git, a file can not be re-included if its parent directory is excluded.
+	f := NewFilter(nil, nil)
+	f.AddRule("logs/", "")
+	f.AddRule("!logs/keep.txt", "")
+	if !f.Excluded("logs/keep.txt", false) {
+		t.Error("logs/keep.txt is re-included")
+	}
+}
+
+func TestFilterIncludeIgnore(t *testing.T) {
+	f := NewFilter([]string{"*.txt", "*.md", ""}, []string{"secret*"})
+	tests := []struct {
+		path     string
+		excluded bool
+	}{
+		{"a.txt", false},
+		{"dir/a.md", false},
+		{"a.go", true},
+		{"secret.txt", true},
+		// fnmatch patterns are matched without FNM_PATHNAME, so "*" matches "/".
+		{"dir/secret.txt", false},
+	}
+	for _, test := range tests {
+		if got := f.Excluded(test.path, false); got != test.excluded {
+			t.Errorf("Excluded(%q) = %v, want %v", test.path, got, test.excluded)
+		}
+	}
+	// Directories are only checked against the rules.
+	if f.Excluded("secret", true) {
+		t.Error("directory secret is excluded")
+	}
+}
+
+func TestFilterRegexpAndNames(t *testing.T) {
+	f := NewFilter(nil, nil)
+	if err := f.SetRegexp(`^2020/`); err != nil {
+		t.Fatal(err)
+	}
+	f.SetNames([]string{"*.jpg", ""})
+	tests := []struct {
+		path     string
+		excluded bool
+	}{
+		{"2020/a.jpg", false},
+		{"2020/x/b.jpg", false},
+		{"2021/a.jpg", true},
+		{"2020/a.png", true},
+		{"2020/jpg/a.png", true},
+	}
+	for _, test := range tests {
+		if got := f.Excluded(test.path, false); got != test.excluded {
+			t.Errorf("Excluded(%q) = %v, want %v", test.path, got, test.excluded)
+		}
+	}
+	if err := f.SetRegexp("("); err == nil {
+		t.Error("invalid regular expression is accepted")
+	}
+}
+
+func TestFilterExcludedFile(t *testing.T) {
+	now := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	f := NewFilter(nil, nil)
+	f.SetSizeRange(10, 100)
+	f.SetTimeRange(now.Add(-24*time.Hour), now)
+	f.SetStorageClasses([]string{"standard", "ARCHIVE", ""})
+	tests := []struct {
+		name     string
+		attr     FileAttr
+		excluded bool
+	}{
+		{"match", FileAttr{Size: 50, ModTime: now.Add(-time.Hour), StorageClass: "STANDARD"}, false},
+		{"too small", FileAttr{Size: 9, ModTime: now.Add(-time.Hour)}, true},
+		{"too large", FileAttr{Size: 101, ModTime: now.Add(-time.Hour)}, true},
+		{"bounds", FileAttr{Size: 100, ModTime: now.Add(-time.Hour)}, false},
+		{"too old", FileAttr{Size: 50, ModTime: now.Add(-48 * time.Hour)}, true},
+		{"too new", FileAttr{Size: 50, ModTime: now}, true},
+		{"unknown size and time", FileAttr{Size: -1}, false},
+		{"other class", FileAttr{Size: 50, ModTime: now.Add(-time.Hour), StorageClass: "STANDARD_IA"}, true},
+		{"local file", FileAttr{Size: 50, ModTime: now.Add(-time.Hour)}, false},
+	}
+	for _, test := range tests {
+		if got := f.ExcludedFile("a", test.attr); got != test.excluded {
+			t.Errorf("%s: ExcludedFile = %v, want %v", test.name, got, test.excluded)
+		}
+	}
+	var nilFilter *Filter
+	if nilFilter.ExcludedFile("a", FileAttr{}) || nilFilter.Excluded("a", false) {
+		t.Error("nil filter excludes files")
+	}
+}
+
+func TestFilterReloadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	rootFile := filepath.Join(dir, IgnoreFileName)
+	subFile := filepath.Join(dir, "sub", IgnoreFileName)
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(rootFile, []byte("*.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(subFile, []byte("!keep.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f := NewFilter(nil, nil)
+	if err := f.LoadFile(rootFile, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.LoadFile(subFile, "sub"); err != nil {
+		t.Fatal(err)
+	}
+	if f.Excluded("sub/keep.log", false) || !f.Excluded("a.log", false) {
+		t.Fatal("rules are not loaded")
+	}
+	// The reloaded rules keep their place before the rules of sub.
+	if err := ioutil.WriteFile(rootFile, []byte("*.log\n*.tmp\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.ReloadFile(rootFile, ""); err != nil {
+		t.Fatal(err)
+	}
+	if f.Excluded("sub/keep.log", false) || !f.Excluded("a.tmp", false) {
+		t.Error("rules are not reloaded in place")
+	}
+	if err := os.Remove(rootFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.ReloadFile(rootFile, ""); err != nil {
+		t.Fatal(err)
+	}
+	if f.Excluded("a.log", false) || f.Excluded("a.tmp", false) {
+		t.Error("rules of the removed file are kept")
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coshelper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/danwakefield/fnmatch"
+)
+
+// LoadMimeTypes reads a mime.types file, each line of which is a type followed by its extensions,
+// and overrides the built-in table with them.
+func LoadMimeTypes(filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, ext := range fields[1:] {
+			if err := mime.AddExtensionType("."+strings.TrimPrefix(ext, "."), fields[0]); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// DetectContentType infers the Content-Type of the file from its extension,
+// and sniffs its content if the extension is unknown.
+func DetectContentType(filePath string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(filePath)); contentType != "" {
+		return contentType
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return ""
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	// DetectContentType considers at most 512 bytes.
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return ""
+	}
+	return http.DetectContentType(buf[:n])
+}
+
+type headerRule struct {
+	pattern string
+	key     string
+	value   string
+}
+
+// HeaderRules sets headers of objects whose names match patterns.
+type HeaderRules struct {
+	rules []headerRule
+}
+
+// LoadHeaderRules reads rules from file. Each line is a fnmatch pattern and a header, like
+//
+//	*.html Cache-Control: no-cache
+//
+// Patterns without "/" are matched against the file name, otherwise the COS path.
+// Blank lines and lines starting with "#" are ignored.
+func LoadHeaderRules(filePath string) (*HeaderRules, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	rules := &HeaderRules{}
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sep := strings.IndexAny(line, " \t")
+		if sep < 0 {
+			return nil, fmt.Errorf("line %d: header is missing", lineNum)
+		}
+		pattern := line[:sep]
+		header := strings.SplitN(strings.TrimSpace(line[sep:]), ":", 2)
+		if len(header) < 2 || strings.TrimSpace(header[0]) == "" {
+			return nil, fmt.Errorf("line %d: header should be 'Key: Value'", lineNum)
+		}
+		rules.rules = append(rules.rules, headerRule{
+			pattern: pattern,
+			key:     strings.TrimSpace(header[0]),
+			value:   strings.TrimSpace(header[1]),
+		})
+	}
+	return rules, scanner.Err()
+}
+
+// Apply sets the headers of all rules matching cosPath to header, later rules override earlier ones.
+func (r *HeaderRules) Apply(cosPath string, header http.Header) {
+	if r == nil {
+		return
+	}
+	name := path.Base(cosPath)
+	for _, rule := range r.rules {
+		target := name
+		if strings.Contains(rule.pattern, "/") {
+			target = strings.TrimLeft(cosPath, "/")
+		}
+		if fnmatch.Match(rule.pattern, target, 0) {
+			header.Set(rule.key, rule.value)
+		}
+	}
+}
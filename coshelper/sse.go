@@ -0,0 +1,142 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coshelper
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// SSE is the server-side encryption of objects. A nil *SSE means no encryption.
+type SSE struct {
+	// Type is "cos" for SSE-COS, "kms" for SSE-KMS, or empty.
+	Type     string
+	KMSKeyID string
+	// CustomerKey is the 32 bytes key of SSE-C, nil if SSE-C is not used.
+	CustomerKey []byte
+}
+
+// NewSSE checks the options of encryption, the key of SSE-C is read from customerKeyFile.
+// Return nil if no encryption is specified.
+func NewSSE(sseType string, kmsKeyID string, customerKeyFile string) (*SSE, error) {
+	sse := &SSE{
+		Type:     strings.ToLower(sseType),
+		KMSKeyID: kmsKeyID,
+	}
+	switch sse.Type {
+	case "", "cos", "kms":
+	default:
+		return nil, fmt.Errorf("encryption type must be cos or kms")
+	}
+	if kmsKeyID != "" && sse.Type != "kms" {
+		return nil, fmt.Errorf("KMS key id can only be used with SSE-KMS")
+	}
+	if customerKeyFile != "" {
+		if sse.Type != "" {
+			return nil, fmt.Errorf("SSE-C can not be used with SSE-%s", strings.ToUpper(sse.Type))
+		}
+		var err error
+		if sse.CustomerKey, err = readCustomerKey(customerKeyFile); err != nil {
+			return nil, err
+		}
+	}
+	if sse.Type == "" && sse.CustomerKey == nil {
+		return nil, nil
+	}
+	return sse, nil
+}
+
+// The key file has the 32 bytes key, in raw bytes, base64 or hex.
+func readCustomerKey(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 32 {
+		return data, nil
+	}
+	text := string(bytes.TrimSpace(data))
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, fmt.Errorf("key file '%s' should have a 32 bytes key, in raw bytes, base64 or hex", path)
+}
+
+// SetWriteHeaders sets the headers of requests creating objects, like PUT, initiating multipart uploads and copy.
+func (sse *SSE) SetWriteHeaders(header http.Header) {
+	if sse == nil {
+		return
+	}
+	switch sse.Type {
+	case "cos":
+		header.Set("x-cos-server-side-encryption", "AES256")
+	case "kms":
+		header.Set("x-cos-server-side-encryption", "cos/kms")
+		if sse.KMSKeyID != "" {
+			header.Set("x-cos-server-side-encryption-cos-kms-key-id", sse.KMSKeyID)
+		}
+	}
+	sse.SetReadHeaders(header)
+}
+
+// SetReadHeaders sets the SSE-C headers needed by every request of an object encrypted with SSE-C,
+// like GET, HEAD and uploading parts.
+func (sse *SSE) SetReadHeaders(header http.Header) {
+	if sse == nil || sse.CustomerKey == nil {
+		return
+	}
+	sum := md5.Sum(sse.CustomerKey)
+	header.Set("x-cos-server-side-encryption-customer-algorithm", "AES256")
+	header.Set("x-cos-server-side-encryption-customer-key", base64.StdEncoding.EncodeToString(sse.CustomerKey))
+	header.Set("x-cos-server-side-encryption-customer-key-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// SetCopySourceHeaders sets the SSE-C headers of the source object of copy.
+func (sse *SSE) SetCopySourceHeaders(header http.Header) {
+	if sse == nil || sse.CustomerKey == nil {
+		return
+	}
+	sum := md5.Sum(sse.CustomerKey)
+	header.Set("x-cos-copy-source-server-side-encryption-customer-algorithm", "AES256")
+	header.Set("x-cos-copy-source-server-side-encryption-customer-key", base64.StdEncoding.EncodeToString(sse.CustomerKey))
+	header.Set("x-cos-copy-source-server-side-encryption-customer-key-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// ReadHeaders returns the headers set by SetReadHeaders, nil if there is none, for the options of the SDK.
+func (sse *SSE) ReadHeaders() *http.Header {
+	if sse == nil || sse.CustomerKey == nil {
+		return nil
+	}
+	header := http.Header{}
+	sse.SetReadHeaders(header)
+	return &header
+}
+
+// ETagIsMD5 tells whether the ETags of the encrypted objects and parts are still the MD5 of the content,
+// which is not true for SSE-KMS and SSE-C.
+func (sse *SSE) ETagIsMD5() bool {
+	return sse == nil || (sse.Type != "kms" && sse.CustomerKey == nil)
+}
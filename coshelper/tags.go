@@ -0,0 +1,76 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coshelper
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Tag is a key-value pair attached to buckets and objects.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// ParseTags parses tags like "k1=v1,k2=v2". A tag without "=" has an empty value.
+func ParseTags(s string) ([]Tag, error) {
+	tags := make([]Tag, 0)
+	keys := make(map[string]struct{})
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		tag := Tag{Key: strings.TrimSpace(kv[0])}
+		if len(kv) == 2 {
+			tag.Value = strings.TrimSpace(kv[1])
+		}
+		if tag.Key == "" || len(tag.Key) > 128 {
+			return nil, fmt.Errorf("tag key must have 1 to 128 characters: '%s'", item)
+		}
+		if len(tag.Value) > 256 {
+			return nil, fmt.Errorf("tag value of '%s' is longer than 256 characters", tag.Key)
+		}
+		if _, ok := keys[tag.Key]; ok {
+			return nil, fmt.Errorf("tag key '%s' is duplicated", tag.Key)
+		}
+		keys[tag.Key] = struct{}{}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// EncodeTags encodes tags as the value of x-cos-tagging header, like "k1=v1&k2=v2".
+func EncodeTags(tags []Tag) string {
+	items := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		items = append(items, url.QueryEscape(tag.Key)+"="+url.QueryEscape(tag.Value))
+	}
+	return strings.Join(items, "&")
+}
+
+// FormatTags formats tags like "k1=v1,k2=v2" for display.
+func FormatTags(tags []Tag) string {
+	items := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		items = append(items, tag.Key+"="+tag.Value)
+	}
+	return strings.Join(items, ",")
+}
@@ -18,17 +18,23 @@ package coshelper
 
 import (
 	"bufio"
+	"bytes"
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
 )
 
 // Get MD5 of the file. The MD5 string is uppercase. If MD5 calculation failed, return ""
@@ -131,6 +137,14 @@ func IsFile(path string) bool {
 	return FileExists(path) && !IsDir(path)
 }
 
+func IsSymlink(path string) bool {
+	stat, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeSymlink != 0
+}
+
 func GetFileSize(path string) (int64, error) {
 	f, err := os.Stat(path)
 	if err != nil {
@@ -167,3 +181,122 @@ func Humanize(size int64, human bool) string {
 		return fmt.Sprintf("%d", size)
 	}
 }
+
+var sizePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([KMGTP]?)I?B?$`)
+
+// Parse size string like 100, 1.5K, 20M, 3GB to bytes. Units are in 1024.
+func ParseSize(str string) (int64, error) {
+	matches := sizePattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(str)))
+	if matches == nil {
+		return 0, fmt.Errorf("invalid size '%s'", str)
+	}
+	value, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	unit := strings.Index("KMGTP", matches[2]) + 1
+	if matches[2] == "" {
+		unit = 0
+	}
+	return int64(value * math.Pow(1024, float64(unit))), nil
+}
+
+var dayWeekPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)([dw])$`)
+
+// Parse a point of time. str can be a date like 2006-01-02, a time in RFC3339 or "2006-01-02 15:04:05" (local time),
+// or a duration before now like 30m, 12h, 7d, 2w.
+func ParseTimeOrDuration(str string, now time.Time) (time.Time, error) {
+	str = strings.TrimSpace(str)
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, str, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	// time.ParseDuration does not know days and weeks, which must be a plain number like 7d, not 1w2d or 12h7d.
+	if matches := dayWeekPattern.FindStringSubmatch(str); matches != nil {
+		value, err := strconv.ParseFloat(matches[1], 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time or duration '%s'", str)
+		}
+		if matches[2] == "w" {
+			value *= 7
+		}
+		return now.Add(-time.Duration(value * float64(24*time.Hour))), nil
+	}
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time or duration '%s'", str)
+	}
+	return now.Add(-d), nil
+}
+
+// Read a JSON or YAML file into v, YAML is used if the extension of the file is .yaml or .yml.
+func UnmarshalFile(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.UnmarshalStrict(data, v)
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(v)
+	}
+}
+
+// DiffLines compares two texts line by line, returning the lines of a and b
+// prefixed by "- ", "+ " or "  " for removed, added and unchanged lines.
+func DiffLines(a, b string) []string {
+	x := strings.Split(strings.TrimRight(a, "\n"), "\n")
+	y := strings.Split(strings.TrimRight(b, "\n"), "\n")
+	if a == "" {
+		x = nil
+	}
+	if b == "" {
+		y = nil
+	}
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	lines := make([]string, 0, len(x)+len(y))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, "  "+x[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "- "+x[i])
+			i++
+		default:
+			lines = append(lines, "+ "+y[j])
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		lines = append(lines, "- "+x[i])
+	}
+	for ; j < len(y); j++ {
+		lines = append(lines, "+ "+y[j])
+	}
+	return lines
+}
@@ -0,0 +1,109 @@
+/*
+Copyright © 2020 Haitao Huang <hht970222@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coshelper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		str  string
+		want int64
+	}{
+		{"0", 0},
+		{"100", 100},
+		{"100B", 100},
+		{"1k", 1024},
+		{"1KB", 1024},
+		{"1KiB", 1024},
+		{"1.5M", 1536 * 1024},
+		{" 2 G ", 2 << 30},
+		{"1T", 1 << 40},
+		{"1P", 1 << 50},
+	}
+	for _, test := range tests {
+		got, err := ParseSize(test.str)
+		if err != nil {
+			t.Errorf("ParseSize(%q) returns error: %v", test.str, err)
+		} else if got != test.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", test.str, got, test.want)
+		}
+	}
+	for _, str := range []string{"", "-1", "1X", "K", "1.K", "1KBB", "1 2K"} {
+		if got, err := ParseSize(str); err == nil {
+			t.Errorf("ParseSize(%q) = %d, want error", str, got)
+		}
+	}
+}
+
+func TestParseTimeOrDuration(t *testing.T) {
+	now := time.Date(2020, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		str  string
+		want time.Time
+	}{
+		{"2020-01-02T03:04:05Z", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2020-01-02T03:04:05+08:00", time.Date(2020, 1, 1, 19, 4, 5, 0, time.UTC)},
+		{"2020-01-02", time.Date(2020, 1, 2, 0, 0, 0, 0, time.Local)},
+		{"2020-01-02 03:04:05", time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)},
+		{"30m", now.Add(-30 * time.Minute)},
+		{"1h30m", now.Add(-90 * time.Minute)},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{"1.5d", now.Add(-36 * time.Hour)},
+		{"2w", now.Add(-14 * 24 * time.Hour)},
+		{" 12h ", now.Add(-12 * time.Hour)},
+	}
+	for _, test := range tests {
+		got, err := ParseTimeOrDuration(test.str, now)
+		if err != nil {
+			t.Errorf("ParseTimeOrDuration(%q) returns error: %v", test.str, err)
+		} else if !got.Equal(test.want) {
+			t.Errorf("ParseTimeOrDuration(%q) = %v, want %v", test.str, got, test.want)
+		}
+	}
+	for _, str := range []string{"", "yesterday", "d", "w", "7", "-7d", "7d12h", "12h7d", "1w2d", "2020-13-01"} {
+		if got, err := ParseTimeOrDuration(str, now); err == nil {
+			t.Errorf("ParseTimeOrDuration(%q) = %v, want error", str, got)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []string
+	}{
+		{"", "", []string{}},
+		{"a\nb\n", "a\nb\n", []string{"  a", "  b"}},
+		{"", "a\nb", []string{"+ a", "+ b"}},
+		{"a\nb\n", "", []string{"- a", "- b"}},
+		{"a\nb\nc", "a\nc", []string{"  a", "- b", "  c"}},
+		{"a\nc", "a\nb\nc", []string{"  a", "+ b", "  c"}},
+		{"a\nb\nc", "a\nx\nc", []string{"  a", "- b", "+ x", "  c"}},
+		{"a\nb", "b\na", []string{"- a", "  b", "+ a"}},
+		// A missing final newline is not a difference.
+		{"a\n", "a", []string{"  a"}},
+	}
+	for _, test := range tests {
+		if got := DiffLines(test.a, test.b); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("DiffLines(%q, %q) = %q, want %q", test.a, test.b, got, test.want)
+		}
+	}
+}
@@ -4,7 +4,9 @@ go 1.13
 
 require (
 	github.com/danwakefield/fnmatch v0.0.0-20160403171240-cbb64ac3d964
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/jedib0t/go-pretty/v6 v6.2.1
+	github.com/klauspost/compress v1.11.13
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/schollz/progressbar/v3 v3.7.4
 	github.com/sirupsen/logrus v1.8.1
@@ -14,4 +16,5 @@ require (
 	github.com/tencentyun/cos-go-sdk-v5 v0.7.24
 	gopkg.in/ini.v1 v1.62.0
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0
+	gopkg.in/yaml.v2 v2.4.0
 )